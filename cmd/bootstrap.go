@@ -1,11 +1,15 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
+	"github.com/rezajo220/ecommerce/internal/migrate"
+	"github.com/rezajo220/ecommerce/internal/payment"
+	"github.com/rezajo220/ecommerce/migrations"
 )
 
 func NewPostgresDB(cfg DatabaseConfig) (*sqlx.DB, error) {
@@ -24,5 +28,64 @@ func NewPostgresDB(cfg DatabaseConfig) (*sqlx.DB, error) {
 	}
 
 	log.Println("Connected to PostgreSQL database")
+
+	if err := prepareSchema(db, cfg); err != nil {
+		db.Close()
+		return nil, err
+	}
+
 	return db, nil
 }
+
+// mockWebhookSecret signs mock gateway webhooks when no secret is configured.
+const mockWebhookSecret = "mock-webhook-secret"
+
+func NewPaymentGateway(cfg PaymentConfig) (payment.PaymentGateway, error) {
+	switch cfg.Provider {
+	case "mock":
+		secret := cfg.WebhookSecret
+		if secret == "" {
+			log.Printf("PAYMENT_WEBHOOK_SECRET is not set; mock webhooks are signed with %q", mockWebhookSecret)
+			secret = mockWebhookSecret
+		}
+		return payment.NewMockGateway(secret), nil
+	}
+
+	return nil, fmt.Errorf("unknown payment provider %q", cfg.Provider)
+}
+
+// prepareSchema applies pending migrations when MIGRATE_ON_START is set and
+// otherwise refuses to continue on an outdated schema if RequireLatestSchema is on.
+func prepareSchema(db *sqlx.DB, cfg DatabaseConfig) error {
+	if !cfg.MigrateOnStart && !cfg.RequireLatestSchema {
+		return nil
+	}
+
+	migrator, err := migrate.New(db, migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if cfg.MigrateOnStart {
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to apply migrations: %w", err)
+		}
+		for _, m := range applied {
+			log.Printf("Applied migration %d_%s", m.Version, m.Name)
+		}
+		return nil
+	}
+
+	pending, err := migrator.Pending(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check schema version: %w", err)
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("database schema is behind by %d migration(s), starting with %d_%s; run `migrate up` or set MIGRATE_ON_START=true",
+			len(pending), pending[0].Version, pending[0].Name)
+	}
+
+	return nil
+}
@@ -1,39 +1,129 @@
 package main
 
 import (
+	"fmt"
 	"os"
 	"strconv"
 	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/labstack/echo/v4"
+	"github.com/rezajo220/ecommerce/internal/ratelimit"
+	services "github.com/rezajo220/ecommerce/internal/service"
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
+	Server     ServerConfig
+	Database   DatabaseConfig
+	Inventory  InventoryConfig
+	Pricing    PricingConfig
+	Payment    PaymentConfig
+	Auth       AuthConfig
+	RateLimit  RateLimitConfig
+	SoftDelete SoftDeleteConfig
 }
 
 type ServerConfig struct {
-	Port         string
-	ReadTimeout  time.Duration
-	WriteTimeout time.Duration
+	Port              string
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	TrustProxyHeaders bool
+	RequireIfMatch    bool
+}
+
+// IPExtractor decides where the client address comes from. X-Forwarded-For
+// is only honoured behind a trusted proxy, since clients can set it freely
+// and API key IP allowlists depend on it.
+func (c ServerConfig) IPExtractor() echo.IPExtractor {
+	if c.TrustProxyHeaders {
+		return echo.ExtractIPFromXFFHeader()
+	}
+	return echo.ExtractIPDirect()
 }
 
 type DatabaseConfig struct {
-	Host     string
-	Port     string
-	User     string
-	Password string
-	DBName   string
-	SSLMode  string
+	Host                string
+	Port                string
+	User                string
+	Password            string
+	DBName              string
+	SSLMode             string
+	MigrateOnStart      bool
+	RequireLatestSchema bool
+	MigrationsDir       string
+}
+
+// InventoryConfig sets how often expired stock holds are swept, how long
+// an unpaid order keeps its stock before it is cancelled and how long a
+// payment may stay unsettled before the sweep gives it up.
+type InventoryConfig struct {
+	ReservationExpiryInterval time.Duration
+	PendingOrderTTL           time.Duration
+	StalePaymentTTL           time.Duration
 }
 
+// PricingConfig sets how often due scheduled prices are applied.
+type PricingConfig struct {
+	ScheduleInterval time.Duration
+}
+
+type PaymentConfig struct {
+	Provider      string
+	WebhookSecret string
+}
+
+type AuthConfig struct {
+	JWTSecret       string
+	JWTIssuer       string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+}
+
+// minJWTSecretLength is the shortest accepted HS256 signing key, in bytes.
+const minJWTSecretLength = 32
+
+// Validate reports a missing or weak signing key. It is checked when the
+// server starts rather than in LoadConfig so CLI subcommands that never sign
+// tokens do not need the key.
+func (c AuthConfig) Validate() error {
+	if len(c.JWTSecret) < minJWTSecretLength {
+		return fmt.Errorf("JWT_SECRET must be set to at least %d bytes", minJWTSecretLength)
+	}
+	return nil
+}
+
+func (c AuthConfig) ServiceConfig() services.AuthConfig {
+	return services.AuthConfig{
+		SigningKey:      []byte(c.JWTSecret),
+		Issuer:          c.JWTIssuer,
+		AccessTokenTTL:  c.AccessTokenTTL,
+		RefreshTokenTTL: c.RefreshTokenTTL,
+	}
+}
+
+type RateLimitConfig struct {
+	Enabled bool
+	Policy  ratelimit.Policy
+}
+
+// SoftDeleteConfig sets how long soft-deleted products and brands are kept
+// before the purge command removes them.
+type SoftDeleteConfig struct {
+	Retention time.Duration
+}
+
+// defaultRateLimitRules are stricter quotas for catalog creation and for the
+// login endpoints, where password guessing would happen.
+const defaultRateLimitRules = "POST /v1/products=30/1m; POST /v1/auth/*=20/1m"
+
 func LoadConfig() (*Config, error) {
 	godotenv.Load()
 
 	port := getEnv("SERVER_PORT", "8080")
 	readTimeoutSec, _ := strconv.Atoi(getEnv("SERVER_READ_TIMEOUT", "30"))
 	writeTimeoutSec, _ := strconv.Atoi(getEnv("SERVER_WRITE_TIMEOUT", "30"))
+	trustProxyHeaders, _ := strconv.ParseBool(getEnv("TRUST_PROXY_HEADERS", "false"))
+	requireIfMatch, _ := strconv.ParseBool(getEnv("REQUIRE_IF_MATCH", "true"))
 
 	dbHost := getEnv("DB_HOST", "localhost")
 	dbPort := getEnv("DB_PORT", "5432")
@@ -41,20 +131,86 @@ func LoadConfig() (*Config, error) {
 	dbPassword := getEnv("DB_PASSWORD", "postgres")
 	dbName := getEnv("DB_NAME", "ecommerce")
 	dbSSLMode := getEnv("DB_SSL_MODE", "disable")
+	migrateOnStart, _ := strconv.ParseBool(getEnv("MIGRATE_ON_START", "false"))
+	requireLatestSchema, _ := strconv.ParseBool(getEnv("DB_REQUIRE_LATEST_SCHEMA", "false"))
+	migrationsDir := getEnv("MIGRATIONS_DIR", "migrations")
+
+	reservationExpirySec, _ := strconv.Atoi(getEnv("RESERVATION_EXPIRY_INTERVAL", "60"))
+	pendingOrderTTLSec, _ := strconv.Atoi(getEnv("PENDING_ORDER_TTL", "1800"))
+	stalePaymentTTLSec, _ := strconv.Atoi(getEnv("STALE_PAYMENT_TTL", "900"))
+	priceScheduleSec, _ := strconv.Atoi(getEnv("PRICE_SCHEDULE_INTERVAL", "60"))
+
+	paymentProvider := getEnv("PAYMENT_PROVIDER", "mock")
+	paymentWebhookSecret := getEnv("PAYMENT_WEBHOOK_SECRET", "")
+
+	jwtSecret := getEnv("JWT_SECRET", "")
+	jwtIssuer := getEnv("JWT_ISSUER", "ecommerce-api")
+	accessTokenTTLSec, _ := strconv.Atoi(getEnv("ACCESS_TOKEN_TTL", "900"))
+	refreshTokenTTLSec, _ := strconv.Atoi(getEnv("REFRESH_TOKEN_TTL", "2592000"))
+
+	rateLimitEnabled, _ := strconv.ParseBool(getEnv("RATE_LIMIT_ENABLED", "true"))
+	rateLimitDefault, err := ratelimit.ParseLimit(getEnv("RATE_LIMIT_DEFAULT", "300/1m"))
+	if err != nil {
+		return nil, fmt.Errorf("RATE_LIMIT_DEFAULT: %w", err)
+	}
+	rateLimitRules, err := ratelimit.ParseRules(getEnv("RATE_LIMIT_RULES", defaultRateLimitRules))
+	if err != nil {
+		return nil, fmt.Errorf("RATE_LIMIT_RULES: %w", err)
+	}
+	rateLimitPerIP, err := ratelimit.ParseLimit(getEnv("RATE_LIMIT_PER_IP", "1200/1m"))
+	if err != nil {
+		return nil, fmt.Errorf("RATE_LIMIT_PER_IP: %w", err)
+	}
+
+	softDeleteRetentionDays, _ := strconv.Atoi(getEnv("SOFT_DELETE_RETENTION_DAYS", "30"))
 
 	config := &Config{
 		Server: ServerConfig{
-			Port:         port,
-			ReadTimeout:  time.Duration(readTimeoutSec) * time.Second,
-			WriteTimeout: time.Duration(writeTimeoutSec) * time.Second,
+			Port:              port,
+			ReadTimeout:       time.Duration(readTimeoutSec) * time.Second,
+			WriteTimeout:      time.Duration(writeTimeoutSec) * time.Second,
+			TrustProxyHeaders: trustProxyHeaders,
+			RequireIfMatch:    requireIfMatch,
 		},
 		Database: DatabaseConfig{
-			Host:     dbHost,
-			Port:     dbPort,
-			User:     dbUser,
-			Password: dbPassword,
-			DBName:   dbName,
-			SSLMode:  dbSSLMode,
+			Host:                dbHost,
+			Port:                dbPort,
+			User:                dbUser,
+			Password:            dbPassword,
+			DBName:              dbName,
+			SSLMode:             dbSSLMode,
+			MigrateOnStart:      migrateOnStart,
+			RequireLatestSchema: requireLatestSchema,
+			MigrationsDir:       migrationsDir,
+		},
+		Inventory: InventoryConfig{
+			ReservationExpiryInterval: time.Duration(reservationExpirySec) * time.Second,
+			PendingOrderTTL:           time.Duration(pendingOrderTTLSec) * time.Second,
+			StalePaymentTTL:           time.Duration(stalePaymentTTLSec) * time.Second,
+		},
+		Pricing: PricingConfig{
+			ScheduleInterval: time.Duration(priceScheduleSec) * time.Second,
+		},
+		Payment: PaymentConfig{
+			Provider:      paymentProvider,
+			WebhookSecret: paymentWebhookSecret,
+		},
+		Auth: AuthConfig{
+			JWTSecret:       jwtSecret,
+			JWTIssuer:       jwtIssuer,
+			AccessTokenTTL:  time.Duration(accessTokenTTLSec) * time.Second,
+			RefreshTokenTTL: time.Duration(refreshTokenTTLSec) * time.Second,
+		},
+		RateLimit: RateLimitConfig{
+			Enabled: rateLimitEnabled,
+			Policy: ratelimit.Policy{
+				Default: rateLimitDefault,
+				Rules:   rateLimitRules,
+				PerIP:   rateLimitPerIP,
+			},
+		},
+		SoftDelete: SoftDeleteConfig{
+			Retention: time.Duration(softDeleteRetentionDays) * 24 * time.Hour,
 		},
 	}
 
@@ -1,14 +1,18 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
+	"os"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	_ "github.com/rezajo220/ecommerce/docs"
 	handlers "github.com/rezajo220/ecommerce/internal/handler"
 	"github.com/rezajo220/ecommerce/internal/handler/routes"
+	"github.com/rezajo220/ecommerce/internal/ratelimit"
 	"github.com/rezajo220/ecommerce/internal/repository"
 	services "github.com/rezajo220/ecommerce/internal/service"
 	echoSwagger "github.com/swaggo/echo-swagger"
@@ -21,20 +25,60 @@ import (
 // @BasePath /v1
 // @schemes http https
 
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description "Bearer " followed by an access token from /auth/login
+
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @description An API key from /admin/api-keys
+
 func main() {
 	cfg, err := LoadConfig()
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(cfg, os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "user" {
+		if err := runUser(cfg, os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "purge" {
+		if err := runPurge(cfg, os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if err := cfg.Auth.Validate(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
 	e := echo.New()
+	e.HTTPErrorHandler = handlers.HTTPErrorHandler
+	e.IPExtractor = cfg.Server.IPExtractor()
+	e.Validator = handlers.NewRequestValidator()
 
+	e.Use(handlers.RequestID())
 	e.Use(middleware.Logger())
 	e.Use(middleware.Recover())
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete},
-		AllowHeaders:     []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization},
+		AllowMethods:     []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete},
+		AllowHeaders:     []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization, handlers.HeaderAPIKey, handlers.HeaderIfMatch, handlers.HeaderAcceptCurrency},
+		ExposeHeaders:    []string{handlers.HeaderRateLimitLimit, handlers.HeaderRateLimitRemaining, handlers.HeaderRateLimitReset, handlers.HeaderRateLimitPolicy, echo.HeaderRetryAfter, echo.HeaderXRequestID, handlers.HeaderETag},
 		AllowCredentials: false,
 	}))
 
@@ -46,17 +90,94 @@ func main() {
 	}
 	defer pDB.Close()
 
+	paymentGateway, err := NewPaymentGateway(cfg.Payment)
+	if err != nil {
+		log.Fatalf("Failed to set up payment gateway: %v", err)
+	}
+
+	transactor := repository.NewTransactor(pDB)
+
 	productRepository := repository.NewProductRepository(pDB)
 	brandRepository := repository.NewBrandRepository(pDB)
+	categoryRepository := repository.NewCategoryRepository(pDB)
+	variantRepository := repository.NewVariantRepository(pDB)
+	inventoryRepository := repository.NewInventoryRepository(pDB)
+	reservationRepository := repository.NewReservationRepository(pDB)
+	cartRepository := repository.NewCartRepository(pDB)
+	orderRepository := repository.NewOrderRepository(pDB)
+	paymentRepository := repository.NewPaymentRepository(pDB)
+	userRepository := repository.NewUserRepository(pDB)
+	refreshTokenRepository := repository.NewRefreshTokenRepository(pDB)
+	roleRepository := repository.NewRoleRepository(pDB)
+	apiKeyRepository := repository.NewAPIKeyRepository(pDB)
+	auditRepository := repository.NewAuditRepository(pDB)
+	currencyRepository := repository.NewCurrencyRepository(pDB)
+	productPriceRepository := repository.NewProductPriceRepository(pDB)
+
+	productService := services.NewProductService(transactor, productRepository, brandRepository, categoryRepository, variantRepository, inventoryRepository, auditRepository, productPriceRepository)
+	brandService := services.NewBrandService(transactor, brandRepository, productRepository, auditRepository)
+	categoryService := services.NewCategoryService(transactor, categoryRepository, productRepository)
+	variantService := services.NewVariantService(variantRepository, productRepository)
+	inventoryService := services.NewInventoryService(transactor, inventoryRepository, productRepository)
+	reservationService := services.NewReservationService(transactor, reservationRepository, inventoryRepository)
+	cartService := services.NewCartService(cartRepository, productRepository)
+	accessService := services.NewAccessService(transactor, roleRepository, userRepository)
+	orderService := services.NewOrderService(transactor, orderRepository, cartRepository, productRepository, inventoryRepository, paymentRepository, accessService)
+	paymentService := services.NewPaymentService(transactor, paymentGateway, paymentRepository, orderRepository, orderService, accessService)
+	authService := services.NewAuthService(transactor, userRepository, refreshTokenRepository, cfg.Auth.ServiceConfig())
+	apiKeyService := services.NewAPIKeyService(transactor, apiKeyRepository, accessService)
+	auditService := services.NewAuditService(auditRepository)
+	currencyService := services.NewCurrencyService(transactor, currencyRepository, productRepository, auditRepository)
+	priceService := services.NewPriceService(transactor, productPriceRepository, productRepository, auditRepository)
 
-	productService := services.NewProductService(productRepository, brandRepository)
-	brandService := services.NewBrandService(brandRepository, productRepository)
+	preconditions := handlers.Preconditions{RequireIfMatch: cfg.Server.RequireIfMatch}
+	productHandler := handlers.NewProductHandler(productService, currencyService, preconditions)
+	brandHandler := handlers.NewBrandHandler(brandService, preconditions)
+	categoryHandler := handlers.NewCategoryHandler(categoryService)
+	variantHandler := handlers.NewVariantHandler(variantService)
+	inventoryHandler := handlers.NewInventoryHandler(inventoryService)
+	reservationHandler := handlers.NewReservationHandler(reservationService)
+	cartHandler := handlers.NewCartHandler(cartService)
+	orderHandler := handlers.NewOrderHandler(orderService)
+	paymentHandler := handlers.NewPaymentHandler(paymentService)
+	authHandler := handlers.NewAuthHandler(authService)
+	roleHandler := handlers.NewRoleHandler(accessService)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService)
+	auditHandler := handlers.NewAuditHandler(auditService)
+	currencyHandler := handlers.NewCurrencyHandler(currencyService)
+	priceHandler := handlers.NewPriceHandler(priceService)
 
-	productHandler := handlers.NewProductHandler(productService)
-	brandHandler := handlers.NewBrandHandler(brandService)
+	guard := handlers.NewAccessGuard(authService, accessService, apiKeyService)
 
-	routes.SetupProductRoutes(e, productHandler)
-	routes.SetupBrandRoutes(e, brandHandler)
+	rateLimitStore := ratelimit.NewMemoryStore()
+	if cfg.RateLimit.Enabled {
+		e.Use(handlers.IPRateLimit(rateLimitStore, cfg.RateLimit.Policy.PerIP))
+	}
+	e.Use(guard.Identify)
+	if cfg.RateLimit.Enabled {
+		e.Use(handlers.RateLimit(rateLimitStore, cfg.RateLimit.Policy))
+	}
+
+	routes.SetupProductRoutes(e, productHandler, guard)
+	routes.SetupBrandRoutes(e, brandHandler, guard)
+	routes.SetupCategoryRoutes(e, categoryHandler, guard)
+	routes.SetupVariantRoutes(e, variantHandler, guard)
+	routes.SetupInventoryRoutes(e, inventoryHandler, guard)
+	routes.SetupReservationRoutes(e, reservationHandler, guard)
+	routes.SetupCartRoutes(e, cartHandler, guard)
+	routes.SetupOrderRoutes(e, orderHandler, guard)
+	routes.SetupPaymentRoutes(e, paymentHandler, guard)
+	routes.SetupAuthRoutes(e, authHandler)
+	routes.SetupRoleRoutes(e, roleHandler, guard)
+	routes.SetupAPIKeyRoutes(e, apiKeyHandler, guard)
+	routes.SetupAuditRoutes(e, auditHandler, guard)
+	routes.SetupCurrencyRoutes(e, currencyHandler, guard)
+	routes.SetupPriceRoutes(e, priceHandler, guard)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go expireHolds(ctx, reservationService, orderService, paymentService, cfg.Inventory)
+	go applyScheduledPrices(ctx, priceService, cfg.Pricing.ScheduleInterval)
 
 	e.GET("/health", func(c echo.Context) error {
 		return c.JSON(http.StatusOK, map[string]string{
@@ -69,3 +190,78 @@ func main() {
 	log.Printf("Swagger documentation available at: http://localhost:%s/swagger/", cfg.Server.Port)
 	e.Logger.Fatal(e.Start(":" + cfg.Server.Port))
 }
+
+// expireHolds periodically marks reservations past their TTL as expired,
+// gives up payments left unsettled past theirs and cancels orders left
+// unpaid past theirs, until ctx is cancelled. Stale payments go first, so
+// their orders can expire in the same run.
+func expireHolds(ctx context.Context, reservationService services.ReservationService, orderService services.OrderService, paymentService services.PaymentService, cfg InventoryConfig) {
+	if cfg.ReservationExpiryInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(cfg.ReservationExpiryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			expired, err := reservationService.ExpireReservations(ctx)
+			if err != nil {
+				log.Printf("Failed to expire reservations: %v", err)
+			} else if expired > 0 {
+				log.Printf("Expired %d stale reservations", expired)
+			}
+
+			if cfg.StalePaymentTTL > 0 {
+				failed, err := paymentService.ExpireStalePayments(ctx, cfg.StalePaymentTTL)
+				if err != nil {
+					log.Printf("Failed to expire stale payments: %v", err)
+				} else if failed > 0 {
+					log.Printf("Gave up %d stale payments", failed)
+				}
+			}
+
+			if cfg.PendingOrderTTL <= 0 {
+				continue
+			}
+			cancelled, err := orderService.ExpirePendingOrders(ctx, cfg.PendingOrderTTL)
+			if err != nil {
+				log.Printf("Failed to expire pending orders: %v", err)
+				continue
+			}
+			if cancelled > 0 {
+				log.Printf("Cancelled %d unpaid orders", cancelled)
+			}
+		}
+	}
+}
+
+// applyScheduledPrices periodically writes scheduled prices that have taken
+// effect to their products until ctx is cancelled.
+func applyScheduledPrices(ctx context.Context, priceService services.PriceService, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			applied, err := priceService.ApplyScheduledPrices(ctx)
+			if err != nil {
+				log.Printf("Failed to apply scheduled prices: %v", err)
+				continue
+			}
+			if applied > 0 {
+				log.Printf("Applied scheduled prices to %d products", applied)
+			}
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/rezajo220/ecommerce/internal/migrate"
+	"github.com/rezajo220/ecommerce/migrations"
+)
+
+const migrateUsage = "usage: migrate up | down [steps] | status | create <name>"
+
+func runMigrate(cfg *Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	if args[0] == "create" {
+		if len(args) != 2 {
+			return errors.New(migrateUsage)
+		}
+		files, err := migrate.Create(cfg.Database.MigrationsDir, args[1])
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			fmt.Println("Created", file)
+		}
+		return nil
+	}
+
+	switch args[0] {
+	case "up", "down", "status":
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	dbCfg := cfg.Database
+	dbCfg.MigrateOnStart = false
+	dbCfg.RequireLatestSchema = false
+
+	db, err := NewPostgresDB(dbCfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	migrator, err := migrate.New(db, migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			fmt.Printf("Applied %d_%s\n", m.Version, m.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("Schema is up to date")
+		}
+		return err
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of steps %q", args[1])
+			}
+		}
+		rolledBack, err := migrator.Down(ctx, steps)
+		for _, m := range rolledBack {
+			fmt.Printf("Rolled back %d_%s\n", m.Version, m.Name)
+		}
+		return err
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			applied := "pending"
+			if s.Applied() {
+				applied = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%06d  %-40s %s\n", s.Version, s.Name, applied)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/rezajo220/ecommerce/internal/repository"
+	services "github.com/rezajo220/ecommerce/internal/service"
+)
+
+const purgeUsage = "usage: purge [retention-days]"
+
+// runPurge hard deletes products and brands that were soft deleted more than
+// the retention window ago. Products go first so that brands only they still
+// referenced can be purged in the same run.
+func runPurge(cfg *Config, args []string) error {
+	retention := cfg.SoftDelete.Retention
+	switch len(args) {
+	case 0:
+	case 1:
+		days, err := strconv.Atoi(args[0])
+		if err != nil || days < 0 {
+			return errors.New(purgeUsage)
+		}
+		retention = time.Duration(days) * 24 * time.Hour
+	default:
+		return errors.New(purgeUsage)
+	}
+
+	db, err := NewPostgresDB(cfg.Database)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	transactor := repository.NewTransactor(db)
+	productRepository := repository.NewProductRepository(db)
+	brandRepository := repository.NewBrandRepository(db)
+	auditRepository := repository.NewAuditRepository(db)
+
+	productService := services.NewProductService(
+		transactor,
+		productRepository,
+		brandRepository,
+		repository.NewCategoryRepository(db),
+		repository.NewVariantRepository(db),
+		repository.NewInventoryRepository(db),
+		auditRepository,
+		repository.NewProductPriceRepository(db),
+	)
+	brandService := services.NewBrandService(transactor, brandRepository, productRepository, auditRepository)
+
+	ctx := context.Background()
+	deletedBefore := time.Now().Add(-retention)
+
+	products, err := productService.PurgeDeletedProducts(ctx, deletedBefore)
+	if err != nil {
+		return fmt.Errorf("failed to purge products: %w", err)
+	}
+	brands, err := brandService.PurgeDeletedBrands(ctx, deletedBefore)
+	if err != nil {
+		return fmt.Errorf("failed to purge brands: %w", err)
+	}
+
+	fmt.Printf("Purged %d products and %d brands deleted before %s\n", products, brands, deletedBefore.Format(time.RFC3339))
+	return nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rezajo220/ecommerce/internal/repository"
+	services "github.com/rezajo220/ecommerce/internal/service"
+)
+
+const userUsage = `usage:
+  user create <email>        create a user; the password is read from stdin
+  user grant <email> <role>  assign a role, e.g. to bootstrap the first admin`
+
+// runUser manages API users from the command line. Passwords are read from
+// stdin so they do not end up in shell history or the process list.
+func runUser(cfg *Config, args []string) error {
+	switch {
+	case len(args) == 2 && args[0] == "create":
+		return createUser(cfg, args[1])
+	case len(args) == 3 && args[0] == "grant":
+		return grantRole(cfg, args[1], args[2])
+	default:
+		return errors.New(userUsage)
+	}
+}
+
+func createUser(cfg *Config, email string) error {
+	fmt.Fprint(os.Stderr, "Password: ")
+	password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && password == "" {
+		return fmt.Errorf("failed to read password: %w", err)
+	}
+	password = strings.TrimRight(password, "\r\n")
+
+	db, err := NewPostgresDB(cfg.Database)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	authService := services.NewAuthService(
+		repository.NewTransactor(db),
+		repository.NewUserRepository(db),
+		repository.NewRefreshTokenRepository(db),
+		cfg.Auth.ServiceConfig(),
+	)
+
+	user, err := authService.CreateUser(context.Background(), email, password)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Created user", user.ID, user.Email)
+	return nil
+}
+
+func grantRole(cfg *Config, email, role string) error {
+	db, err := NewPostgresDB(cfg.Database)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	accessService := services.NewAccessService(
+		repository.NewTransactor(db),
+		repository.NewRoleRepository(db),
+		repository.NewUserRepository(db),
+	)
+
+	if err := accessService.GrantRole(context.Background(), email, role); err != nil {
+		return err
+	}
+
+	fmt.Println("Granted role", role, "to", email)
+	return nil
+}
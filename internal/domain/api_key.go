@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"net"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKeyPrefix starts every API key so leaked keys are easy to recognise.
+const APIKeyPrefix = "ek_"
+
+// APIKey is a long-lived credential for machine-to-machine access. Only the
+// hash of the secret is stored; the full key is shown once, on creation or
+// rotation.
+type APIKey struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	Name       string     `json:"name" db:"name"`
+	Prefix     string     `json:"prefix" db:"prefix"`
+	SecretHash string     `json:"-" db:"secret_hash"`
+	Scopes     []string   `json:"scopes" db:"-"`
+	AllowedIPs []string   `json:"allowed_ips" db:"-"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	CreatedBy  string     `json:"created_by" db:"created_by"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// Usable reports whether the key is neither revoked nor expired at now.
+func (k *APIKey) Usable(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
+
+// AllowsIP reports whether ip falls in the key's allowlist. An empty
+// allowlist allows every address.
+func (k *APIKey) AllowsIP(ip string) bool {
+	if len(k.AllowedIPs) == 0 {
+		return true
+	}
+
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, cidr := range k.AllowedIPs {
+		if _, network, err := net.ParseCIDR(cidr); err == nil && network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasScopes reports whether the key was granted every one of permissions.
+func (k *APIKey) HasScopes(permissions ...string) bool {
+	for _, permission := range permissions {
+		found := false
+		for _, scope := range k.Scopes {
+			if scope == permission {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+type CreateAPIKeyRequest struct {
+	Name       string     `json:"name" validate:"required,max=100"`
+	Scopes     []string   `json:"scopes" validate:"required,min=1"`
+	AllowedIPs []string   `json:"allowed_ips" example:"10.0.0.0/8"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+}
+
+// IssuedAPIKey is returned when a key is created or rotated; Key holds the
+// full secret and is never shown again.
+type IssuedAPIKey struct {
+	APIKey
+	Key string `json:"key" example:"ek_1a2b3c4d5e6f_..."`
+}
+
+// NormalizeAllowedIPs turns single addresses into host CIDR blocks and
+// rejects anything that is neither an address nor a CIDR block.
+func NormalizeAllowedIPs(entries []string) ([]string, error) {
+	normalized := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			normalized = append(normalized, network.String())
+			continue
+		}
+
+		ip := net.ParseIP(entry)
+		if ip == nil {
+			return nil, NewValidationError("allowed_ips: %q is not an IP address or CIDR block", entry)
+		}
+		bits := 128
+		if ip.To4() != nil {
+			ip = ip.To4()
+			bits = 32
+		}
+		normalized = append(normalized, (&net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}).String())
+	}
+	return normalized, nil
+}
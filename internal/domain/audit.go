@@ -0,0 +1,134 @@
+package domain
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AuditAction string
+
+const (
+	AuditCreate  AuditAction = "create"
+	AuditUpdate  AuditAction = "update"
+	AuditDelete  AuditAction = "delete"
+	AuditRestore AuditAction = "restore"
+)
+
+// Audited entity types.
+const (
+	AuditEntityProduct = "product"
+	AuditEntityBrand   = "brand"
+)
+
+// auditIgnoredFields are left out of diffs: the ID is on the entry itself,
+// timestamps and versions change on every write and the others are derived
+// from fields that are audited.
+var auditIgnoredFields = map[string]bool{
+	"id":            true,
+	"created_at":    true,
+	"updated_at":    true,
+	"version":       true,
+	"available_qty": true,
+	"brand_name":    true,
+}
+
+// FieldChange is the value of one field before and after a change, as JSON.
+// Before is absent for creates and After for deletes.
+type FieldChange struct {
+	Before json.RawMessage `json:"before,omitempty" swaggertype:"object"`
+	After  json.RawMessage `json:"after,omitempty" swaggertype:"object"`
+}
+
+type AuditEntry struct {
+	ID         uuid.UUID              `json:"id" db:"id"`
+	Actor      string                 `json:"actor" db:"actor"`
+	Action     AuditAction            `json:"action" db:"action"`
+	EntityType string                 `json:"entity_type" db:"entity_type"`
+	EntityID   uuid.UUID              `json:"entity_id" db:"entity_id"`
+	Changes    map[string]FieldChange `json:"changes" db:"-"`
+	RequestID  string                 `json:"request_id,omitempty" db:"request_id"`
+	CreatedAt  time.Time              `json:"created_at" db:"created_at"`
+}
+
+// AuditFilter narrows GET /audit; zero fields do not filter.
+type AuditFilter struct {
+	EntityType string
+	EntityID   *uuid.UUID
+	Actor      string
+	Action     AuditAction
+	From       *time.Time
+	To         *time.Time
+}
+
+type AuditListResponse struct {
+	Entries    []AuditEntry `json:"entries"`
+	Total      int          `json:"total"`
+	Page       int          `json:"page"`
+	Limit      int          `json:"limit"`
+	TotalPages int          `json:"total_pages"`
+}
+
+// AuditDiff compares the JSON forms of before and after and returns the
+// fields that differ. Either side may be nil, for creates and deletes.
+func AuditDiff(before, after interface{}) (map[string]FieldChange, error) {
+	beforeFields, err := auditFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := auditFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := map[string]FieldChange{}
+	for field, value := range beforeFields {
+		if !bytes.Equal(value, afterFields[field]) {
+			changes[field] = FieldChange{Before: value, After: afterFields[field]}
+		}
+	}
+	for field, value := range afterFields {
+		if _, ok := beforeFields[field]; !ok {
+			changes[field] = FieldChange{After: value}
+		}
+	}
+	return changes, nil
+}
+
+func auditFields(v interface{}) (map[string]json.RawMessage, error) {
+	fields := map[string]json.RawMessage{}
+	if v == nil {
+		return fields, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	for field := range fields {
+		if auditIgnoredFields[field] {
+			delete(fields, field)
+		}
+	}
+	return fields, nil
+}
+
+type requestIDKey struct{}
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext returns the ID of the request being served, or "" for
+// background work.
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
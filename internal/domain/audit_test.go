@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestAuditDiff(t *testing.T) {
+	brand := uuid.MustParse("6f1c2a7e-3b4d-4c5e-8f90-a1b2c3d4e5f6")
+	before := &Product{
+		ID:          uuid.New(),
+		ProductName: "Laptop",
+		Price:       NewDecimal(1250, 2),
+		Currency:    "USD",
+		Qty:         3,
+		BrandID:     brand,
+		Version:     1,
+		CreatedAt:   time.Now(),
+	}
+	renamed := *before
+	renamed.ProductName = "Laptop Pro"
+	renamed.Version = 2
+	renamed.UpdatedAt = time.Now()
+	renamed.AvailableQty = 1
+	rescaled := *before
+	rescaled.Price = NewDecimal(12500, 3)
+
+	tests := []struct {
+		name          string
+		before, after interface{}
+		want          map[string]FieldChange
+	}{
+		{name: "nothing to compare", want: map[string]FieldChange{}},
+		{name: "unchanged", before: before, after: before, want: map[string]FieldChange{}},
+		{
+			name: "ignores derived and bookkeeping fields", before: before, after: &renamed,
+			want: map[string]FieldChange{"product_name": {Before: raw(`"Laptop"`), After: raw(`"Laptop Pro"`)}},
+		},
+		{
+			name: "compares JSON text", before: before, after: &rescaled,
+			want: map[string]FieldChange{"price": {Before: raw(`"12.50"`), After: raw(`"12.500"`)}},
+		},
+		{
+			name: "create", after: map[string]interface{}{"id": brand, "product_name": "Laptop"},
+			want: map[string]FieldChange{"product_name": {After: raw(`"Laptop"`)}},
+		},
+		{
+			name: "delete", before: map[string]interface{}{"id": brand, "product_name": "Laptop"},
+			want: map[string]FieldChange{"product_name": {Before: raw(`"Laptop"`)}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AuditDiff(tt.before, tt.after)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AuditDiff = %s, want %s", describeChanges(got), describeChanges(tt.want))
+			}
+		})
+	}
+}
+
+func raw(s string) []byte {
+	return []byte(s)
+}
+
+func describeChanges(changes map[string]FieldChange) map[string][2]string {
+	described := make(map[string][2]string, len(changes))
+	for field, change := range changes {
+		described[field] = [2]string{string(change.Before), string(change.After)}
+	}
+	return described
+}
@@ -11,8 +11,30 @@ type Brand struct {
 	BrandName string    `json:"brand_name" db:"brand_name"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+
+	// Version increases with every change and is sent as the ETag.
+	Version int `json:"version" db:"version"`
+
+	// DeletedAt is set once the brand is soft deleted.
+	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
 }
 
 type CreateBrandRequest struct {
 	BrandName string `json:"brand_name" validate:"required"`
 }
+
+// ReplaceBrandRequest is the body of PUT /brands/{id}.
+type ReplaceBrandRequest struct {
+	BrandName string `json:"brand_name" validate:"required"`
+}
+
+// UpdateBrandRequest is a JSON merge patch for a brand; nil fields are left unchanged.
+type UpdateBrandRequest struct {
+	BrandName *string `json:"brand_name,omitempty" validate:"omitempty,min=1"`
+}
+
+// ChangesNothing reports whether applying the patch would leave brand as it
+// is, in which case nothing is written.
+func (r *UpdateBrandRequest) ChangesNothing(brand *Brand) bool {
+	return r.BrandName == nil || *r.BrandName == brand.BrandName
+}
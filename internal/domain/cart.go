@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Cart is a shopping cart. Items are priced at the current product price
+// every time the cart is read; Subtotal, Currency and ItemCount are derived
+// from them.
+type Cart struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	Items     []CartItem `json:"items" db:"-"`
+	ItemCount float64    `json:"item_count" db:"-"`
+	Subtotal  Decimal    `json:"subtotal" db:"-" swaggertype:"string" example:"24000001.00"`
+	Currency  string     `json:"currency,omitempty" db:"-" example:"IDR"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+
+	// CheckedOutAt is set once the cart has been turned into an order;
+	// after that the cart can no longer be changed.
+	CheckedOutAt *time.Time `json:"checked_out_at,omitempty" db:"checked_out_at"`
+
+	// HolderID is the caller that created the cart, see HeldBy.
+	HolderID *uuid.UUID `json:"-" db:"holder_id"`
+}
+
+// HeldBy reports whether user created the cart. Carts from before holders
+// were recorded belong to nobody.
+func (c *Cart) HeldBy(user *AuthUser) bool {
+	return user != nil && c.HolderID != nil && *c.HolderID == user.ID
+}
+
+// CartItem is one product line of a cart. UnitPrice and Currency are what the
+// line was last priced at; CurrentPrice, CurrentCurrency and AvailableQty
+// come from the product.
+type CartItem struct {
+	ID           uuid.UUID `json:"id" db:"id"`
+	CartID       uuid.UUID `json:"cart_id" db:"cart_id"`
+	ProductID    uuid.UUID `json:"product_id" db:"product_id"`
+	ProductName  string    `json:"product_name" db:"product_name"`
+	Qty          float64   `json:"qty" db:"qty"`
+	UnitPrice    Decimal   `json:"unit_price" db:"unit_price" swaggertype:"string" example:"12000000.50"`
+	Currency     string    `json:"currency" db:"currency" example:"IDR"`
+	LineTotal    Decimal   `json:"line_total" db:"-" swaggertype:"string" example:"24000001.00"`
+	AvailableQty float64   `json:"available_qty" db:"available_qty"`
+	Warnings     []string  `json:"warnings,omitempty" db:"-"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+
+	CurrentPrice    Decimal `json:"-" db:"current_price"`
+	CurrentCurrency string  `json:"-" db:"current_currency"`
+}
+
+type AddCartItemRequest struct {
+	ProductID uuid.UUID `json:"product_id" validate:"required"`
+	Qty       float64   `json:"qty" validate:"required,gt=0"`
+}
+
+type UpdateCartItemRequest struct {
+	Qty float64 `json:"qty" validate:"required,gt=0"`
+}
+
+// Reprice moves the line to the product's current price and currency and
+// reports whether either changed.
+func (i *CartItem) Reprice() bool {
+	if i.UnitPrice.Cmp(i.CurrentPrice) == 0 && i.Currency == i.CurrentCurrency {
+		return false
+	}
+	if i.Currency == i.CurrentCurrency {
+		i.Warnings = append(i.Warnings, fmt.Sprintf("price changed from %s to %s", i.UnitPrice, i.CurrentPrice))
+	} else {
+		i.Warnings = append(i.Warnings, fmt.Sprintf("price changed from %s %s to %s %s", i.UnitPrice, i.Currency, i.CurrentPrice, i.CurrentCurrency))
+	}
+	i.UnitPrice = i.CurrentPrice
+	i.Currency = i.CurrentCurrency
+	return true
+}
+
+// CheckStock warns when the line asks for more than is available.
+func (i *CartItem) CheckStock() {
+	switch {
+	case i.AvailableQty <= 0:
+		i.Warnings = append(i.Warnings, "out of stock")
+	case i.Qty > i.AvailableQty:
+		i.Warnings = append(i.Warnings, fmt.Sprintf("only %g available", i.AvailableQty))
+	}
+}
+
+// Total fills in line totals, the item count, the currency and the
+// subtotal. The cart is in the currency of its oldest line. A line priced in
+// another currency, because its product's currency changed after it was
+// added, is left out of the subtotal and flagged: the cart cannot be checked
+// out until it is removed.
+func (c *Cart) Total() {
+	c.ItemCount = 0
+	c.Subtotal = Decimal{}
+	c.Currency = ""
+	for i := range c.Items {
+		item := &c.Items[i]
+		item.LineTotal = item.UnitPrice.Mul(DecimalFromFloat(item.Qty))
+		c.ItemCount += item.Qty
+		if c.Currency == "" {
+			c.Currency = item.Currency
+		}
+		if item.Currency != c.Currency {
+			item.Warnings = append(item.Warnings, fmt.Sprintf("priced in %s, but the cart is in %s", item.Currency, c.Currency))
+			continue
+		}
+		c.Subtotal = c.Subtotal.Add(item.LineTotal)
+	}
+}
@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCartTotal(t *testing.T) {
+	line := func(price Decimal, currency string, qty float64) CartItem {
+		return CartItem{UnitPrice: price, Currency: currency, Qty: qty}
+	}
+
+	tests := []struct {
+		name         string
+		items        []CartItem
+		wantSubtotal string
+		wantCurrency string
+		wantWarnings [][]string
+	}{
+		{name: "empty", wantSubtotal: "0"},
+		{
+			name:         "one currency",
+			items:        []CartItem{line(NewDecimal(1250, 2), "USD", 2), line(NewDecimal(5, 1), "USD", 0.5)},
+			wantSubtotal: "25.25",
+			wantCurrency: "USD",
+			wantWarnings: [][]string{nil, nil},
+		},
+		{
+			name:         "leaves out other currencies",
+			items:        []CartItem{line(NewDecimal(16000, 0), "IDR", 1), line(NewDecimal(100, 2), "USD", 3)},
+			wantSubtotal: "16000",
+			wantCurrency: "IDR",
+			wantWarnings: [][]string{nil, {"priced in USD, but the cart is in IDR"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cart := &Cart{Items: tt.items}
+			cart.Total()
+			if cart.Subtotal.String() != tt.wantSubtotal || cart.Currency != tt.wantCurrency {
+				t.Errorf("Total() = %s %q, want %s %q", cart.Subtotal, cart.Currency, tt.wantSubtotal, tt.wantCurrency)
+			}
+			for i, item := range cart.Items {
+				if !reflect.DeepEqual(item.Warnings, tt.wantWarnings[i]) {
+					t.Errorf("item %d warnings = %q, want %q", i, item.Warnings, tt.wantWarnings[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCartItemReprice(t *testing.T) {
+	tests := []struct {
+		name        string
+		item        CartItem
+		wantChanged bool
+		wantWarning string
+	}{
+		{
+			name: "unchanged",
+			item: CartItem{UnitPrice: NewDecimal(150, 2), Currency: "USD", CurrentPrice: NewDecimal(15, 1), CurrentCurrency: "USD"},
+		},
+		{
+			name:        "new price",
+			item:        CartItem{UnitPrice: NewDecimal(150, 2), Currency: "USD", CurrentPrice: NewDecimal(175, 2), CurrentCurrency: "USD"},
+			wantChanged: true,
+			wantWarning: "price changed from 1.50 to 1.75",
+		},
+		{
+			name:        "new currency",
+			item:        CartItem{UnitPrice: NewDecimal(150, 2), Currency: "USD", CurrentPrice: NewDecimal(24000, 0), CurrentCurrency: "IDR"},
+			wantChanged: true,
+			wantWarning: "price changed from 1.50 USD to 24000 IDR",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := tt.item
+			if changed := item.Reprice(); changed != tt.wantChanged {
+				t.Fatalf("Reprice() = %v, want %v", changed, tt.wantChanged)
+			}
+			if item.UnitPrice.Cmp(item.CurrentPrice) != 0 || item.Currency != item.CurrentCurrency {
+				t.Errorf("line priced at %s %s, want %s %s", item.UnitPrice, item.Currency, item.CurrentPrice, item.CurrentCurrency)
+			}
+			if tt.wantWarning != "" && (len(item.Warnings) != 1 || item.Warnings[0] != tt.wantWarning) {
+				t.Errorf("warnings = %q, want %q", item.Warnings, tt.wantWarning)
+			}
+		})
+	}
+}
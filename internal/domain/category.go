@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Category struct {
+	ID           uuid.UUID     `json:"id" db:"id"`
+	CategoryName string        `json:"category_name" db:"category_name"`
+	ParentID     *uuid.UUID    `json:"parent_id" db:"parent_id"`
+	CreatedAt    time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time     `json:"updated_at" db:"updated_at"`
+	Path         []CategoryRef `json:"path,omitempty" db:"-"`
+}
+
+// CategoryRef is one step of a breadcrumb path.
+type CategoryRef struct {
+	ID           uuid.UUID `json:"id" db:"id"`
+	CategoryName string    `json:"category_name" db:"category_name"`
+}
+
+// CategoryPath is a breadcrumb from the root category down to a leaf.
+type CategoryPath []CategoryRef
+
+type CreateCategoryRequest struct {
+	CategoryName string     `json:"category_name" validate:"required"`
+	ParentID     *uuid.UUID `json:"parent_id,omitempty"`
+}
+
+// ReplaceCategoryRequest is the body of PUT /categories/{id}. A null or
+// missing parent_id moves the category to the root of the tree.
+type ReplaceCategoryRequest struct {
+	CategoryName string     `json:"category_name" validate:"required"`
+	ParentID     *uuid.UUID `json:"parent_id"`
+}
+
+// SetProductCategoriesRequest replaces the set of categories a product belongs to.
+type SetProductCategoriesRequest struct {
+	CategoryIDs []uuid.UUID `json:"category_ids" validate:"required"`
+}
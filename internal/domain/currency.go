@@ -0,0 +1,129 @@
+package domain
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultCurrency is the base currency of products created without one.
+const DefaultCurrency = "IDR"
+
+// Currency is a currency the catalog can price in. Converted prices are
+// rounded half away from zero to Decimals digits after the decimal point.
+type Currency struct {
+	Code     string `json:"code" example:"USD"`
+	Decimals int32  `json:"decimals" example:"2"`
+}
+
+// currencies are the supported currencies and their rounding rules. Rupiah
+// prices are quoted without minor units.
+var currencies = map[string]Currency{
+	"IDR": {Code: "IDR", Decimals: 0},
+	"USD": {Code: "USD", Decimals: 2},
+}
+
+// LookupCurrency finds a supported currency by its ISO 4217 code, in any
+// letter case.
+func LookupCurrency(code string) (Currency, error) {
+	currency, ok := currencies[strings.ToUpper(strings.TrimSpace(code))]
+	if !ok {
+		return Currency{}, NewValidationError("unsupported currency %q", code)
+	}
+	return currency, nil
+}
+
+// Round applies the currency's rounding rule to an amount in it.
+func (c Currency) Round(amount Decimal) Decimal {
+	return amount.Round(c.Decimals)
+}
+
+// ExchangeRate says one unit of BaseCurrency is worth Rate units of
+// QuoteCurrency.
+type ExchangeRate struct {
+	BaseCurrency  string    `json:"base_currency" db:"base_currency" example:"USD"`
+	QuoteCurrency string    `json:"quote_currency" db:"quote_currency" example:"IDR"`
+	Rate          Decimal   `json:"rate" db:"rate" swaggertype:"string" example:"16250"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type SetExchangeRateRequest struct {
+	Rate Decimal `json:"rate" validate:"required,gt=0" swaggertype:"string" example:"16250"`
+}
+
+// ProductCurrencyPrice overrides the converted price of a product in one
+// currency.
+type ProductCurrencyPrice struct {
+	ProductID uuid.UUID `json:"product_id" db:"product_id"`
+	Currency  string    `json:"currency" db:"currency" example:"USD"`
+	Price     Decimal   `json:"price" db:"price" swaggertype:"string" example:"749.00"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type SetProductCurrencyPriceRequest struct {
+	Price Decimal `json:"price" validate:"required,gt=0" swaggertype:"string" example:"749.00"`
+}
+
+// ImportExchangeRatesResponse reports how many rates a CSV import set.
+type ImportExchangeRatesResponse struct {
+	Imported int            `json:"imported" example:"2"`
+	Rates    []ExchangeRate `json:"rates"`
+}
+
+// ParseExchangeRatesCSV reads "base,quote,rate" records such as
+// "USD,IDR,16250", with an optional header line naming the columns. Every
+// record is checked before any is returned, so an import is all or nothing;
+// errors name the offending line.
+func ParseExchangeRatesCSV(r io.Reader) ([]ExchangeRate, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+
+	var rates []ExchangeRate
+	seen := map[[2]string]int{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, NewValidationError("invalid CSV: %v", err)
+		}
+		line, _ := reader.FieldPos(0)
+		if line == 1 && strings.EqualFold(strings.TrimSpace(record[0]), "base_currency") {
+			continue
+		}
+
+		base, err := LookupCurrency(record[0])
+		if err != nil {
+			return nil, NewValidationError("line %d: unsupported currency %q", line, record[0])
+		}
+		quote, err := LookupCurrency(record[1])
+		if err != nil {
+			return nil, NewValidationError("line %d: unsupported currency %q", line, record[1])
+		}
+		if base.Code == quote.Code {
+			return nil, NewValidationError("line %d: %s", line, ErrSameCurrency)
+		}
+		rate, err := ParseDecimal(record[2])
+		if err != nil || rate.Sign() <= 0 {
+			return nil, NewValidationError("line %d: rate must be a number greater than 0", line)
+		}
+
+		pair := [2]string{base.Code, quote.Code}
+		if first, ok := seen[pair]; ok {
+			return nil, NewValidationError("line %d: %s/%s was already given on line %d", line, base.Code, quote.Code, first)
+		}
+		seen[pair] = line
+		rates = append(rates, ExchangeRate{BaseCurrency: base.Code, QuoteCurrency: quote.Code, Rate: rate})
+	}
+
+	if len(rates) == 0 {
+		return nil, NewValidationError("the CSV contains no exchange rates")
+	}
+	return rates, nil
+}
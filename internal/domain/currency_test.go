@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseExchangeRatesCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    []string
+		wantErr string
+	}{
+		{name: "single rate", in: "USD,IDR,16250\n", want: []string{"USD/IDR=16250"}},
+		{
+			name: "header, spacing and case",
+			in:   "base_currency,quote_currency,rate\nusd, idr, 16250.5\nIDR,USD,0.0000615\n",
+			want: []string{"USD/IDR=16250.5", "IDR/USD=0.0000615"},
+		},
+		{name: "empty", in: "", wantErr: "no exchange rates"},
+		{name: "header only", in: "base_currency,quote_currency,rate\n", wantErr: "no exchange rates"},
+		{name: "header after line one", in: "USD,IDR,1\nbase_currency,quote_currency,rate\n", wantErr: "line 2"},
+		{name: "wrong field count", in: "USD,IDR\n", wantErr: "invalid CSV"},
+		{name: "unsupported base", in: "EUR,IDR,17000\n", wantErr: `line 1: unsupported currency "EUR"`},
+		{name: "unsupported quote", in: "USD,IDR,1\nUSD,EUR,0.9\n", wantErr: `line 2: unsupported currency "EUR"`},
+		{name: "same currency", in: "USD,usd,1\n", wantErr: "line 1: an exchange rate needs two different currencies"},
+		{name: "zero rate", in: "USD,IDR,0\n", wantErr: "line 1: rate must be"},
+		{name: "negative rate", in: "USD,IDR,-1\n", wantErr: "line 1: rate must be"},
+		{name: "non-numeric rate", in: "USD,IDR,lots\n", wantErr: "line 1: rate must be"},
+		{name: "out of range rate", in: "USD,IDR,1e2000000000\n", wantErr: "line 1: rate must be"},
+		{name: "duplicate pair", in: "USD,IDR,16250\nusd,idr,16300\n", wantErr: "line 2: USD/IDR was already given on line 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rates, err := ParseExchangeRatesCSV(strings.NewReader(tt.in))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) || !errors.Is(err, ErrValidation) {
+					t.Fatalf("error = %v, want a validation error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := make([]string, len(rates))
+			for i, rate := range rates {
+				got[i] = rate.BaseCurrency + "/" + rate.QuoteCurrency + "=" + rate.Rate.String()
+			}
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("rates = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProductCursor is a keyset position in the (created_at DESC, id DESC)
+// product ordering. Backward cursors page towards newer products.
+type ProductCursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        uuid.UUID `json:"id"`
+	Backward  bool      `json:"b,omitempty"`
+}
+
+func NewProductCursor(p Product, backward bool) *ProductCursor {
+	return &ProductCursor{CreatedAt: p.CreatedAt, ID: p.ID, Backward: backward}
+}
+
+// Encode returns the opaque string handed out to clients.
+func (c *ProductCursor) Encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func DecodeProductCursor(encoded string) (*ProductCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, NewBadRequestError("Invalid cursor")
+	}
+
+	var cursor ProductCursor
+	if err := json.Unmarshal(raw, &cursor); err != nil || cursor.ID == uuid.Nil || cursor.CreatedAt.IsZero() {
+		return nil, NewBadRequestError("Invalid cursor")
+	}
+	return &cursor, nil
+}
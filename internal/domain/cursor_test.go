@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestProductCursorRoundTrip(t *testing.T) {
+	product := Product{
+		ID:        uuid.MustParse("6f1c2a7e-3b4d-4c5e-8f90-a1b2c3d4e5f6"),
+		CreatedAt: time.Date(2024, 5, 17, 8, 30, 15, 123456000, time.UTC),
+	}
+
+	for _, backward := range []bool{false, true} {
+		cursor := NewProductCursor(product, backward)
+		decoded, err := DecodeProductCursor(cursor.Encode())
+		if err != nil {
+			t.Fatalf("DecodeProductCursor: %v", err)
+		}
+		if decoded.ID != product.ID || !decoded.CreatedAt.Equal(product.CreatedAt) || decoded.Backward != backward {
+			t.Errorf("round trip = %+v, want %+v", decoded, cursor)
+		}
+	}
+}
+
+func TestDecodeProductCursorRejects(t *testing.T) {
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{name: "empty", in: ""},
+		{name: "not base64", in: "not a cursor!"},
+		{name: "padded base64", in: base64.URLEncoding.EncodeToString([]byte(`{"t":"2024-05-17T08:30:15Z"}`))},
+		{name: "not JSON", in: encode("hello")},
+		{name: "missing id", in: encode(`{"t":"2024-05-17T08:30:15Z"}`)},
+		{name: "missing time", in: encode(`{"id":"6f1c2a7e-3b4d-4c5e-8f90-a1b2c3d4e5f6"}`)},
+		{name: "invalid id", in: encode(`{"t":"2024-05-17T08:30:15Z","id":"42"}`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeProductCursor(tt.in); err == nil {
+				t.Errorf("DecodeProductCursor(%q) succeeded", tt.in)
+			}
+		})
+	}
+}
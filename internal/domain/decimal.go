@@ -0,0 +1,221 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Decimal is an exact base-10 number used for money. It is read from and
+// written to NUMERIC columns as text and encoded in JSON as a string, so a
+// price such as 12000000.50 never passes through float64. The zero value is
+// 0.
+type Decimal struct {
+	coef  *big.Int // nil means zero
+	scale int32    // digits after the decimal point
+}
+
+// NewDecimal returns coef × 10^-scale, e.g. NewDecimal(1250, 2) is 12.50.
+func NewDecimal(coef int64, scale int32) Decimal {
+	if scale < 0 {
+		return Decimal{coef: new(big.Int).Mul(big.NewInt(coef), pow10(-scale))}
+	}
+	return Decimal{coef: big.NewInt(coef), scale: scale}
+}
+
+// maxDecimalScale bounds how many digits a parsed number may have after the
+// decimal point, and how many zeros an exponent may add before it, so input
+// such as "1e2000000000" cannot make the parser build a huge number.
+const maxDecimalScale = 64
+
+// ParseDecimal reads a plain or exponent notation number such as "12.50",
+// "-3" or "1.5e3". Trailing zeros are kept as written. Numbers whose scale
+// would exceed maxDecimalScale either way are rejected.
+func ParseDecimal(s string) (Decimal, error) {
+	mantissa, exponent := strings.TrimSpace(s), int64(0)
+	if i := strings.IndexAny(mantissa, "eE"); i >= 0 {
+		var err error
+		if exponent, err = strconv.ParseInt(mantissa[i+1:], 10, 32); err != nil {
+			return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+		}
+		mantissa = mantissa[:i]
+	}
+
+	sign := ""
+	if mantissa != "" && (mantissa[0] == '-' || mantissa[0] == '+') {
+		sign, mantissa = mantissa[:1], mantissa[1:]
+	}
+	intPart, fracPart, _ := strings.Cut(mantissa, ".")
+	digits := intPart + fracPart
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+
+	scale := int64(len(fracPart)) - exponent
+	if scale > maxDecimalScale || scale < -maxDecimalScale {
+		return Decimal{}, fmt.Errorf("decimal %q is out of range", s)
+	}
+
+	coef, _ := new(big.Int).SetString(sign+digits, 10)
+	if scale < 0 {
+		return Decimal{coef: coef.Mul(coef, pow10(int32(-scale)))}, nil
+	}
+	return Decimal{coef: coef, scale: int32(scale)}, nil
+}
+
+// DecimalFromFloat converts f through its shortest decimal representation,
+// so 0.1 becomes exactly 0.1. It is meant for quantities, which are still
+// float64, when they are multiplied with prices.
+func DecimalFromFloat(f float64) Decimal {
+	d, _ := ParseDecimal(strconv.FormatFloat(f, 'f', -1, 64))
+	return d
+}
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+func (d Decimal) int() *big.Int {
+	if d.coef == nil {
+		return new(big.Int)
+	}
+	return d.coef
+}
+
+// rescaled returns the coefficient of d at scale, which must not be below
+// d.scale.
+func (d Decimal) rescaled(scale int32) *big.Int {
+	return new(big.Int).Mul(d.int(), pow10(scale-d.scale))
+}
+
+func (d Decimal) Add(o Decimal) Decimal {
+	scale := max(d.scale, o.scale)
+	return Decimal{coef: new(big.Int).Add(d.rescaled(scale), o.rescaled(scale)), scale: scale}
+}
+
+func (d Decimal) Sub(o Decimal) Decimal {
+	return d.Add(o.Neg())
+}
+
+func (d Decimal) Mul(o Decimal) Decimal {
+	return Decimal{coef: new(big.Int).Mul(d.int(), o.int()), scale: d.scale + o.scale}
+}
+
+func (d Decimal) Neg() Decimal {
+	return Decimal{coef: new(big.Int).Neg(d.int()), scale: d.scale}
+}
+
+// Cmp compares the values of d and o, ignoring trailing zeros: 1.5 and 1.50
+// are equal.
+func (d Decimal) Cmp(o Decimal) int {
+	scale := max(d.scale, o.scale)
+	return d.rescaled(scale).Cmp(o.rescaled(scale))
+}
+
+func (d Decimal) Sign() int {
+	return d.int().Sign()
+}
+
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Round rounds d to places digits after the decimal point, halves away from
+// zero. Values that already have no more digits are returned unchanged.
+func (d Decimal) Round(places int32) Decimal {
+	if places >= d.scale {
+		return d
+	}
+
+	divisor := pow10(d.scale - places)
+	quotient, remainder := new(big.Int).QuoRem(d.int(), divisor, new(big.Int))
+	if remainder.Abs(remainder).Lsh(remainder, 1).Cmp(divisor) >= 0 {
+		quotient.Add(quotient, big.NewInt(int64(d.Sign())))
+	}
+
+	if places < 0 {
+		return Decimal{coef: quotient.Mul(quotient, pow10(-places))}
+	}
+	return Decimal{coef: quotient, scale: places}
+}
+
+// Float64 is for comparisons that tolerate rounding, such as validation
+// rules; never use it for arithmetic.
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+func (d Decimal) String() string {
+	digits := new(big.Int).Abs(d.int()).String()
+	sign := ""
+	if d.Sign() < 0 {
+		sign = "-"
+	}
+	if d.scale == 0 {
+		return sign + digits
+	}
+
+	if pad := int(d.scale) + 1 - len(digits); pad > 0 {
+		digits = strings.Repeat("0", pad) + digits
+	}
+	point := len(digits) - int(d.scale)
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON accepts a string or, for older clients, a JSON number; the
+// number is parsed from its text, not through float64.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	text := string(data)
+	if text == "null" {
+		return nil
+	}
+	if strings.HasPrefix(text, `"`) {
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+	}
+
+	parsed, err := ParseDecimal(text)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+func (d *Decimal) Scan(src interface{}) error {
+	var text string
+	switch v := src.(type) {
+	case []byte:
+		text = string(v)
+	case string:
+		text = v
+	case int64:
+		*d = NewDecimal(v, 0)
+		return nil
+	case float64:
+		*d = DecimalFromFloat(v)
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into Decimal", src)
+	}
+
+	parsed, err := ParseDecimal(text)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
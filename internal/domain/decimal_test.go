@@ -0,0 +1,164 @@
+package domain
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "12.50", want: "12.50"},
+		{in: "12000000.50", want: "12000000.50"},
+		{in: " 3 ", want: "3"},
+		{in: "-3", want: "-3"},
+		{in: "+3", want: "3"},
+		{in: "-0.05", want: "-0.05"},
+		{in: "0", want: "0"},
+		{in: "0.00", want: "0.00"},
+		{in: ".5", want: "0.5"},
+		{in: "5.", want: "5"},
+		{in: "1.5e3", want: "1500"},
+		{in: "1.5E-3", want: "0.0015"},
+		{in: "-2e2", want: "-200"},
+		{in: "1e64", want: "1" + strings.Repeat("0", 64)},
+		{in: "1e-64", want: "0." + strings.Repeat("0", 63) + "1"},
+		{in: "1e65", wantErr: true},
+		{in: "1e-65", wantErr: true},
+		{in: "1e2000000000", wantErr: true},
+		{in: "1e-2000000000", wantErr: true},
+		{in: "1e9999999999", wantErr: true},
+		{in: "0." + strings.Repeat("0", 65), wantErr: true},
+		{in: "", wantErr: true},
+		{in: "-", wantErr: true},
+		{in: ".", wantErr: true},
+		{in: "1.2.3", wantErr: true},
+		{in: "12abc", wantErr: true},
+		{in: "1e", wantErr: true},
+		{in: "NaN", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseDecimal(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDecimal(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if err == nil && got.String() != tt.want {
+				t.Errorf("ParseDecimal(%q) = %s, want %s", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecimalString(t *testing.T) {
+	tests := []struct {
+		name string
+		d    Decimal
+		want string
+	}{
+		{name: "zero value", d: Decimal{}, want: "0"},
+		{name: "integer", d: NewDecimal(42, 0), want: "42"},
+		{name: "fraction", d: NewDecimal(1250, 2), want: "12.50"},
+		{name: "pads below one", d: NewDecimal(5, 3), want: "0.005"},
+		{name: "pads negative below one", d: NewDecimal(-5, 3), want: "-0.005"},
+		{name: "zero with scale", d: NewDecimal(0, 2), want: "0.00"},
+		{name: "negative scale", d: NewDecimal(15, -2), want: "1500"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.d.String(); got != tt.want {
+				t.Errorf("String() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecimalJSONRoundTrip(t *testing.T) {
+	for _, in := range []string{`"12000000.50"`, `"-0.05"`, `"0"`, `"0.10"`} {
+		var d Decimal
+		if err := json.Unmarshal([]byte(in), &d); err != nil {
+			t.Fatalf("Unmarshal(%s): %v", in, err)
+		}
+		out, err := json.Marshal(d)
+		if err != nil {
+			t.Fatalf("Marshal(%s): %v", in, err)
+		}
+		if string(out) != in {
+			t.Errorf("round trip of %s = %s", in, out)
+		}
+	}
+
+	var d Decimal
+	if err := json.Unmarshal([]byte(`12.5`), &d); err != nil || d.String() != "12.5" {
+		t.Errorf("Unmarshal(12.5) = %s, %v; want 12.5", d, err)
+	}
+	if err := json.Unmarshal([]byte(`"1e2000000000"`), &d); err == nil {
+		t.Error("Unmarshal of an out of range exponent succeeded")
+	}
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	parse := func(s string) Decimal {
+		d, err := ParseDecimal(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+
+	tests := []struct {
+		name string
+		got  Decimal
+		want string
+	}{
+		{name: "add aligns scales", got: parse("1.5").Add(parse("2.25")), want: "3.75"},
+		{name: "add keeps the larger scale", got: parse("1").Add(parse("0.10")), want: "1.10"},
+		{name: "add to zero value", got: Decimal{}.Add(parse("2.50")), want: "2.50"},
+		{name: "sub below zero", got: parse("1.00").Sub(parse("2.5")), want: "-1.50"},
+		{name: "mul adds scales", got: parse("1.25").Mul(parse("0.2")), want: "0.250"},
+		{name: "mul negative", got: parse("-3").Mul(parse("2.50")), want: "-7.50"},
+		{name: "mul by zero value", got: parse("3.5").Mul(Decimal{}), want: "0.0"},
+		{name: "round half up", got: parse("2.345").Round(2), want: "2.35"},
+		{name: "round half away from zero", got: parse("-2.345").Round(2), want: "-2.35"},
+		{name: "round down", got: parse("2.344").Round(2), want: "2.34"},
+		{name: "round to integer", got: parse("12000.5").Round(0), want: "12001"},
+		{name: "round keeps shorter", got: parse("2.5").Round(2), want: "2.5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.got.String(); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecimalCmp(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "1.5", b: "1.50", want: 0},
+		{a: "0", b: "0.00", want: 0},
+		{a: "-1", b: "0", want: -1},
+		{a: "10", b: "9.99", want: 1},
+		{a: "1e3", b: "1000", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" vs "+tt.b, func(t *testing.T) {
+			a, _ := ParseDecimal(tt.a)
+			b, _ := ParseDecimal(tt.b)
+			if got := a.Cmp(b); got != tt.want {
+				t.Errorf("Cmp = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrBadRequest           = errors.New("bad request")
+	ErrUnauthorized         = errors.New("unauthorized")
+	ErrPaymentRequired      = errors.New("payment required")
+	ErrForbidden            = errors.New("forbidden")
+	ErrNotFound             = errors.New("not found")
+	ErrConflict             = errors.New("conflict")
+	ErrPreconditionFailed   = errors.New("precondition failed")
+	ErrPreconditionRequired = errors.New("precondition required")
+	ErrTooManyRequests      = errors.New("too many requests")
+	ErrValidation           = errors.New("validation failed")
+	ErrInternal             = errors.New("internal server error")
+)
+
+var (
+	ErrProductNotFound = NewNotFoundError("product not found")
+	ErrBrandNotFound   = NewNotFoundError("brand not found")
+	ErrBrandInUse      = NewConflictError("cannot delete brand: it is being used by products")
+
+	ErrProductNotDeleted = NewConflictError("product is not deleted")
+	ErrBrandNotDeleted   = NewConflictError("brand is not deleted")
+	ErrBrandDeleted      = NewConflictError("the product's brand is deleted; restore the brand first")
+
+	ErrVersionMismatch = NewPreconditionFailedError("the resource has changed since it was read; fetch it again and retry")
+	ErrIfMatchRequired = NewPreconditionRequiredError("an If-Match header with the resource's ETag is required")
+
+	ErrInsufficientStock = NewConflictError("insufficient stock")
+
+	ErrReservationNotFound  = NewNotFoundError("reservation not found")
+	ErrReservationNotActive = NewConflictError("reservation is no longer active")
+
+	ErrCartNotFound     = NewNotFoundError("cart not found")
+	ErrCartItemNotFound = NewNotFoundError("cart item not found")
+	ErrCartCheckedOut   = NewConflictError("cart has already been checked out")
+	ErrCartEmpty        = NewValidationError("cart is empty")
+	ErrMixedCurrencies  = NewValidationError("all products of a cart or order must be priced in the same currency")
+
+	ErrOrderNotFound = NewNotFoundError("order not found")
+
+	ErrUserNotFound       = NewNotFoundError("user not found")
+	ErrUserExists         = NewConflictError("a user with this email already exists")
+	ErrInvalidCredentials = NewUnauthorizedError("invalid email or password")
+	ErrInvalidToken       = NewUnauthorizedError("invalid or expired token")
+	ErrMissingToken       = NewUnauthorizedError("missing bearer token")
+	ErrPermissionDenied   = NewForbiddenError("you do not have permission to perform this action")
+
+	ErrInvalidAPIKey       = NewUnauthorizedError("invalid, expired or revoked api key")
+	ErrAPIKeyIPDenied      = NewForbiddenError("api key is not allowed from this address")
+	ErrAPIKeyNotFound      = NewNotFoundError("api key not found")
+	ErrAPIKeyRevoked       = NewConflictError("api key has been revoked")
+	ErrScopeNotGranted     = NewForbiddenError("cannot grant scopes you do not hold")
+	ErrAPIKeyScopesNotHeld = NewForbiddenError("cannot manage an api key with scopes you do not hold")
+
+	ErrRateLimited = NewTooManyRequestsError("rate limit exceeded, try again later")
+
+	ErrRoleNotFound   = NewNotFoundError("role not found")
+	ErrRoleExists     = NewConflictError("a role with this name already exists")
+	ErrAdminRoleFixed = NewConflictError("the admin role cannot be changed or deleted")
+
+	ErrPaymentNotFound         = NewNotFoundError("payment not found")
+	ErrPaymentDeclined         = NewPaymentRequiredError("payment declined")
+	ErrPaymentInProgress       = NewConflictError("the order already has a payment in progress or captured")
+	ErrInvalidWebhookSignature = NewUnauthorizedError("invalid webhook signature")
+
+	ErrVariantNotFound = NewNotFoundError("variant not found")
+	ErrSKUExists       = NewConflictError("sku already exists")
+
+	ErrProductPriceNotFound = NewNotFoundError("price not found")
+	ErrNoPriceAtTime        = NewNotFoundError("the product had no price at that time")
+	ErrPriceNotInFuture     = NewValidationError("effective_from must be in the future")
+	ErrPriceAlreadyApplied  = NewConflictError("the price has already taken effect and is part of the history")
+
+	ErrExchangeRateNotFound  = NewNotFoundError("exchange rate not found")
+	ErrCurrencyPriceNotFound = NewNotFoundError("price override not found")
+	ErrSameCurrency          = NewValidationError("an exchange rate needs two different currencies")
+
+	ErrCategoryNotFound    = NewNotFoundError("category not found")
+	ErrCategoryInUse       = NewConflictError("cannot delete category: it is assigned to products")
+	ErrCategoryHasChildren = NewConflictError("cannot delete category: it has subcategories")
+	ErrCategoryCycle       = NewValidationError("a category cannot be moved under itself or one of its descendants")
+)
+
+// Error is a domain error carrying a client-facing message. Kind is one of
+// the sentinel errors above and decides the HTTP status it is mapped to.
+type Error struct {
+	Kind    error
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+func NewBadRequestError(format string, args ...interface{}) error {
+	return &Error{Kind: ErrBadRequest, Message: fmt.Sprintf(format, args...)}
+}
+
+func NewUnauthorizedError(format string, args ...interface{}) error {
+	return &Error{Kind: ErrUnauthorized, Message: fmt.Sprintf(format, args...)}
+}
+
+func NewPaymentRequiredError(format string, args ...interface{}) error {
+	return &Error{Kind: ErrPaymentRequired, Message: fmt.Sprintf(format, args...)}
+}
+
+func NewForbiddenError(format string, args ...interface{}) error {
+	return &Error{Kind: ErrForbidden, Message: fmt.Sprintf(format, args...)}
+}
+
+func NewNotFoundError(format string, args ...interface{}) error {
+	return &Error{Kind: ErrNotFound, Message: fmt.Sprintf(format, args...)}
+}
+
+func NewConflictError(format string, args ...interface{}) error {
+	return &Error{Kind: ErrConflict, Message: fmt.Sprintf(format, args...)}
+}
+
+func NewPreconditionFailedError(format string, args ...interface{}) error {
+	return &Error{Kind: ErrPreconditionFailed, Message: fmt.Sprintf(format, args...)}
+}
+
+func NewPreconditionRequiredError(format string, args ...interface{}) error {
+	return &Error{Kind: ErrPreconditionRequired, Message: fmt.Sprintf(format, args...)}
+}
+
+func NewTooManyRequestsError(format string, args ...interface{}) error {
+	return &Error{Kind: ErrTooManyRequests, Message: fmt.Sprintf(format, args...)}
+}
+
+func NewValidationError(format string, args ...interface{}) error {
+	return &Error{Kind: ErrValidation, Message: fmt.Sprintf(format, args...)}
+}
+
+// FieldError describes a single request field that failed validation.
+type FieldError struct {
+	Field   string `json:"field" example:"price"`
+	Rule    string `json:"rule" example:"gt"`
+	Param   string `json:"param,omitempty" example:"0"`
+	Message string `json:"message" example:"price must be greater than 0"`
+}
+
+// ValidationErrors is returned when a request fails struct validation.
+type ValidationErrors []FieldError
+
+func (v ValidationErrors) Error() string {
+	messages := make([]string, 0, len(v))
+	for _, fe := range v {
+		messages = append(messages, fe.Message)
+	}
+	return "validation failed: " + strings.Join(messages, ", ")
+}
+
+func (v ValidationErrors) Unwrap() error {
+	return ErrValidation
+}
@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type MovementType string
+
+const (
+	MovementReceipt    MovementType = "receipt"
+	MovementSale       MovementType = "sale"
+	MovementAdjustment MovementType = "adjustment"
+	MovementReturn     MovementType = "return"
+)
+
+// SystemActor is recorded on movements that were not made by a named actor.
+const SystemActor = "system"
+
+// InventoryMovement is one entry of the stock ledger. Quantity is signed:
+// positive movements add stock and negative ones remove it.
+type InventoryMovement struct {
+	ID           uuid.UUID    `json:"id" db:"id"`
+	ProductID    uuid.UUID    `json:"product_id" db:"product_id"`
+	MovementType MovementType `json:"movement_type" db:"movement_type"`
+	Quantity     float64      `json:"quantity" db:"quantity"`
+	QtyAfter     float64      `json:"qty_after" db:"qty_after"`
+	Reason       string       `json:"reason" db:"reason"`
+	Actor        string       `json:"actor" db:"actor"`
+	CreatedAt    time.Time    `json:"created_at" db:"created_at"`
+}
+
+// StockAdjustmentRequest records a stock movement. Quantity is the number of
+// units received, sold or returned; for adjustments it is a signed delta.
+type StockAdjustmentRequest struct {
+	MovementType MovementType `json:"movement_type" validate:"required,oneof=receipt sale adjustment return"`
+	Quantity     float64      `json:"quantity" validate:"required"`
+	Reason       string       `json:"reason" validate:"max=500"`
+}
+
+// Delta is the signed change the request applies to the product's stock.
+func (r *StockAdjustmentRequest) Delta() (float64, error) {
+	switch r.MovementType {
+	case MovementReceipt, MovementReturn:
+		if r.Quantity < 0 {
+			return 0, NewValidationError("quantity must be positive for %s", r.MovementType)
+		}
+		return r.Quantity, nil
+	case MovementSale:
+		if r.Quantity < 0 {
+			return 0, NewValidationError("quantity must be positive for %s", r.MovementType)
+		}
+		return -r.Quantity, nil
+	}
+	return r.Quantity, nil
+}
+
+type StockHistoryResponse struct {
+	Movements  []InventoryMovement `json:"movements"`
+	Total      int                 `json:"total"`
+	Page       int                 `json:"page"`
+	Limit      int                 `json:"limit"`
+	TotalPages int                 `json:"total_pages"`
+}
+
+// StockLevel is a product's stock and how much of it active reservations hold.
+type StockLevel struct {
+	Qty      float64 `db:"qty"`
+	Reserved float64 `db:"reserved"`
+}
+
+// Available is the stock that can still be reserved or sold.
+func (l *StockLevel) Available() float64 {
+	return l.Qty - l.Reserved
+}
+
+// StockDiscrepancy is a product whose qty does not match its ledger.
+type StockDiscrepancy struct {
+	ProductID   uuid.UUID `json:"product_id" db:"product_id"`
+	ProductName string    `json:"product_name" db:"product_name"`
+	Qty         float64   `json:"qty" db:"qty"`
+	LedgerQty   float64   `json:"ledger_qty" db:"ledger_qty"`
+}
@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type OrderStatus string
+
+const (
+	OrderPending   OrderStatus = "pending"
+	OrderPaid      OrderStatus = "paid"
+	OrderShipped   OrderStatus = "shipped"
+	OrderDelivered OrderStatus = "delivered"
+	OrderCancelled OrderStatus = "cancelled"
+	OrderRefunded  OrderStatus = "refunded"
+)
+
+// orderTransitions lists, per status, the statuses an order may move to.
+// Cancelled and refunded are final.
+var orderTransitions = map[OrderStatus][]OrderStatus{
+	OrderPending:   {OrderPaid, OrderCancelled},
+	OrderPaid:      {OrderShipped, OrderCancelled, OrderRefunded},
+	OrderShipped:   {OrderDelivered},
+	OrderDelivered: {OrderRefunded},
+}
+
+// CanTransitionTo reports whether an order in status s may move to next.
+func (s OrderStatus) CanTransitionTo(next OrderStatus) bool {
+	for _, allowed := range orderTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// RestocksOn reports whether moving from s to next puts the order's items
+// back in stock: they have not left the warehouse yet.
+func (s OrderStatus) RestocksOn(next OrderStatus) bool {
+	return next == OrderCancelled || (s == OrderPaid && next == OrderRefunded)
+}
+
+type Order struct {
+	ID        uuid.UUID   `json:"id" db:"id"`
+	CartID    *uuid.UUID  `json:"cart_id,omitempty" db:"cart_id"`
+	HolderID  *uuid.UUID  `json:"-" db:"holder_id"`
+	Status    OrderStatus `json:"status" db:"status"`
+	Total     Decimal     `json:"total" db:"total" swaggertype:"string" example:"24000001.00"`
+	Currency  string      `json:"currency" db:"currency" example:"IDR"`
+	Items     []OrderItem `json:"items,omitempty" db:"-"`
+	CreatedAt time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at" db:"updated_at"`
+}
+
+// HeldBy reports whether user placed the order.
+func (o *Order) HeldBy(user *AuthUser) bool {
+	return user != nil && o.HolderID != nil && *o.HolderID == user.ID
+}
+
+// OrderItem snapshots the product as it was at checkout. ProductID becomes
+// nil if the product is deleted later. Every item of an order is in the
+// order's currency.
+type OrderItem struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	OrderID     uuid.UUID  `json:"order_id" db:"order_id"`
+	ProductID   *uuid.UUID `json:"product_id" db:"product_id"`
+	ProductName string     `json:"product_name" db:"product_name"`
+	UnitPrice   Decimal    `json:"unit_price" db:"unit_price" swaggertype:"string" example:"12000000.50"`
+	Currency    string     `json:"currency" db:"currency" example:"IDR"`
+	Qty         float64    `json:"qty" db:"qty"`
+	LineTotal   Decimal    `json:"line_total" db:"line_total" swaggertype:"string" example:"24000001.00"`
+}
+
+// CheckoutRequest places an order either for the contents of a cart or for
+// an explicit list of lines; exactly one of CartID and Items must be given.
+type CheckoutRequest struct {
+	CartID *uuid.UUID     `json:"cart_id"`
+	Items  []CheckoutItem `json:"items" validate:"omitempty,dive"`
+}
+
+type CheckoutItem struct {
+	ProductID uuid.UUID `json:"product_id" validate:"required"`
+	Qty       float64   `json:"qty" validate:"required,gt=0"`
+}
+
+type UpdateOrderStatusRequest struct {
+	Status OrderStatus `json:"status" validate:"required,oneof=pending paid shipped delivered cancelled refunded"`
+}
+
+type OrderListResponse struct {
+	Orders     []Order `json:"orders"`
+	Total      int     `json:"total"`
+	Page       int     `json:"page"`
+	Limit      int     `json:"limit"`
+	TotalPages int     `json:"total_pages"`
+}
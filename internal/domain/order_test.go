@@ -0,0 +1,57 @@
+package domain
+
+import "testing"
+
+func TestOrderStatusCanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from, to OrderStatus
+		want     bool
+	}{
+		{from: OrderPending, to: OrderPaid, want: true},
+		{from: OrderPending, to: OrderCancelled, want: true},
+		{from: OrderPending, to: OrderShipped, want: false},
+		{from: OrderPending, to: OrderRefunded, want: false},
+		{from: OrderPaid, to: OrderShipped, want: true},
+		{from: OrderPaid, to: OrderCancelled, want: true},
+		{from: OrderPaid, to: OrderRefunded, want: true},
+		{from: OrderPaid, to: OrderPending, want: false},
+		{from: OrderShipped, to: OrderDelivered, want: true},
+		{from: OrderShipped, to: OrderCancelled, want: false},
+		{from: OrderDelivered, to: OrderRefunded, want: true},
+		{from: OrderDelivered, to: OrderShipped, want: false},
+		{from: OrderCancelled, to: OrderPending, want: false},
+		{from: OrderRefunded, to: OrderPaid, want: false},
+		{from: OrderPaid, to: OrderPaid, want: false},
+		{from: OrderStatus("unknown"), to: OrderPaid, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			if got := tt.from.CanTransitionTo(tt.to); got != tt.want {
+				t.Errorf("%s.CanTransitionTo(%s) = %v, want %v", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderStatusRestocksOn(t *testing.T) {
+	tests := []struct {
+		from, to OrderStatus
+		want     bool
+	}{
+		{from: OrderPending, to: OrderCancelled, want: true},
+		{from: OrderPaid, to: OrderCancelled, want: true},
+		{from: OrderPaid, to: OrderRefunded, want: true},
+		{from: OrderDelivered, to: OrderRefunded, want: false},
+		{from: OrderPending, to: OrderPaid, want: false},
+		{from: OrderShipped, to: OrderDelivered, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			if got := tt.from.RestocksOn(tt.to); got != tt.want {
+				t.Errorf("%s.RestocksOn(%s) = %v, want %v", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PaymentStatus string
+
+const (
+	// PaymentProcessing is a payment whose authorization is under way; it
+	// has no ProviderRef yet.
+	PaymentProcessing PaymentStatus = "processing"
+	PaymentAuthorized PaymentStatus = "authorized"
+	PaymentCaptured   PaymentStatus = "captured"
+	PaymentFailed     PaymentStatus = "failed"
+	PaymentRefunded   PaymentStatus = "refunded"
+)
+
+// Payment is a charge for an order at a payment provider. ProviderRef is the
+// provider's identifier for it and ties webhook events back to the payment.
+type Payment struct {
+	ID          uuid.UUID     `json:"id" db:"id"`
+	OrderID     uuid.UUID     `json:"order_id" db:"order_id"`
+	Provider    string        `json:"provider" db:"provider"`
+	ProviderRef string        `json:"provider_ref,omitempty" db:"provider_ref"`
+	Amount      Decimal       `json:"amount" db:"amount" swaggertype:"string" example:"24000001.00"`
+	Status      PaymentStatus `json:"status" db:"status"`
+	CreatedAt   time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at" db:"updated_at"`
+}
+
+// PayOrderRequest pays a pending order. PaymentMethod is the provider's token
+// for the customer's payment details.
+type PayOrderRequest struct {
+	PaymentMethod string `json:"payment_method" validate:"required"`
+}
+
+type PaymentEventType string
+
+const (
+	PaymentEventCaptured PaymentEventType = "payment.captured"
+	PaymentEventFailed   PaymentEventType = "payment.failed"
+	PaymentEventRefunded PaymentEventType = "payment.refunded"
+)
+
+// PaymentEvent is the body of a provider webhook. ID is unique per event and
+// makes redelivered events harmless.
+type PaymentEvent struct {
+	ID          string           `json:"id" validate:"required"`
+	Type        PaymentEventType `json:"type" validate:"required,oneof=payment.captured payment.failed payment.refunded"`
+	ProviderRef string           `json:"provider_ref" validate:"required"`
+}
+
+// PaymentStatus is the status a payment ends up in after the event.
+func (e *PaymentEvent) PaymentStatus() PaymentStatus {
+	switch e.Type {
+	case PaymentEventCaptured:
+		return PaymentCaptured
+	case PaymentEventRefunded:
+		return PaymentRefunded
+	}
+	return PaymentFailed
+}
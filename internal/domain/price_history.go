@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProductPrice is one entry of a product's price history: the price in
+// effect from EffectiveFrom until EffectiveTo, or until further notice when
+// EffectiveTo is nil. Entries of a product never overlap. Scheduled entries
+// are always in the product's current currency; changing it cancels them.
+type ProductPrice struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	ProductID     uuid.UUID  `json:"product_id" db:"product_id"`
+	Price         Decimal    `json:"price" db:"price" swaggertype:"string" example:"11500000"`
+	Currency      string     `json:"currency" db:"currency" example:"IDR"`
+	EffectiveFrom time.Time  `json:"effective_from" db:"effective_from"`
+	EffectiveTo   *time.Time `json:"effective_to,omitempty" db:"effective_to"`
+
+	// AppliedAt is when the price was written to the product. It is nil for
+	// scheduled prices that have not taken effect yet.
+	AppliedAt *time.Time `json:"applied_at,omitempty" db:"applied_at"`
+
+	Actor     string    `json:"actor" db:"actor" example:"admin@example.com"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// SchedulePriceRequest sets a product's price from a future point in time.
+// The price is in the product's currency; Price is a pointer so that 0 is a
+// price a client can schedule.
+type SchedulePriceRequest struct {
+	Price         *Decimal  `json:"price" validate:"required,gte=0" swaggertype:"string" example:"11500000"`
+	EffectiveFrom time.Time `json:"effective_from" validate:"required" example:"2026-12-01T00:00:00+07:00"`
+}
+
+type PriceHistoryResponse struct {
+	Prices     []ProductPrice `json:"prices"`
+	Total      int            `json:"total"`
+	Page       int            `json:"page"`
+	Limit      int            `json:"limit"`
+	TotalPages int            `json:"total_pages"`
+}
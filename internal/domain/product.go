@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,32 +10,158 @@ import (
 type Product struct {
 	ID          uuid.UUID `json:"id" db:"id"`
 	ProductName string    `json:"product_name" db:"product_name"`
-	Price       float64   `json:"price" db:"price"`
+	Price       Decimal   `json:"price" db:"price" swaggertype:"string" example:"12000000.50"`
+	Currency    string    `json:"currency" db:"currency" example:"IDR"`
 	Qty         float64   `json:"qty" db:"qty"`
 	BrandID     uuid.UUID `json:"brand_id" db:"brand_id"`
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
 	BrandName   string    `json:"brand_name,omitempty" db:"brand_name"`
+
+	// Version increases with every change and is sent as the ETag.
+	Version int `json:"version" db:"version"`
+
+	// DeletedAt is set once the product is soft deleted.
+	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+
+	// AvailableQty is Qty minus the units held by active reservations.
+	AvailableQty float64 `json:"available_qty" db:"available_qty"`
+
+	Categories []CategoryPath   `json:"categories,omitempty" db:"-"`
+	Variants   []ProductVariant `json:"variants,omitempty" db:"-"`
+	PriceRange *PriceRange      `json:"price_range,omitempty" db:"-"`
+
+	// BasePrice and BaseCurrency keep the stored price when Price and
+	// Currency were converted for the currency a client asked for.
+	BasePrice    *Decimal `json:"base_price,omitempty" db:"-" swaggertype:"string" example:"12000000.50"`
+	BaseCurrency string   `json:"base_currency,omitempty" db:"-" example:"IDR"`
 }
 
 type CreateProductRequest struct {
 	ProductName string    `json:"product_name" validate:"required"`
-	Price       float64   `json:"price" validate:"required,gt=0"`
-	Qty         float64   `json:"qty" validate:"required,gte=0"`
+	Price       Decimal   `json:"price" validate:"required,gt=0" swaggertype:"string" example:"12000000.50"`
+	Currency    string    `json:"currency,omitempty" example:"IDR"` // defaults to IDR
+	Qty         float64   `json:"qty" validate:"gte=0"`
 	BrandID     uuid.UUID `json:"brand_id" validate:"required"`
 }
 
+// ReplaceProductRequest is the body of PUT /products/{id}; every field is
+// required and replaces the stored value.
+type ReplaceProductRequest struct {
+	ProductName string    `json:"product_name" validate:"required"`
+	Price       *Decimal  `json:"price" validate:"required,gte=0" swaggertype:"string" example:"12000000.50"`
+	Currency    string    `json:"currency,omitempty" example:"IDR"` // keeps the current currency when empty
+	Qty         *float64  `json:"qty" validate:"required,gte=0"`
+	BrandID     uuid.UUID `json:"brand_id" validate:"required"`
+}
+
+// UpdateProductRequest is a JSON merge patch (RFC 7396) for a product. Fields
+// left out of the document are nil and keep their current value, so zero is a
+// value a client can actually set.
 type UpdateProductRequest struct {
-	ProductName string    `json:"product_name,omitempty"`
-	Price       float64   `json:"price,omitempty"`
-	Qty         float64   `json:"qty,omitempty"`
-	BrandID     uuid.UUID `json:"brand_id,omitempty"`
+	ProductName *string    `json:"product_name,omitempty" validate:"omitempty,min=1"`
+	Price       *Decimal   `json:"price,omitempty" validate:"omitempty,gte=0" swaggertype:"string" example:"12000000.50"`
+	Currency    *string    `json:"currency,omitempty" example:"IDR"`
+	Qty         *float64   `json:"qty,omitempty" validate:"omitempty,gte=0"`
+	BrandID     *uuid.UUID `json:"brand_id,omitempty"`
+}
+
+// ChangesNothing reports whether applying the patch would leave product as
+// it is, in which case nothing is written.
+func (r *UpdateProductRequest) ChangesNothing(product *Product) bool {
+	return (r.ProductName == nil || *r.ProductName == product.ProductName) &&
+		(r.Price == nil || r.Price.Cmp(product.Price) == 0) &&
+		(r.Currency == nil || *r.Currency == product.Currency) &&
+		(r.Qty == nil || *r.Qty == product.Qty) &&
+		(r.BrandID == nil || *r.BrandID == product.BrandID)
 }
 
+// ProductListResponse is shared by offset and cursor pagination. Offset mode
+// fills Page and TotalPages; cursor mode fills NextCursor and PrevCursor and
+// only reports Total when it was asked for.
 type ProductListResponse struct {
 	Products   []Product `json:"products"`
-	Total      int       `json:"total"`
-	Page       int       `json:"page"`
+	Total      *int      `json:"total,omitempty"`
+	Page       int       `json:"page,omitempty"`
 	Limit      int       `json:"limit"`
-	TotalPages int       `json:"total_pages"`
+	TotalPages int       `json:"total_pages,omitempty"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+	PrevCursor string    `json:"prev_cursor,omitempty"`
+}
+
+// ProductSearchResult is a product matched by full-text search. Snippet is
+// the matched text with hits wrapped in <mark> tags.
+type ProductSearchResult struct {
+	Product
+	Rank    float64 `json:"rank" db:"rank"`
+	Snippet string  `json:"snippet" db:"snippet"`
+}
+
+type ProductSearchResponse struct {
+	Products   []ProductSearchResult `json:"products"`
+	Total      int                   `json:"total"`
+	Page       int                   `json:"page"`
+	Limit      int                   `json:"limit"`
+	TotalPages int                   `json:"total_pages"`
+}
+
+// ProductSortFields whitelists the fields accepted by the sort query parameter.
+var ProductSortFields = map[string]bool{
+	"product_name": true,
+	"price":        true,
+	"qty":          true,
+	"created_at":   true,
+	"updated_at":   true,
+}
+
+type ProductSort struct {
+	Field string
+	Desc  bool
+}
+
+// ProductFilter narrows down a product listing. Nil or empty fields are not applied.
+type ProductFilter struct {
+	BrandIDs      []uuid.UUID
+	CategoryID    *uuid.UUID // includes products in descendant categories
+	PriceMin      *Decimal
+	PriceMax      *Decimal
+	InStock       *bool
+	Name          string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	Sort          []ProductSort
+
+	// IncludeVariants embeds variants and a price range in each listed
+	// product; it does not narrow the result.
+	IncludeVariants bool
+
+	// IncludeDeleted lists soft-deleted products alongside the others.
+	IncludeDeleted bool
+}
+
+// ParseProductSort parses a comma separated sort expression such as
+// "price,-created_at"; a leading "-" sorts descending.
+func ParseProductSort(expr string) ([]ProductSort, error) {
+	var sorts []ProductSort
+	seen := map[string]bool{}
+	for _, part := range strings.Split(expr, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		sort := ProductSort{Field: part}
+		if strings.HasPrefix(part, "-") {
+			sort = ProductSort{Field: part[1:], Desc: true}
+		}
+		if !ProductSortFields[sort.Field] {
+			return nil, NewBadRequestError("cannot sort by %q", sort.Field)
+		}
+		if seen[sort.Field] {
+			return nil, NewBadRequestError("sort field %q given more than once", sort.Field)
+		}
+		seen[sort.Field] = true
+		sorts = append(sorts, sort)
+	}
+	return sorts, nil
 }
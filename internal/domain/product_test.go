@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseProductSort(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []ProductSort
+		wantErr bool
+	}{
+		{in: "", want: nil},
+		{in: " , ", want: nil},
+		{in: "price", want: []ProductSort{{Field: "price"}}},
+		{in: "-created_at", want: []ProductSort{{Field: "created_at", Desc: true}}},
+		{
+			in:   "price, -created_at,product_name",
+			want: []ProductSort{{Field: "price"}, {Field: "created_at", Desc: true}, {Field: "product_name"}},
+		},
+		{in: "brand_id", wantErr: true},
+		{in: "-", wantErr: true},
+		{in: "--price", wantErr: true},
+		{in: "+price", wantErr: true},
+		{in: "Price", wantErr: true},
+		{in: "price,-price", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseProductSort(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseProductSort(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrBadRequest) {
+				t.Errorf("ParseProductSort(%q) error = %v, want a bad request", tt.in, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseProductSort(%q) = %+v, want %+v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
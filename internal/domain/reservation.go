@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ReservationStatus string
+
+const (
+	ReservationActive    ReservationStatus = "active"
+	ReservationConfirmed ReservationStatus = "confirmed"
+	ReservationReleased  ReservationStatus = "released"
+	ReservationExpired   ReservationStatus = "expired"
+)
+
+// DefaultReservationTTL applies when a reservation request gives no TTL.
+const DefaultReservationTTL = 15 * time.Minute
+
+// StockReservation holds Quantity units of a product for Holder until
+// ExpiresAt. Held units are not available to anyone else; confirming the
+// reservation turns them into a sale.
+type StockReservation struct {
+	ID        uuid.UUID         `json:"id" db:"id"`
+	ProductID uuid.UUID         `json:"product_id" db:"product_id"`
+	Holder    string            `json:"holder" db:"holder"`
+	Quantity  float64           `json:"quantity" db:"quantity"`
+	Status    ReservationStatus `json:"status" db:"status"`
+	ExpiresAt time.Time         `json:"expires_at" db:"expires_at"`
+	CreatedAt time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+type CreateReservationRequest struct {
+	ProductID  uuid.UUID `json:"product_id" validate:"required"`
+	Holder     string    `json:"holder" validate:"required,max=200"`
+	Quantity   float64   `json:"quantity" validate:"required,gt=0"`
+	TTLSeconds int       `json:"ttl_seconds" validate:"omitempty,min=1,max=86400"`
+}
+
+// TTL is the requested lifetime of the reservation.
+func (r *CreateReservationRequest) TTL() time.Duration {
+	if r.TTLSeconds == 0 {
+		return DefaultReservationTTL
+	}
+	return time.Duration(r.TTLSeconds) * time.Second
+}
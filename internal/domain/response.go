@@ -10,6 +10,11 @@ type ProductListResponseWrapper struct {
 	Data    *ProductListResponse `json:"data"`
 }
 
+type ProductSearchResponseWrapper struct {
+	Message string                 `json:"message" example:"Products found successfully"`
+	Data    *ProductSearchResponse `json:"data"`
+}
+
 type BrandResponse struct {
 	Message string `json:"message" example:"Brand created successfully"`
 	Data    *Brand `json:"data"`
@@ -27,3 +32,148 @@ type MessageResponse struct {
 type ErrorResponse struct {
 	Error string `json:"error" example:"Something went wrong"`
 }
+
+type ValidationErrorResponse struct {
+	Error  string       `json:"error" example:"Validation failed"`
+	Fields []FieldError `json:"fields"`
+}
+
+type CategoryResponse struct {
+	Message string    `json:"message" example:"Category created successfully"`
+	Data    *Category `json:"data"`
+}
+
+type CategoryListResponse struct {
+	Message string     `json:"message" example:"Categories retrieved successfully"`
+	Data    []Category `json:"data"`
+}
+
+type VariantResponse struct {
+	Message string          `json:"message" example:"Variant created successfully"`
+	Data    *ProductVariant `json:"data"`
+}
+
+type VariantListResponse struct {
+	Message string           `json:"message" example:"Variants retrieved successfully"`
+	Data    []ProductVariant `json:"data"`
+}
+
+type StockMovementResponse struct {
+	Message string             `json:"message" example:"Stock adjusted successfully"`
+	Data    *InventoryMovement `json:"data"`
+}
+
+type StockHistoryResponseWrapper struct {
+	Message string                `json:"message" example:"Stock history retrieved successfully"`
+	Data    *StockHistoryResponse `json:"data"`
+}
+
+type StockReconciliationResponse struct {
+	Message string             `json:"message" example:"Stock reconciliation completed"`
+	Data    []StockDiscrepancy `json:"data"`
+}
+
+type ReservationResponse struct {
+	Message string            `json:"message" example:"Stock reserved successfully"`
+	Data    *StockReservation `json:"data"`
+}
+
+type CartResponse struct {
+	Message string `json:"message" example:"Cart retrieved successfully"`
+	Data    *Cart  `json:"data"`
+}
+
+type OrderResponse struct {
+	Message string `json:"message" example:"Order retrieved successfully"`
+	Data    *Order `json:"data"`
+}
+
+type OrderListResponseWrapper struct {
+	Message string             `json:"message" example:"Orders retrieved successfully"`
+	Data    *OrderListResponse `json:"data"`
+}
+
+type PaymentResponse struct {
+	Message string   `json:"message" example:"Payment processed successfully"`
+	Data    *Payment `json:"data"`
+}
+
+type PaymentListResponse struct {
+	Message string    `json:"message" example:"Payments retrieved successfully"`
+	Data    []Payment `json:"data"`
+}
+
+type TokenResponse struct {
+	Message string     `json:"message" example:"Logged in successfully"`
+	Data    *TokenPair `json:"data"`
+}
+
+type RoleResponse struct {
+	Message string `json:"message" example:"Role retrieved successfully"`
+	Data    *Role  `json:"data"`
+}
+
+type RoleListResponse struct {
+	Message string `json:"message" example:"Roles retrieved successfully"`
+	Data    []Role `json:"data"`
+}
+
+type PermissionListResponse struct {
+	Message string   `json:"message" example:"Permissions retrieved successfully"`
+	Data    []string `json:"data"`
+}
+
+type APIKeyResponse struct {
+	Message string  `json:"message" example:"API key retrieved successfully"`
+	Data    *APIKey `json:"data"`
+}
+
+type APIKeyListResponse struct {
+	Message string   `json:"message" example:"API keys retrieved successfully"`
+	Data    []APIKey `json:"data"`
+}
+
+type IssuedAPIKeyResponse struct {
+	Message string        `json:"message" example:"API key created successfully"`
+	Data    *IssuedAPIKey `json:"data"`
+}
+
+type AuditListResponseWrapper struct {
+	Message string             `json:"message" example:"Audit log retrieved successfully"`
+	Data    *AuditListResponse `json:"data"`
+}
+
+type ExchangeRateResponse struct {
+	Message string        `json:"message" example:"Exchange rate set successfully"`
+	Data    *ExchangeRate `json:"data"`
+}
+
+type ExchangeRateListResponse struct {
+	Message string         `json:"message" example:"Exchange rates retrieved successfully"`
+	Data    []ExchangeRate `json:"data"`
+}
+
+type ImportExchangeRatesResponseWrapper struct {
+	Message string                       `json:"message" example:"Exchange rates imported successfully"`
+	Data    *ImportExchangeRatesResponse `json:"data"`
+}
+
+type ProductCurrencyPriceResponse struct {
+	Message string                `json:"message" example:"Price override set successfully"`
+	Data    *ProductCurrencyPrice `json:"data"`
+}
+
+type ProductCurrencyPriceListResponse struct {
+	Message string                 `json:"message" example:"Price overrides retrieved successfully"`
+	Data    []ProductCurrencyPrice `json:"data"`
+}
+
+type ProductPriceResponse struct {
+	Message string        `json:"message" example:"Price scheduled successfully"`
+	Data    *ProductPrice `json:"data"`
+}
+
+type PriceHistoryResponseWrapper struct {
+	Message string                `json:"message" example:"Price history retrieved successfully"`
+	Data    *PriceHistoryResponse `json:"data"`
+}
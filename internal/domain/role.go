@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Permissions checked by the access guard. Catalog reads are public and need
+// none.
+const (
+	PermProductsWrite       = "products:write"
+	PermProductsDelete      = "products:delete"
+	PermBrandsWrite         = "brands:write"
+	PermBrandsDelete        = "brands:delete"
+	PermCategoriesWrite     = "categories:write"
+	PermCategoriesDelete    = "categories:delete"
+	PermStockRead           = "stock:read"
+	PermStockAdjust         = "stock:adjust"
+	PermReservationsWrite   = "reservations:write"
+	PermOrdersRead          = "orders:read"
+	PermOrdersWrite         = "orders:write"
+	PermPaymentsRefund      = "payments:refund"
+	PermRolesManage         = "roles:manage"
+	PermAPIKeysManage       = "api_keys:manage"
+	PermAuditRead           = "audit:read"
+	PermExchangeRatesManage = "exchange_rates:manage"
+)
+
+// AllPermissions lists every permission a role can be granted.
+var AllPermissions = []string{
+	PermProductsWrite,
+	PermProductsDelete,
+	PermBrandsWrite,
+	PermBrandsDelete,
+	PermCategoriesWrite,
+	PermCategoriesDelete,
+	PermStockRead,
+	PermStockAdjust,
+	PermReservationsWrite,
+	PermOrdersRead,
+	PermOrdersWrite,
+	PermPaymentsRefund,
+	PermRolesManage,
+	PermAPIKeysManage,
+	PermAuditRead,
+	PermExchangeRatesManage,
+}
+
+// AdminRole is the built-in role holding every permission. It cannot be
+// changed or deleted, so there is always a way back into the admin API.
+const AdminRole = "admin"
+
+type Role struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	Name        string    `json:"name" db:"name"`
+	Description string    `json:"description" db:"description"`
+	Permissions []string  `json:"permissions" db:"-"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type CreateRoleRequest struct {
+	Name        string   `json:"name" validate:"required,max=64"`
+	Description string   `json:"description" validate:"max=500"`
+	Permissions []string `json:"permissions" validate:"required"`
+}
+
+// ReplaceRoleRequest is the body of PUT /admin/roles/{id}; the name of a
+// role is fixed once created.
+type ReplaceRoleRequest struct {
+	Description string   `json:"description" validate:"max=500"`
+	Permissions []string `json:"permissions" validate:"required"`
+}
+
+// SetUserRolesRequest replaces all role assignments of a user; an empty list
+// removes them.
+type SetUserRolesRequest struct {
+	RoleIDs []uuid.UUID `json:"role_ids" validate:"required"`
+}
+
+// ValidatePermissions rejects permissions that are not in AllPermissions.
+func ValidatePermissions(permissions []string) error {
+	known := map[string]bool{}
+	for _, permission := range AllPermissions {
+		known[permission] = true
+	}
+	for _, permission := range permissions {
+		if !known[permission] {
+			return NewValidationError("unknown permission %q", permission)
+		}
+	}
+	return nil
+}
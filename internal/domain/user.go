@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type User struct {
+	ID           uuid.UUID `json:"id" db:"id"`
+	Email        string    `json:"email" db:"email"`
+	PasswordHash string    `json:"-" db:"password_hash"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// RefreshToken is a stored refresh token. Only the hash of the token is kept.
+type RefreshToken struct {
+	ID        uuid.UUID  `db:"id"`
+	UserID    uuid.UUID  `db:"user_id"`
+	FamilyID  uuid.UUID  `db:"family_id"`
+	TokenHash string     `db:"token_hash"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	RevokedAt *time.Time `db:"revoked_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
+
+type LoginRequest struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// TokenPair is returned by login and refresh. ExpiresIn is the lifetime of
+// the access token in seconds.
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type" example:"Bearer"`
+	ExpiresIn    int    `json:"expires_in" example:"900"`
+}
+
+// AuthUser is the authenticated caller of a request. APIKey is set when the
+// caller presented an API key instead of an access token; ID is then the
+// key's ID and its scopes take the place of role permissions.
+type AuthUser struct {
+	ID     uuid.UUID
+	Email  string
+	APIKey *APIKey
+}
+
+type authUserKey struct{}
+
+func WithAuthUser(ctx context.Context, user *AuthUser) context.Context {
+	return context.WithValue(ctx, authUserKey{}, user)
+}
+
+// AuthUserFromContext returns the authenticated caller, or nil for
+// anonymous requests and background work.
+func AuthUserFromContext(ctx context.Context) *AuthUser {
+	user, _ := ctx.Value(authUserKey{}).(*AuthUser)
+	return user
+}
+
+// ActorFromContext names who is acting in ctx for audit trails: the
+// authenticated user's email, "api-key:<prefix>" for API keys, or
+// SystemActor.
+func ActorFromContext(ctx context.Context) string {
+	user := AuthUserFromContext(ctx)
+	switch {
+	case user == nil:
+		return SystemActor
+	case user.APIKey != nil:
+		return "api-key:" + user.APIKey.Prefix
+	default:
+		return user.Email
+	}
+}
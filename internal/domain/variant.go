@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// VariantOptions holds the option values that tell variants apart, for
+// example {"color": "black", "storage": "256GB"}. It is stored as JSONB.
+type VariantOptions map[string]string
+
+func (o VariantOptions) Value() (driver.Value, error) {
+	if o == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(o)
+}
+
+func (o *VariantOptions) Scan(src interface{}) error {
+	var raw []byte
+	switch v := src.(type) {
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	case nil:
+		*o = VariantOptions{}
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into VariantOptions", src)
+	}
+	return json.Unmarshal(raw, o)
+}
+
+type ProductVariant struct {
+	ID        uuid.UUID      `json:"id" db:"id"`
+	ProductID uuid.UUID      `json:"product_id" db:"product_id"`
+	SKU       string         `json:"sku" db:"sku"`
+	Options   VariantOptions `json:"options" db:"options" swaggertype:"object,string"`
+	Price     Decimal        `json:"price" db:"price" swaggertype:"string" example:"499.99"`
+	Qty       float64        `json:"qty" db:"qty"`
+	CreatedAt time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+type CreateVariantRequest struct {
+	SKU     string         `json:"sku" validate:"required,max=64"`
+	Options VariantOptions `json:"options" swaggertype:"object,string"`
+	Price   Decimal        `json:"price" validate:"required,gt=0" swaggertype:"string" example:"499.99"`
+	Qty     float64        `json:"qty" validate:"gte=0"`
+}
+
+// UpdateVariantRequest is a JSON merge patch for a variant. Options, when
+// given, replace the whole option set.
+type UpdateVariantRequest struct {
+	SKU     *string         `json:"sku,omitempty" validate:"omitempty,min=1,max=64"`
+	Options *VariantOptions `json:"options,omitempty" swaggertype:"object,string"`
+	Price   *Decimal        `json:"price,omitempty" validate:"omitempty,gte=0" swaggertype:"string" example:"499.99"`
+	Qty     *float64        `json:"qty,omitempty" validate:"omitempty,gte=0"`
+}
+
+// ChangesNothing reports whether applying the patch would leave variant as
+// it is, in which case nothing is written.
+func (r *UpdateVariantRequest) ChangesNothing(variant *ProductVariant) bool {
+	return (r.SKU == nil || *r.SKU == variant.SKU) &&
+		(r.Options == nil || maps.Equal(*r.Options, variant.Options)) &&
+		(r.Price == nil || r.Price.Cmp(variant.Price) == 0) &&
+		(r.Qty == nil || *r.Qty == variant.Qty)
+}
+
+// PriceRange is the cheapest and most expensive sellable price of a product.
+type PriceRange struct {
+	Min Decimal `json:"min" swaggertype:"string" example:"449.99"`
+	Max Decimal `json:"max" swaggertype:"string" example:"599.99"`
+}
+
+// NewPriceRange spans the variant prices, or just the product price when
+// the product has no variants.
+func NewPriceRange(price Decimal, variants []ProductVariant) *PriceRange {
+	if len(variants) == 0 {
+		return &PriceRange{Min: price, Max: price}
+	}
+
+	r := &PriceRange{Min: variants[0].Price, Max: variants[0].Price}
+	for _, v := range variants[1:] {
+		if v.Price.Cmp(r.Min) < 0 {
+			r.Min = v.Price
+		}
+		if v.Price.Cmp(r.Max) > 0 {
+			r.Max = v.Price
+		}
+	}
+	return r
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/rezajo220/ecommerce/internal/domain"
+	services "github.com/rezajo220/ecommerce/internal/service"
+)
+
+type APIKeyHandler struct {
+	apiKeyService services.APIKeyService
+}
+
+func NewAPIKeyHandler(apiKeyService services.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyService: apiKeyService}
+}
+
+// CreateAPIKey godoc
+// @Summary Create an API key
+// @Description Issue an API key for machine-to-machine access. Scopes are permission names and cannot exceed the caller's own permissions. The full key is only returned in this response.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param key body domain.CreateAPIKeyRequest true "API key settings"
+// @Success 201 {object} domain.IssuedAPIKeyResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 422 {object} domain.ValidationErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/api-keys [post]
+func (h *APIKeyHandler) CreateAPIKey(c echo.Context) error {
+	var req domain.CreateAPIKeyRequest
+	if err := c.Bind(&req); err != nil {
+		return domain.NewBadRequestError("Invalid request body")
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	key, err := h.apiKeyService.CreateKey(c.Request().Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"message": "API key created successfully",
+		"data":    key,
+	})
+}
+
+// GetAPIKeys godoc
+// @Summary List API keys
+// @Description List all API keys, including revoked ones. Secrets are never returned.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Success 200 {object} domain.APIKeyListResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/api-keys [get]
+func (h *APIKeyHandler) GetAPIKeys(c echo.Context) error {
+	keys, err := h.apiKeyService.ListKeys(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "API keys retrieved successfully",
+		"data":    keys,
+	})
+}
+
+// RotateAPIKey godoc
+// @Summary Rotate an API key
+// @Description Replace the secret of an API key, keeping its scopes and restrictions. The old key stops working immediately. The caller must hold every scope of the key.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "API key ID (UUID)"
+// @Success 200 {object} domain.IssuedAPIKeyResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse "Missing permission, or the key has scopes the caller does not hold"
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse "API key has been revoked"
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/api-keys/{id}/rotate [post]
+func (h *APIKeyHandler) RotateAPIKey(c echo.Context) error {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return domain.NewBadRequestError("Invalid API key ID")
+	}
+
+	key, err := h.apiKeyService.RotateKey(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "API key rotated successfully",
+		"data":    key,
+	})
+}
+
+// RevokeAPIKey godoc
+// @Summary Revoke an API key
+// @Description Permanently disable an API key. The key stays listed with its revocation time. The caller must hold every scope of the key.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "API key ID (UUID)"
+// @Success 200 {object} domain.APIKeyResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse "Missing permission, or the key has scopes the caller does not hold"
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/api-keys/{id}/revoke [post]
+func (h *APIKeyHandler) RevokeAPIKey(c echo.Context) error {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return domain.NewBadRequestError("Invalid API key ID")
+	}
+
+	key, err := h.apiKeyService.RevokeKey(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "API key revoked successfully",
+		"data":    key,
+	})
+}
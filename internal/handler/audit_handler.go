@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/rezajo220/ecommerce/internal/domain"
+	services "github.com/rezajo220/ecommerce/internal/service"
+)
+
+type AuditHandler struct {
+	auditService services.AuditService
+}
+
+func NewAuditHandler(auditService services.AuditService) *AuditHandler {
+	return &AuditHandler{auditService: auditService}
+}
+
+// GetAuditLog godoc
+// @Summary Get the audit log
+// @Description Get catalog changes newest first, with the changed fields before and after each change
+// @Tags audit
+// @Accept json
+// @Produce json
+// @Param entity query string false "Entity type" Enums(product, brand)
+// @Param id query string false "Entity ID (UUID)"
+// @Param actor query string false "Who made the change: a user email or api-key:<prefix>"
+// @Param action query string false "Kind of change" Enums(create, update, delete, restore)
+// @Param from query string false "Only changes at or after this time (RFC 3339)"
+// @Param to query string false "Only changes before this time (RFC 3339)"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(10)
+// @Success 200 {object} domain.AuditListResponseWrapper
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /audit [get]
+func (h *AuditHandler) GetAuditLog(c echo.Context) error {
+	filter := domain.AuditFilter{
+		EntityType: c.QueryParam("entity"),
+		Actor:      c.QueryParam("actor"),
+		Action:     domain.AuditAction(c.QueryParam("action")),
+	}
+
+	switch filter.Action {
+	case "", domain.AuditCreate, domain.AuditUpdate, domain.AuditDelete, domain.AuditRestore:
+	default:
+		return domain.NewBadRequestError("Invalid action %q", filter.Action)
+	}
+
+	if idStr := c.QueryParam("id"); idStr != "" {
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			return domain.NewBadRequestError("Invalid entity ID")
+		}
+		filter.EntityID = &id
+	}
+
+	for _, bound := range []struct {
+		param string
+		dest  **time.Time
+	}{{"from", &filter.From}, {"to", &filter.To}} {
+		value := c.QueryParam(bound.param)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return domain.NewBadRequestError("Invalid %s: use RFC 3339, e.g. 2024-01-02T15:04:05Z", bound.param)
+		}
+		*bound.dest = &t
+	}
+
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+
+	entries, err := h.auditService.ListAuditLog(c.Request().Context(), &filter, page, limit)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Audit log retrieved successfully",
+		"data":    entries,
+	})
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rezajo220/ecommerce/internal/domain"
+	services "github.com/rezajo220/ecommerce/internal/service"
+)
+
+type AuthHandler struct {
+	authService services.AuthService
+}
+
+func NewAuthHandler(authService services.AuthService) *AuthHandler {
+	return &AuthHandler{authService: authService}
+}
+
+// Login godoc
+// @Summary Log in
+// @Description Exchange an email and password for a short-lived access token and a refresh token
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param credentials body domain.LoginRequest true "Credentials"
+// @Success 200 {object} domain.TokenResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 422 {object} domain.ValidationErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /auth/login [post]
+func (h *AuthHandler) Login(c echo.Context) error {
+	var req domain.LoginRequest
+	if err := c.Bind(&req); err != nil {
+		return domain.NewBadRequestError("Invalid request body")
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	tokens, err := h.authService.Login(c.Request().Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Logged in successfully",
+		"data":    tokens,
+	})
+}
+
+// Refresh godoc
+// @Summary Refresh tokens
+// @Description Exchange a refresh token for a new token pair. The refresh token is single use; reusing one revokes every token from the same login.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param token body domain.RefreshTokenRequest true "Refresh token"
+// @Success 200 {object} domain.TokenResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 422 {object} domain.ValidationErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /auth/refresh [post]
+func (h *AuthHandler) Refresh(c echo.Context) error {
+	var req domain.RefreshTokenRequest
+	if err := c.Bind(&req); err != nil {
+		return domain.NewBadRequestError("Invalid request body")
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	tokens, err := h.authService.Refresh(c.Request().Context(), req.RefreshToken)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Tokens refreshed successfully",
+		"data":    tokens,
+	})
+}
+
+// Logout godoc
+// @Summary Log out
+// @Description Revoke a refresh token and every token from the same login
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param token body domain.RefreshTokenRequest true "Refresh token"
+// @Success 200 {object} domain.MessageResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 422 {object} domain.ValidationErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /auth/logout [post]
+func (h *AuthHandler) Logout(c echo.Context) error {
+	var req domain.RefreshTokenRequest
+	if err := c.Bind(&req); err != nil {
+		return domain.NewBadRequestError("Invalid request body")
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	if err := h.authService.Logout(c.Request().Context(), req.RefreshToken); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Logged out successfully",
+	})
+}
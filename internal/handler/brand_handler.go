@@ -10,11 +10,12 @@ import (
 )
 
 type BrandHandler struct {
-	brandService services.BrandService
+	brandService  services.BrandService
+	preconditions Preconditions
 }
 
-func NewBrandHandler(brandService services.BrandService) *BrandHandler {
-	return &BrandHandler{brandService: brandService}
+func NewBrandHandler(brandService services.BrandService, preconditions Preconditions) *BrandHandler {
+	return &BrandHandler{brandService: brandService, preconditions: preconditions}
 }
 
 // CreateBrand godoc
@@ -25,24 +26,31 @@ func NewBrandHandler(brandService services.BrandService) *BrandHandler {
 // @Produce json
 // @Param brand body domain.CreateBrandRequest true "Brand information"
 // @Success 201 {object} domain.BrandResponse
+// @Header 201 {string} ETag "Version of the brand, for If-Match"
 // @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 422 {object} domain.ValidationErrorResponse
 // @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /brands [post]
 func (h *BrandHandler) CreateBrand(c echo.Context) error {
 	var req domain.CreateBrandRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "Invalid request body",
-		})
+		return domain.NewBadRequestError("Invalid request body")
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
 	}
 
 	brand, err := h.brandService.CreateBrand(c.Request().Context(), &req)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": err.Error(),
-		})
+		return err
 	}
 
+	setETag(c, brand.Version)
+
 	return c.JSON(http.StatusCreated, map[string]interface{}{
 		"message": "Brand created successfully",
 		"data":    brand,
@@ -55,15 +63,22 @@ func (h *BrandHandler) CreateBrand(c echo.Context) error {
 // @Tags brands
 // @Accept json
 // @Produce json
+// @Param include_deleted query bool false "Include soft-deleted brands; requires brands:delete" default(false)
 // @Success 200 {object} domain.BrandListResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
 // @Failure 500 {object} domain.ErrorResponse
 // @Router /brands [get]
 func (h *BrandHandler) GetBrands(c echo.Context) error {
-	brands, err := h.brandService.ListBrands(c.Request().Context())
+	includeDeleted, err := parseIncludeDeleted(c)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": err.Error(),
-		})
+		return err
+	}
+
+	brands, err := h.brandService.ListBrands(c.Request().Context(), includeDeleted)
+	if err != nil {
+		return err
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
@@ -72,35 +87,234 @@ func (h *BrandHandler) GetBrands(c echo.Context) error {
 	})
 }
 
+// GetBrand godoc
+// @Summary Get a brand
+// @Description Get a single brand by ID
+// @Tags brands
+// @Accept json
+// @Produce json
+// @Param id path string true "Brand ID (UUID)"
+// @Param include_deleted query bool false "Also find a soft-deleted brand; requires brands:delete" default(false)
+// @Success 200 {object} domain.BrandResponse
+// @Header 200 {string} ETag "Version of the brand, for If-Match"
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /brands/{id} [get]
+func (h *BrandHandler) GetBrand(c echo.Context) error {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return domain.NewBadRequestError("Invalid brand ID")
+	}
+
+	includeDeleted, err := parseIncludeDeleted(c)
+	if err != nil {
+		return err
+	}
+
+	brand, err := h.brandService.GetBrand(c.Request().Context(), id, includeDeleted)
+	if err != nil {
+		return err
+	}
+
+	setETag(c, brand.Version)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Brand retrieved successfully",
+		"data":    brand,
+	})
+}
+
+// ReplaceBrand godoc
+// @Summary Replace a brand
+// @Description Replace every field of an existing brand by ID
+// @Tags brands
+// @Accept json
+// @Produce json
+// @Param id path string true "Brand ID (UUID)"
+// @Param If-Match header string false "ETag from the last read of the brand; required unless REQUIRE_IF_MATCH is false"
+// @Param brand body domain.ReplaceBrandRequest true "Complete brand information"
+// @Success 200 {object} domain.BrandResponse
+// @Header 200 {string} ETag "Version of the brand, for If-Match"
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 412 {object} domain.ErrorResponse "The brand has changed since it was read"
+// @Failure 422 {object} domain.ValidationErrorResponse
+// @Failure 428 {object} domain.ErrorResponse "If-Match header missing"
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /brands/{id} [put]
+func (h *BrandHandler) ReplaceBrand(c echo.Context) error {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return domain.NewBadRequestError("Invalid brand ID")
+	}
+
+	version, err := h.preconditions.ExpectedVersion(c)
+	if err != nil {
+		return err
+	}
+
+	var req domain.ReplaceBrandRequest
+	if err := c.Bind(&req); err != nil {
+		return domain.NewBadRequestError("Invalid request body")
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	brand, err := h.brandService.ReplaceBrand(c.Request().Context(), id, &req, version)
+	if err != nil {
+		return err
+	}
+
+	setETag(c, brand.Version)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Brand updated successfully",
+		"data":    brand,
+	})
+}
+
+// PatchBrand godoc
+// @Summary Partially update a brand
+// @Description Apply a JSON merge patch to an existing brand; omitted fields are left unchanged
+// @Tags brands
+// @Accept application/merge-patch+json
+// @Accept json
+// @Produce json
+// @Param id path string true "Brand ID (UUID)"
+// @Param If-Match header string false "ETag from the last read of the brand; required unless REQUIRE_IF_MATCH is false"
+// @Param brand body domain.UpdateBrandRequest true "Fields to change"
+// @Success 200 {object} domain.BrandResponse
+// @Header 200 {string} ETag "Version of the brand, for If-Match"
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 412 {object} domain.ErrorResponse "The brand has changed since it was read"
+// @Failure 415 {object} domain.ErrorResponse
+// @Failure 422 {object} domain.ValidationErrorResponse
+// @Failure 428 {object} domain.ErrorResponse "If-Match header missing"
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /brands/{id} [patch]
+func (h *BrandHandler) PatchBrand(c echo.Context) error {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return domain.NewBadRequestError("Invalid brand ID")
+	}
+
+	version, err := h.preconditions.ExpectedVersion(c)
+	if err != nil {
+		return err
+	}
+
+	var req domain.UpdateBrandRequest
+	if err := bindMergePatch(c, &req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	brand, err := h.brandService.UpdateBrand(c.Request().Context(), id, &req, version)
+	if err != nil {
+		return err
+	}
+
+	setETag(c, brand.Version)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Brand updated successfully",
+		"data":    brand,
+	})
+}
+
 // DeleteBrand godoc
 // @Summary Delete a brand
-// @Description Delete an existing brand by ID (only if not used by products)
+// @Description Soft delete an existing brand by ID (only if no undeleted product uses it)
 // @Tags brands
 // @Accept json
 // @Produce json
 // @Param id path string true "Brand ID (UUID)"
+// @Param If-Match header string false "ETag from the last read of the brand; required unless REQUIRE_IF_MATCH is false"
 // @Success 200 {object} domain.MessageResponse
 // @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
 // @Failure 404 {object} domain.ErrorResponse
 // @Failure 409 {object} domain.ErrorResponse "Brand is being used by products"
+// @Failure 412 {object} domain.ErrorResponse "The brand has changed since it was read"
+// @Failure 428 {object} domain.ErrorResponse "If-Match header missing"
 // @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /brands/{id} [delete]
 func (h *BrandHandler) DeleteBrand(c echo.Context) error {
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "Invalid brand ID",
-		})
+		return domain.NewBadRequestError("Invalid brand ID")
 	}
 
-	if err := h.brandService.DeleteBrand(c.Request().Context(), id); err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": err.Error(),
-		})
+	version, err := h.preconditions.ExpectedVersion(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.brandService.DeleteBrand(c.Request().Context(), id, version); err != nil {
+		return err
 	}
 
 	return c.JSON(http.StatusOK, map[string]string{
 		"message": "Brand deleted successfully",
 	})
 }
+
+// RestoreBrand godoc
+// @Summary Restore a deleted brand
+// @Description Undo the soft delete of a brand
+// @Tags brands
+// @Accept json
+// @Produce json
+// @Param id path string true "Brand ID (UUID)"
+// @Success 200 {object} domain.BrandResponse
+// @Header 200 {string} ETag "Version of the brand, for If-Match"
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse "Brand is not deleted"
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /brands/{id}/restore [post]
+func (h *BrandHandler) RestoreBrand(c echo.Context) error {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return domain.NewBadRequestError("Invalid brand ID")
+	}
+
+	brand, err := h.brandService.RestoreBrand(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	setETag(c, brand.Version)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Brand restored successfully",
+		"data":    brand,
+	})
+}
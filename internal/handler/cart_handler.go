@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/rezajo220/ecommerce/internal/domain"
+	services "github.com/rezajo220/ecommerce/internal/service"
+)
+
+type CartHandler struct {
+	cartService services.CartService
+}
+
+func NewCartHandler(cartService services.CartService) *CartHandler {
+	return &CartHandler{cartService: cartService}
+}
+
+// CreateCart godoc
+// @Summary Create a cart
+// @Description Create an empty shopping cart
+// @Tags carts
+// @Accept json
+// @Produce json
+// @Success 201 {object} domain.CartResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /carts/ [post]
+func (h *CartHandler) CreateCart(c echo.Context) error {
+	cart, err := h.cartService.CreateCart(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"message": "Cart created successfully",
+		"data":    cart,
+	})
+}
+
+// GetCart godoc
+// @Summary Get a cart
+// @Description Get a cart with its lines repriced at current product prices, per-line stock warnings and totals
+// @Tags carts
+// @Accept json
+// @Produce json
+// @Param id path string true "Cart ID (UUID)"
+// @Success 200 {object} domain.CartResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /carts/{id} [get]
+func (h *CartHandler) GetCart(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return domain.NewBadRequestError("Invalid cart ID")
+	}
+
+	cart, err := h.cartService.GetCart(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Cart retrieved successfully",
+		"data":    cart,
+	})
+}
+
+// AddCartItem godoc
+// @Summary Add an item to a cart
+// @Description Add a product to the cart; adding a product already in the cart increases its qty. A cart holds products priced in one currency only.
+// @Tags carts
+// @Accept json
+// @Produce json
+// @Param id path string true "Cart ID (UUID)"
+// @Param item body domain.AddCartItemRequest true "Item to add"
+// @Success 200 {object} domain.CartResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse "Cart or product not found"
+// @Failure 422 {object} domain.ValidationErrorResponse "Invalid request or product priced in another currency than the cart"
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /carts/{id}/items [post]
+func (h *CartHandler) AddCartItem(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return domain.NewBadRequestError("Invalid cart ID")
+	}
+
+	var req domain.AddCartItemRequest
+	if err := c.Bind(&req); err != nil {
+		return domain.NewBadRequestError("Invalid request body")
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	cart, err := h.cartService.AddItem(c.Request().Context(), id, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Item added successfully",
+		"data":    cart,
+	})
+}
+
+// UpdateCartItem godoc
+// @Summary Update a cart item
+// @Description Set the qty of a cart line
+// @Tags carts
+// @Accept json
+// @Produce json
+// @Param id path string true "Cart ID (UUID)"
+// @Param item_id path string true "Cart item ID (UUID)"
+// @Param item body domain.UpdateCartItemRequest true "New qty"
+// @Success 200 {object} domain.CartResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 422 {object} domain.ValidationErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /carts/{id}/items/{item_id} [put]
+func (h *CartHandler) UpdateCartItem(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return domain.NewBadRequestError("Invalid cart ID")
+	}
+	itemID, err := uuid.Parse(c.Param("item_id"))
+	if err != nil {
+		return domain.NewBadRequestError("Invalid cart item ID")
+	}
+
+	var req domain.UpdateCartItemRequest
+	if err := c.Bind(&req); err != nil {
+		return domain.NewBadRequestError("Invalid request body")
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	cart, err := h.cartService.UpdateItem(c.Request().Context(), id, itemID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Item updated successfully",
+		"data":    cart,
+	})
+}
+
+// RemoveCartItem godoc
+// @Summary Remove a cart item
+// @Description Remove a line from the cart
+// @Tags carts
+// @Accept json
+// @Produce json
+// @Param id path string true "Cart ID (UUID)"
+// @Param item_id path string true "Cart item ID (UUID)"
+// @Success 200 {object} domain.CartResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /carts/{id}/items/{item_id} [delete]
+func (h *CartHandler) RemoveCartItem(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return domain.NewBadRequestError("Invalid cart ID")
+	}
+	itemID, err := uuid.Parse(c.Param("item_id"))
+	if err != nil {
+		return domain.NewBadRequestError("Invalid cart item ID")
+	}
+
+	cart, err := h.cartService.RemoveItem(c.Request().Context(), id, itemID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Item removed successfully",
+		"data":    cart,
+	})
+}
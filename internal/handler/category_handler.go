@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/rezajo220/ecommerce/internal/domain"
+	services "github.com/rezajo220/ecommerce/internal/service"
+)
+
+type CategoryHandler struct {
+	categoryService services.CategoryService
+}
+
+func NewCategoryHandler(categoryService services.CategoryService) *CategoryHandler {
+	return &CategoryHandler{categoryService: categoryService}
+}
+
+// CreateCategory godoc
+// @Summary Create a new category
+// @Description Create a new category, optionally under a parent category
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param category body domain.CreateCategoryRequest true "Category information"
+// @Success 201 {object} domain.CategoryResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse "Parent category not found"
+// @Failure 422 {object} domain.ValidationErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /categories [post]
+func (h *CategoryHandler) CreateCategory(c echo.Context) error {
+	var req domain.CreateCategoryRequest
+	if err := c.Bind(&req); err != nil {
+		return domain.NewBadRequestError("Invalid request body")
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	category, err := h.categoryService.CreateCategory(c.Request().Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"message": "Category created successfully",
+		"data":    category,
+	})
+}
+
+// GetCategories godoc
+// @Summary Get all categories
+// @Description Get a flat list of all categories; parent_id links them into a tree
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Success 200 {object} domain.CategoryListResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /categories [get]
+func (h *CategoryHandler) GetCategories(c echo.Context) error {
+	categories, err := h.categoryService.ListCategories(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Categories retrieved successfully",
+		"data":    categories,
+	})
+}
+
+// GetCategory godoc
+// @Summary Get a category
+// @Description Get a single category by ID, including its breadcrumb path from the root
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param id path string true "Category ID (UUID)"
+// @Success 200 {object} domain.CategoryResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /categories/{id} [get]
+func (h *CategoryHandler) GetCategory(c echo.Context) error {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return domain.NewBadRequestError("Invalid category ID")
+	}
+
+	category, err := h.categoryService.GetCategory(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Category retrieved successfully",
+		"data":    category,
+	})
+}
+
+// ReplaceCategory godoc
+// @Summary Replace a category
+// @Description Rename and/or move a category; a null parent_id moves it to the root
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param id path string true "Category ID (UUID)"
+// @Param category body domain.ReplaceCategoryRequest true "Complete category information"
+// @Success 200 {object} domain.CategoryResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 422 {object} domain.ValidationErrorResponse "Invalid body or the move would create a cycle"
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /categories/{id} [put]
+func (h *CategoryHandler) ReplaceCategory(c echo.Context) error {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return domain.NewBadRequestError("Invalid category ID")
+	}
+
+	var req domain.ReplaceCategoryRequest
+	if err := c.Bind(&req); err != nil {
+		return domain.NewBadRequestError("Invalid request body")
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	category, err := h.categoryService.ReplaceCategory(c.Request().Context(), id, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Category updated successfully",
+		"data":    category,
+	})
+}
+
+// DeleteCategory godoc
+// @Summary Delete a category
+// @Description Delete a category that has no subcategories and no products assigned
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param id path string true "Category ID (UUID)"
+// @Success 200 {object} domain.MessageResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse "Category has subcategories or products"
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /categories/{id} [delete]
+func (h *CategoryHandler) DeleteCategory(c echo.Context) error {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return domain.NewBadRequestError("Invalid category ID")
+	}
+
+	if err := h.categoryService.DeleteCategory(c.Request().Context(), id); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Category deleted successfully",
+	})
+}
+
+// GetCategoryProducts godoc
+// @Summary Get products in a category
+// @Description Get products assigned to a category or any of its descendants, with breadcrumb paths
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param id path string true "Category ID (UUID)"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(10)
+// @Success 200 {object} domain.ProductListResponseWrapper
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /categories/{id}/products [get]
+func (h *CategoryHandler) GetCategoryProducts(c echo.Context) error {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return domain.NewBadRequestError("Invalid category ID")
+	}
+
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit < 1 {
+		limit = 10
+	}
+
+	response, err := h.categoryService.ListCategoryProducts(c.Request().Context(), id, page, limit)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Products retrieved successfully",
+		"data":    response,
+	})
+}
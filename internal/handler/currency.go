@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rezajo220/ecommerce/internal/domain"
+)
+
+// HeaderAcceptCurrency asks for product prices in a currency when the
+// currency query parameter is not given, e.g. "Accept-Currency: USD".
+const HeaderAcceptCurrency = "Accept-Currency"
+
+// requestedCurrency is the currency a product read should be priced in, or
+// nil to keep each product's own. The currency query parameter wins and must
+// be supported; Accept-Currency is a list of preferences like Accept-Language,
+// so its unsupported entries are skipped.
+func requestedCurrency(c echo.Context) (*domain.Currency, error) {
+	c.Response().Header().Add(echo.HeaderVary, HeaderAcceptCurrency)
+
+	if code := c.QueryParam("currency"); code != "" {
+		currency, err := domain.LookupCurrency(code)
+		if err != nil {
+			return nil, domain.NewBadRequestError("Invalid currency %q", code)
+		}
+		return &currency, nil
+	}
+
+	for _, code := range acceptedCurrencies(c.Request().Header.Get(HeaderAcceptCurrency)) {
+		if currency, err := domain.LookupCurrency(code); err == nil {
+			return &currency, nil
+		}
+	}
+	return nil, nil
+}
+
+// acceptedCurrencies lists the codes of an Accept-Currency header by
+// descending q value, dropping those with q=0.
+func acceptedCurrencies(header string) []string {
+	type preference struct {
+		code string
+		q    float64
+	}
+
+	var preferences []preference
+	for _, part := range strings.Split(header, ",") {
+		code, params, _ := strings.Cut(part, ";")
+		code = strings.TrimSpace(code)
+		if code == "" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if name == "q" {
+				if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		if q > 0 {
+			preferences = append(preferences, preference{code: code, q: q})
+		}
+	}
+
+	sort.SliceStable(preferences, func(i, j int) bool { return preferences[i].q > preferences[j].q })
+	codes := make([]string, len(preferences))
+	for i, p := range preferences {
+		codes[i] = p.code
+	}
+	return codes
+}
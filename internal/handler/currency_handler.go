@@ -0,0 +1,284 @@
+package handlers
+
+import (
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/rezajo220/ecommerce/internal/domain"
+	services "github.com/rezajo220/ecommerce/internal/service"
+)
+
+const MIMETextCSV = "text/csv"
+
+// maxExchangeRatesCSVSize bounds an exchange rate import; a rate per
+// currency pair is a few hundred bytes at most.
+const maxExchangeRatesCSVSize = 1 << 20
+
+type CurrencyHandler struct {
+	currencyService services.CurrencyService
+}
+
+func NewCurrencyHandler(currencyService services.CurrencyService) *CurrencyHandler {
+	return &CurrencyHandler{currencyService: currencyService}
+}
+
+// GetExchangeRates godoc
+// @Summary List exchange rates
+// @Description List the exchange rates used to convert product prices
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Success 200 {object} domain.ExchangeRateListResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/exchange-rates [get]
+func (h *CurrencyHandler) GetExchangeRates(c echo.Context) error {
+	rates, err := h.currencyService.ListExchangeRates(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Exchange rates retrieved successfully",
+		"data":    rates,
+	})
+}
+
+// SetExchangeRate godoc
+// @Summary Set an exchange rate
+// @Description Create or replace the rate at which one unit of the base currency converts into the quote currency
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param base path string true "Base currency" Enums(IDR, USD)
+// @Param quote path string true "Quote currency" Enums(IDR, USD)
+// @Param rate body domain.SetExchangeRateRequest true "Exchange rate"
+// @Success 200 {object} domain.ExchangeRateResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 422 {object} domain.ValidationErrorResponse "Unsupported or identical currencies, or an invalid rate"
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/exchange-rates/{base}/{quote} [put]
+func (h *CurrencyHandler) SetExchangeRate(c echo.Context) error {
+	var req domain.SetExchangeRateRequest
+	if err := c.Bind(&req); err != nil {
+		return domain.NewBadRequestError("Invalid request body")
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	rate, err := h.currencyService.SetExchangeRate(c.Request().Context(), c.Param("base"), c.Param("quote"), &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Exchange rate set successfully",
+		"data":    rate,
+	})
+}
+
+// DeleteExchangeRate godoc
+// @Summary Delete an exchange rate
+// @Description Delete the rate from the base into the quote currency; prices without an override can no longer be converted
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param base path string true "Base currency" Enums(IDR, USD)
+// @Param quote path string true "Quote currency" Enums(IDR, USD)
+// @Success 200 {object} domain.MessageResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 422 {object} domain.ErrorResponse "Unsupported or identical currencies"
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/exchange-rates/{base}/{quote} [delete]
+func (h *CurrencyHandler) DeleteExchangeRate(c echo.Context) error {
+	if err := h.currencyService.DeleteExchangeRate(c.Request().Context(), c.Param("base"), c.Param("quote")); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Exchange rate deleted successfully",
+	})
+}
+
+// ImportExchangeRates godoc
+// @Summary Import exchange rates from CSV
+// @Description Set several exchange rates at once from CSV lines of base,quote,rate, e.g. USD,IDR,16250. A first line of
+// @Description base_currency,quote_currency,rate is skipped. The CSV is sent as a text/csv body or as the file field of a
+// @Description multipart form. Either every rate is set or, when a line is invalid, none is.
+// @Tags admin
+// @Accept text/csv,mpfd
+// @Produce json
+// @Param file formData file false "CSV file, for multipart uploads"
+// @Success 200 {object} domain.ImportExchangeRatesResponseWrapper
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 415 {object} domain.ErrorResponse
+// @Failure 422 {object} domain.ErrorResponse "An invalid line"
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/exchange-rates/import [post]
+func (h *CurrencyHandler) ImportExchangeRates(c echo.Context) error {
+	csv, err := exchangeRatesCSV(c)
+	if err != nil {
+		return err
+	}
+	defer csv.Close()
+
+	response, err := h.currencyService.ImportExchangeRates(c.Request().Context(), io.LimitReader(csv, maxExchangeRatesCSVSize))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Exchange rates imported successfully",
+		"data":    response,
+	})
+}
+
+// exchangeRatesCSV opens the CSV of an import request, which is either the
+// whole body or an uploaded file.
+func exchangeRatesCSV(c echo.Context) (io.ReadCloser, error) {
+	mediaType, _, err := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+	if err != nil {
+		return nil, echo.ErrUnsupportedMediaType
+	}
+
+	switch mediaType {
+	case MIMETextCSV, echo.MIMETextPlain:
+		return c.Request().Body, nil
+	case echo.MIMEMultipartForm:
+		header, err := c.FormFile("file")
+		if err != nil {
+			return nil, domain.NewBadRequestError("Missing CSV file in the file field")
+		}
+		file, err := header.Open()
+		if err != nil {
+			return nil, err
+		}
+		return file, nil
+	}
+	return nil, echo.ErrUnsupportedMediaType
+}
+
+// GetProductPrices godoc
+// @Summary List a product's price overrides
+// @Description List the fixed prices of a product in other currencies, used instead of converting its price
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID (UUID)"
+// @Success 200 {object} domain.ProductCurrencyPriceListResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /products/{id}/prices [get]
+func (h *CurrencyHandler) GetProductPrices(c echo.Context) error {
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return domain.NewBadRequestError("Invalid product ID")
+	}
+
+	prices, err := h.currencyService.ListProductPrices(c.Request().Context(), productID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Price overrides retrieved successfully",
+		"data":    prices,
+	})
+}
+
+// SetProductPrice godoc
+// @Summary Set a product's price in a currency
+// @Description Fix the product's price in a currency other than its own instead of converting it
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID (UUID)"
+// @Param currency path string true "Currency" Enums(IDR, USD)
+// @Param price body domain.SetProductCurrencyPriceRequest true "Price in the currency"
+// @Success 200 {object} domain.ProductCurrencyPriceResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 422 {object} domain.ValidationErrorResponse "Unsupported currency, the product's own currency, or too many decimal places"
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /products/{id}/prices/{currency} [put]
+func (h *CurrencyHandler) SetProductPrice(c echo.Context) error {
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return domain.NewBadRequestError("Invalid product ID")
+	}
+
+	var req domain.SetProductCurrencyPriceRequest
+	if err := c.Bind(&req); err != nil {
+		return domain.NewBadRequestError("Invalid request body")
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	price, err := h.currencyService.SetProductPrice(c.Request().Context(), productID, c.Param("currency"), &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Price override set successfully",
+		"data":    price,
+	})
+}
+
+// DeleteProductPrice godoc
+// @Summary Delete a product's price in a currency
+// @Description Remove the override so the product's price in the currency is converted again
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID (UUID)"
+// @Param currency path string true "Currency" Enums(IDR, USD)
+// @Success 200 {object} domain.MessageResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 422 {object} domain.ErrorResponse "Unsupported currency"
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /products/{id}/prices/{currency} [delete]
+func (h *CurrencyHandler) DeleteProductPrice(c echo.Context) error {
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return domain.NewBadRequestError("Invalid product ID")
+	}
+
+	if err := h.currencyService.DeleteProductPrice(c.Request().Context(), productID, c.Param("currency")); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Price override deleted successfully",
+	})
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAcceptedCurrencies(t *testing.T) {
+	tests := []struct {
+		header string
+		want   []string
+	}{
+		{header: "", want: []string{}},
+		{header: "USD", want: []string{"USD"}},
+		{header: " USD , IDR ", want: []string{"USD", "IDR"}},
+		{header: "IDR;q=0.5, USD", want: []string{"USD", "IDR"}},
+		{header: "EUR;q=0.9, USD;q=0.9, IDR;q=0.1", want: []string{"EUR", "USD", "IDR"}},
+		{header: "USD;q=0, IDR", want: []string{"IDR"}},
+		{header: "USD; charset=x; q=0.2, IDR;q=0.3", want: []string{"IDR", "USD"}},
+		{header: "USD;q=abc, IDR;q=0.5", want: []string{"USD", "IDR"}},
+		{header: ",;q=1,", want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			if got := acceptedCurrencies(tt.header); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("acceptedCurrencies(%q) = %q, want %q", tt.header, got, tt.want)
+			}
+		})
+	}
+}
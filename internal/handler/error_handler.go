@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rezajo220/ecommerce/internal/domain"
+)
+
+// HTTPErrorHandler is installed as the Echo error handler. It maps domain
+// errors to status codes so handlers can simply return the service error.
+func HTTPErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	status, message := statusFromError(err)
+	if status >= http.StatusInternalServerError {
+		c.Logger().Error(err)
+	}
+
+	var body interface{} = domain.ErrorResponse{Error: message}
+	var verrs domain.ValidationErrors
+	if errors.As(err, &verrs) {
+		body = domain.ValidationErrorResponse{Error: "Validation failed", Fields: verrs}
+	}
+
+	var respErr error
+	if c.Request().Method == http.MethodHead {
+		respErr = c.NoContent(status)
+	} else {
+		respErr = c.JSON(status, body)
+	}
+	if respErr != nil {
+		c.Logger().Error(respErr)
+	}
+}
+
+func statusFromError(err error) (int, string) {
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		if msg, ok := httpErr.Message.(string); ok {
+			return httpErr.Code, msg
+		}
+		return httpErr.Code, http.StatusText(httpErr.Code)
+	}
+
+	switch {
+	case errors.Is(err, domain.ErrBadRequest):
+		return http.StatusBadRequest, err.Error()
+	case errors.Is(err, domain.ErrUnauthorized):
+		return http.StatusUnauthorized, err.Error()
+	case errors.Is(err, domain.ErrPaymentRequired):
+		return http.StatusPaymentRequired, err.Error()
+	case errors.Is(err, domain.ErrForbidden):
+		return http.StatusForbidden, err.Error()
+	case errors.Is(err, domain.ErrNotFound):
+		return http.StatusNotFound, err.Error()
+	case errors.Is(err, domain.ErrConflict):
+		return http.StatusConflict, err.Error()
+	case errors.Is(err, domain.ErrPreconditionFailed):
+		return http.StatusPreconditionFailed, err.Error()
+	case errors.Is(err, domain.ErrPreconditionRequired):
+		return http.StatusPreconditionRequired, err.Error()
+	case errors.Is(err, domain.ErrTooManyRequests):
+		return http.StatusTooManyRequests, err.Error()
+	case errors.Is(err, domain.ErrValidation):
+		return http.StatusUnprocessableEntity, err.Error()
+	}
+
+	return http.StatusInternalServerError, domain.ErrInternal.Error()
+}
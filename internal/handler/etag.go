@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rezajo220/ecommerce/internal/domain"
+)
+
+const (
+	HeaderETag    = "ETag"
+	HeaderIfMatch = "If-Match"
+)
+
+// Preconditions implements optimistic concurrency for versioned resources:
+// reads carry the version as an ETag and writes send it back in If-Match.
+type Preconditions struct {
+	// RequireIfMatch rejects writes without If-Match with 428 instead of
+	// applying them unconditionally.
+	RequireIfMatch bool
+}
+
+func setETag(c echo.Context, version int) {
+	c.Response().Header().Set(HeaderETag, strconv.Quote(strconv.Itoa(version)))
+}
+
+// ExpectedVersion reads If-Match. It returns nil when the write may apply
+// to any version: for "*", or when the header is missing and not required.
+// Weak or unknown entity tags can never match and fail with 412.
+func (p Preconditions) ExpectedVersion(c echo.Context) (*int, error) {
+	header := strings.TrimSpace(c.Request().Header.Get(HeaderIfMatch))
+	if header == "" {
+		if p.RequireIfMatch {
+			return nil, domain.ErrIfMatchRequired
+		}
+		return nil, nil
+	}
+	if header == "*" {
+		return nil, nil
+	}
+	if strings.Contains(header, ",") {
+		return nil, domain.NewBadRequestError("If-Match must be a single ETag or *")
+	}
+
+	tag, err := strconv.Unquote(header)
+	if err != nil {
+		return nil, domain.ErrVersionMismatch
+	}
+	version, err := strconv.Atoi(tag)
+	if err != nil {
+		return nil, domain.ErrVersionMismatch
+	}
+	return &version, nil
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rezajo220/ecommerce/internal/domain"
+)
+
+// IncludesDeleted reports whether the request asks for soft-deleted rows
+// through include_deleted=true. Routes pass it to AccessGuard.RequireWhen so
+// only callers allowed to delete the resource can see deleted rows.
+func IncludesDeleted(c echo.Context) bool {
+	includeDeleted, _ := strconv.ParseBool(c.QueryParam("include_deleted"))
+	return includeDeleted
+}
+
+func parseIncludeDeleted(c echo.Context) (bool, error) {
+	raw := c.QueryParam("include_deleted")
+	if raw == "" {
+		return false, nil
+	}
+	includeDeleted, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, domain.NewBadRequestError("Invalid include_deleted %q", raw)
+	}
+	return includeDeleted, nil
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/rezajo220/ecommerce/internal/domain"
+	services "github.com/rezajo220/ecommerce/internal/service"
+)
+
+type InventoryHandler struct {
+	inventoryService services.InventoryService
+}
+
+func NewInventoryHandler(inventoryService services.InventoryService) *InventoryHandler {
+	return &InventoryHandler{inventoryService: inventoryService}
+}
+
+// AdjustStock godoc
+// @Summary Adjust product stock
+// @Description Record a stock movement and apply it to the product's qty. Receipts and returns add stock, sales remove it, and adjustments apply a signed quantity.
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID (UUID)"
+// @Param movement body domain.StockAdjustmentRequest true "Stock movement"
+// @Success 201 {object} domain.StockMovementResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse "Insufficient stock"
+// @Failure 422 {object} domain.ValidationErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /products/{id}/stock-adjustments [post]
+func (h *InventoryHandler) AdjustStock(c echo.Context) error {
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return domain.NewBadRequestError("Invalid product ID")
+	}
+
+	var req domain.StockAdjustmentRequest
+	if err := c.Bind(&req); err != nil {
+		return domain.NewBadRequestError("Invalid request body")
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	movement, err := h.inventoryService.AdjustStock(c.Request().Context(), productID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"message": "Stock adjusted successfully",
+		"data":    movement,
+	})
+}
+
+// GetStockHistory godoc
+// @Summary Get product stock history
+// @Description Get the stock movements of a product, newest first
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID (UUID)"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(10)
+// @Success 200 {object} domain.StockHistoryResponseWrapper
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /products/{id}/stock-history [get]
+func (h *InventoryHandler) GetStockHistory(c echo.Context) error {
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return domain.NewBadRequestError("Invalid product ID")
+	}
+
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+
+	history, err := h.inventoryService.GetStockHistory(c.Request().Context(), productID, page, limit)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Stock history retrieved successfully",
+		"data":    history,
+	})
+}
+
+// ReconcileStock godoc
+// @Summary Reconcile stock
+// @Description List products whose qty does not equal the sum of their stock movements. An empty list means the ledger is consistent.
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Success 200 {object} domain.StockReconciliationResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /inventory/reconciliation [get]
+func (h *InventoryHandler) ReconcileStock(c echo.Context) error {
+	discrepancies, err := h.inventoryService.Reconcile(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Stock reconciliation completed",
+		"data":    discrepancies,
+	})
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rezajo220/ecommerce/internal/domain"
+)
+
+const MIMEApplicationMergePatchJSON = "application/merge-patch+json"
+
+// bindMergePatch decodes a JSON merge patch (RFC 7396) body into dst, whose
+// fields are pointers so absent members stay nil. Echo's default binder
+// rejects the merge-patch media type, hence the manual decoding. None of our
+// resources have nullable fields, so a member set to null is rejected rather
+// than interpreted as "remove", and so is a member the resource does not
+// have, since a misspelt field would otherwise be ignored silently.
+func bindMergePatch(c echo.Context, dst interface{}) error {
+	contentType := c.Request().Header.Get(echo.HeaderContentType)
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || (mediaType != MIMEApplicationMergePatchJSON && mediaType != echo.MIMEApplicationJSON) {
+		return echo.ErrUnsupportedMediaType
+	}
+
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return domain.NewBadRequestError("Invalid request body")
+	}
+
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(body, &members); err != nil || members == nil {
+		return domain.NewBadRequestError("Merge patch document must be a JSON object")
+	}
+
+	var nullFields domain.ValidationErrors
+	for name, raw := range members {
+		if bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+			nullFields = append(nullFields, domain.FieldError{
+				Field:   name,
+				Rule:    "nonnull",
+				Message: name + " cannot be null",
+			})
+		}
+	}
+	if len(nullFields) > 0 {
+		sort.Slice(nullFields, func(i, j int) bool { return nullFields[i].Field < nullFields[j].Field })
+		return nullFields
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(dst); err != nil {
+		if name, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+			name, _ = strconv.Unquote(name)
+			return domain.ValidationErrors{{
+				Field:   name,
+				Rule:    "unknown",
+				Message: name + " is not a known field",
+			}}
+		}
+		return domain.NewBadRequestError("Invalid request body")
+	}
+
+	return nil
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/rezajo220/ecommerce/internal/domain"
+	services "github.com/rezajo220/ecommerce/internal/service"
+)
+
+type OrderHandler struct {
+	orderService services.OrderService
+}
+
+func NewOrderHandler(orderService services.OrderService) *OrderHandler {
+	return &OrderHandler{orderService: orderService}
+}
+
+// Checkout godoc
+// @Summary Check out
+// @Description Place a pending order for the contents of a cart (cart_id) or an explicit list of lines (items). Product names, prices and currencies are snapshotted and the ordered qty is taken out of stock in one transaction. All products must be priced in the same currency, which becomes the order currency.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param checkout body domain.CheckoutRequest true "Cart or lines to order"
+// @Success 201 {object} domain.OrderResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse "Cart or product not found"
+// @Failure 409 {object} domain.ErrorResponse "Insufficient stock or cart already checked out"
+// @Failure 422 {object} domain.ValidationErrorResponse "Invalid request or products priced in more than one currency"
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /checkout [post]
+func (h *OrderHandler) Checkout(c echo.Context) error {
+	var req domain.CheckoutRequest
+	if err := c.Bind(&req); err != nil {
+		return domain.NewBadRequestError("Invalid request body")
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	order, err := h.orderService.Checkout(c.Request().Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"message": "Order placed successfully",
+		"data":    order,
+	})
+}
+
+// GetOrders godoc
+// @Summary Get orders
+// @Description Get orders newest first, optionally filtered by status
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param status query string false "Only orders in this status" Enums(pending, paid, shipped, delivered, cancelled, refunded)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(10)
+// @Success 200 {object} domain.OrderListResponseWrapper
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /orders/ [get]
+func (h *OrderHandler) GetOrders(c echo.Context) error {
+	status := domain.OrderStatus(c.QueryParam("status"))
+	switch status {
+	case "", domain.OrderPending, domain.OrderPaid, domain.OrderShipped, domain.OrderDelivered, domain.OrderCancelled, domain.OrderRefunded:
+	default:
+		return domain.NewBadRequestError("Invalid status %q", status)
+	}
+
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+
+	orders, err := h.orderService.ListOrders(c.Request().Context(), status, page, limit)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Orders retrieved successfully",
+		"data":    orders,
+	})
+}
+
+// GetOrder godoc
+// @Summary Get an order
+// @Description Get an order and its items by ID. Only the caller who placed the order, or a caller with orders:read, can read it.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID (UUID)"
+// @Success 200 {object} domain.OrderResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /orders/{id} [get]
+func (h *OrderHandler) GetOrder(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return domain.NewBadRequestError("Invalid order ID")
+	}
+
+	order, err := h.orderService.GetOrder(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Order retrieved successfully",
+		"data":    order,
+	})
+}
+
+// UpdateOrderStatus godoc
+// @Summary Change an order's status
+// @Description Move an order along pending → paid → shipped → delivered, or cancel/refund it. Forbidden transitions are rejected; cancelling (and refunding before shipment) returns the items to stock.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID (UUID)"
+// @Param status body domain.UpdateOrderStatusRequest true "New status"
+// @Success 200 {object} domain.OrderResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse "Transition not allowed"
+// @Failure 422 {object} domain.ValidationErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /orders/{id}/status [put]
+func (h *OrderHandler) UpdateOrderStatus(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return domain.NewBadRequestError("Invalid order ID")
+	}
+
+	var req domain.UpdateOrderStatusRequest
+	if err := c.Bind(&req); err != nil {
+		return domain.NewBadRequestError("Invalid request body")
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	order, err := h.orderService.UpdateOrderStatus(c.Request().Context(), id, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Order status updated successfully",
+		"data":    order,
+	})
+}
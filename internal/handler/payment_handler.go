@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/rezajo220/ecommerce/internal/domain"
+	services "github.com/rezajo220/ecommerce/internal/service"
+)
+
+// HeaderPaymentSignature carries the hex HMAC-SHA256 of a webhook body.
+const HeaderPaymentSignature = "X-Payment-Signature"
+
+type PaymentHandler struct {
+	paymentService services.PaymentService
+}
+
+func NewPaymentHandler(paymentService services.PaymentService) *PaymentHandler {
+	return &PaymentHandler{paymentService: paymentService}
+}
+
+// PayOrder godoc
+// @Summary Pay an order
+// @Description Authorize and capture the total of a pending order with the payment gateway. A captured payment marks the order paid.
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID (UUID)"
+// @Param payment body domain.PayOrderRequest true "Payment method"
+// @Success 201 {object} domain.PaymentResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 402 {object} domain.ErrorResponse "Payment declined"
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse "Order is not pending, or already has a payment in progress"
+// @Failure 422 {object} domain.ValidationErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /orders/{id}/payments [post]
+func (h *PaymentHandler) PayOrder(c echo.Context) error {
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return domain.NewBadRequestError("Invalid order ID")
+	}
+
+	var req domain.PayOrderRequest
+	if err := c.Bind(&req); err != nil {
+		return domain.NewBadRequestError("Invalid request body")
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	payment, err := h.paymentService.PayOrder(c.Request().Context(), orderID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"message": "Payment processed successfully",
+		"data":    payment,
+	})
+}
+
+// GetOrderPayments godoc
+// @Summary Get the payments of an order
+// @Description Get all payment attempts for an order, oldest first. Only the caller who placed the order, or a caller with orders:read, can read them.
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID (UUID)"
+// @Success 200 {object} domain.PaymentListResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /orders/{id}/payments [get]
+func (h *PaymentHandler) GetOrderPayments(c echo.Context) error {
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return domain.NewBadRequestError("Invalid order ID")
+	}
+
+	payments, err := h.paymentService.ListOrderPayments(c.Request().Context(), orderID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Payments retrieved successfully",
+		"data":    payments,
+	})
+}
+
+// RefundPayment godoc
+// @Summary Refund a payment
+// @Description Refund a captured payment in full with the payment gateway. The order is marked refunded.
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param id path string true "Payment ID (UUID)"
+// @Success 200 {object} domain.PaymentResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse "Payment is not captured"
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /payments/{id}/refund [post]
+func (h *PaymentHandler) RefundPayment(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return domain.NewBadRequestError("Invalid payment ID")
+	}
+
+	payment, err := h.paymentService.RefundPayment(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Payment refunded successfully",
+		"data":    payment,
+	})
+}
+
+// PaymentWebhook godoc
+// @Summary Receive a payment provider webhook
+// @Description Apply a payment event sent by the provider. The body must be signed in the X-Payment-Signature header (hex HMAC-SHA256 of the raw body). Events are applied once per event id, so redeliveries are safe.
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param X-Payment-Signature header string true "Hex HMAC-SHA256 of the body"
+// @Param event body domain.PaymentEvent true "Payment event"
+// @Success 200 {object} domain.PaymentResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse "Invalid signature"
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 422 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /payments/webhook [post]
+func (h *PaymentHandler) PaymentWebhook(c echo.Context) error {
+	payload, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return domain.NewBadRequestError("Invalid request body")
+	}
+
+	payment, err := h.paymentService.HandleWebhook(c.Request().Context(), payload, c.Request().Header.Get(HeaderPaymentSignature))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Webhook processed successfully",
+		"data":    payment,
+	})
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/rezajo220/ecommerce/internal/domain"
+	services "github.com/rezajo220/ecommerce/internal/service"
+)
+
+type PriceHandler struct {
+	priceService services.PriceService
+}
+
+func NewPriceHandler(priceService services.PriceService) *PriceHandler {
+	return &PriceHandler{priceService: priceService}
+}
+
+// GetPriceHistory godoc
+// @Summary Get the price history of a product
+// @Description List the prices a product has had, newest first, including scheduled ones (those without applied_at).
+// @Description With at, data is instead the single entry (domain.ProductPrice) in effect at that time, e.g. to reconcile an old invoice. Deleted and purged products keep their history.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID (UUID)"
+// @Param at query string false "Point in time (RFC 3339 or YYYY-MM-DD)"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(10)
+// @Success 200 {object} domain.PriceHistoryResponseWrapper
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse "Product not found, or it had no price at that time"
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /products/{id}/price-history [get]
+func (h *PriceHandler) GetPriceHistory(c echo.Context) error {
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return domain.NewBadRequestError("Invalid product ID")
+	}
+
+	at, err := parseTimeParam(c, "at")
+	if err != nil {
+		return err
+	}
+	if at != nil {
+		price, err := h.priceService.GetPriceAt(c.Request().Context(), productID, *at)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"message": "Price retrieved successfully",
+			"data":    price,
+		})
+	}
+
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+
+	history, err := h.priceService.GetPriceHistory(c.Request().Context(), productID, page, limit)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Price history retrieved successfully",
+		"data":    history,
+	})
+}
+
+// SchedulePrice godoc
+// @Summary Schedule a price change
+// @Description Set the product's price, in its currency, from a future point in time. The new price joins the price history
+// @Description right away and is written to the product once it takes effect.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID (UUID)"
+// @Param price body domain.SchedulePriceRequest true "Price and when it takes effect"
+// @Success 201 {object} domain.ProductPriceResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 422 {object} domain.ValidationErrorResponse "Invalid price, more decimal places than the currency has, or a time that is not in the future"
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /products/{id}/scheduled-prices [post]
+func (h *PriceHandler) SchedulePrice(c echo.Context) error {
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return domain.NewBadRequestError("Invalid product ID")
+	}
+
+	var req domain.SchedulePriceRequest
+	if err := c.Bind(&req); err != nil {
+		return domain.NewBadRequestError("Invalid request body")
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	price, err := h.priceService.SchedulePrice(c.Request().Context(), productID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"message": "Price scheduled successfully",
+		"data":    price,
+	})
+}
+
+// CancelScheduledPrice godoc
+// @Summary Cancel a scheduled price
+// @Description Remove a scheduled price that has not taken effect; the price before it stays in effect instead
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID (UUID)"
+// @Param price_id path string true "Price history entry ID (UUID)"
+// @Success 200 {object} domain.MessageResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse "The price has already taken effect"
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /products/{id}/scheduled-prices/{price_id} [delete]
+func (h *PriceHandler) CancelScheduledPrice(c echo.Context) error {
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return domain.NewBadRequestError("Invalid product ID")
+	}
+	id, err := uuid.Parse(c.Param("price_id"))
+	if err != nil {
+		return domain.NewBadRequestError("Invalid price ID")
+	}
+
+	if err := h.priceService.CancelScheduledPrice(c.Request().Context(), productID, id); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Scheduled price cancelled successfully",
+	})
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
@@ -11,11 +12,13 @@ import (
 )
 
 type ProductHandler struct {
-	productService services.ProductService
+	productService  services.ProductService
+	currencyService services.CurrencyService
+	preconditions   Preconditions
 }
 
-func NewProductHandler(productService services.ProductService) *ProductHandler {
-	return &ProductHandler{productService: productService}
+func NewProductHandler(productService services.ProductService, currencyService services.CurrencyService, preconditions Preconditions) *ProductHandler {
+	return &ProductHandler{productService: productService, currencyService: currencyService, preconditions: preconditions}
 }
 
 // CreateProduct godoc
@@ -26,24 +29,32 @@ func NewProductHandler(productService services.ProductService) *ProductHandler {
 // @Produce json
 // @Param product body domain.CreateProductRequest true "Product information"
 // @Success 201 {object} domain.ProductResponse
+// @Header 201 {string} ETag "Version of the product, for If-Match"
 // @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse "Brand not found"
+// @Failure 422 {object} domain.ValidationErrorResponse
 // @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /products [post]
 func (h *ProductHandler) CreateProduct(c echo.Context) error {
 	var req domain.CreateProductRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "Invalid request body",
-		})
+		return domain.NewBadRequestError("Invalid request body")
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
 	}
 
 	product, err := h.productService.CreateProduct(c.Request().Context(), &req)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": err.Error(),
-		})
+		return err
 	}
 
+	setETag(c, product.Version)
+
 	return c.JSON(http.StatusCreated, map[string]interface{}{
 		"message": "Product created successfully",
 		"data":    product,
@@ -52,13 +63,33 @@ func (h *ProductHandler) CreateProduct(c echo.Context) error {
 
 // GetProducts godoc
 // @Summary Get products with pagination
-// @Description Get a list of products with pagination support
+// @Description Get a list of products with filtering, sorting and pagination support.
+// @Description Passing the cursor parameter (empty for the first page) switches to keyset pagination ordered by newest first.
 // @Tags products
 // @Accept json
 // @Produce json
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page" default(10)
+// @Param cursor query string false "Opaque cursor from next_cursor/prev_cursor; empty for the first page"
+// @Param include_total query bool false "Include the total count in cursor mode" default(false)
+// @Param brand_id query []string false "Brand IDs (repeatable or comma separated)" collectionFormat(multi)
+// @Param category_id query string false "Category ID; includes products in its subcategories"
+// @Param price_min query number false "Minimum price (inclusive)"
+// @Param price_max query number false "Maximum price (inclusive)"
+// @Param in_stock query bool false "Only products with (true) or without (false) stock"
+// @Param name query string false "Case-insensitive product name search"
+// @Param created_after query string false "Created at or after (RFC 3339 or YYYY-MM-DD)"
+// @Param created_before query string false "Created before (RFC 3339 or YYYY-MM-DD)"
+// @Param include query string false "Set to variants to embed variants and a price range in each product" Enums(variants)
+// @Param sort query string false "Comma separated sort fields, prefix with - for descending (product_name, price, qty, created_at, updated_at)" default(-created_at)
+// @Param include_deleted query bool false "Include soft-deleted products; requires products:delete" default(false)
+// @Param currency query string false "Convert prices into this currency; price_min and price_max stay in each product's own currency" Enums(IDR, USD)
+// @Param Accept-Currency header string false "Preferred currencies when the currency parameter is not given, e.g. USD, IDR;q=0.5"
 // @Success 200 {object} domain.ProductListResponseWrapper
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 422 {object} domain.ErrorResponse "No exchange rate into the requested currency"
 // @Failure 500 {object} domain.ErrorResponse
 // @Router /products [get]
 func (h *ProductHandler) GetProducts(c echo.Context) error {
@@ -72,11 +103,30 @@ func (h *ProductHandler) GetProducts(c echo.Context) error {
 		limit = 10
 	}
 
-	response, err := h.productService.ListProducts(c.Request().Context(), page, limit)
+	filter, err := parseProductFilter(c)
+	if err != nil {
+		return err
+	}
+
+	currency, err := requestedCurrency(c)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": err.Error(),
-		})
+		return err
+	}
+
+	var response *domain.ProductListResponse
+	if c.QueryParams().Has("cursor") {
+		response, err = h.listProductsByCursor(c, filter, limit)
+	} else {
+		response, err = h.productService.ListProducts(c.Request().Context(), filter, page, limit)
+	}
+	if err != nil {
+		return err
+	}
+
+	if currency != nil {
+		if err := h.currencyService.ConvertProducts(c.Request().Context(), response.Products, *currency); err != nil {
+			return err
+		}
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
@@ -85,76 +135,357 @@ func (h *ProductHandler) GetProducts(c echo.Context) error {
 	})
 }
 
-// UpdateProduct godoc
-// @Summary Update a product
-// @Description Update an existing product by ID
+func (h *ProductHandler) listProductsByCursor(c echo.Context, filter *domain.ProductFilter, limit int) (*domain.ProductListResponse, error) {
+	if len(filter.Sort) > 0 {
+		return nil, domain.NewBadRequestError("sort is not supported with cursor pagination")
+	}
+
+	var cursor *domain.ProductCursor
+	if raw := c.QueryParam("cursor"); raw != "" {
+		var err error
+		if cursor, err = domain.DecodeProductCursor(raw); err != nil {
+			return nil, err
+		}
+	}
+
+	includeTotal := false
+	if raw := c.QueryParam("include_total"); raw != "" {
+		var err error
+		if includeTotal, err = strconv.ParseBool(raw); err != nil {
+			return nil, domain.NewBadRequestError("Invalid include_total %q", raw)
+		}
+	}
+
+	return h.productService.ListProductsByCursor(c.Request().Context(), filter, cursor, limit, includeTotal)
+}
+
+// SearchProducts godoc
+// @Summary Search products
+// @Description Full-text search over product and brand names, ranked by relevance. Each word matches as a prefix.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param q query string true "Search text"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(10)
+// @Success 200 {object} domain.ProductSearchResponseWrapper
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /products/search [get]
+func (h *ProductHandler) SearchProducts(c echo.Context) error {
+	query := strings.TrimSpace(c.QueryParam("q"))
+	if query == "" {
+		return domain.NewBadRequestError("Query parameter q is required")
+	}
+
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit < 1 {
+		limit = 10
+	}
+
+	response, err := h.productService.SearchProducts(c.Request().Context(), query, page, limit)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Products found successfully",
+		"data":    response,
+	})
+}
+
+// GetProduct godoc
+// @Summary Get a product
+// @Description Get a single product by ID
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID (UUID)"
+// @Param include_deleted query bool false "Also find a soft-deleted product; requires products:delete" default(false)
+// @Param currency query string false "Convert prices into this currency" Enums(IDR, USD)
+// @Param Accept-Currency header string false "Preferred currencies when the currency parameter is not given, e.g. USD, IDR;q=0.5"
+// @Success 200 {object} domain.ProductResponse
+// @Header 200 {string} ETag "Version of the product, for If-Match"
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 422 {object} domain.ErrorResponse "No exchange rate into the requested currency"
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /products/{id} [get]
+func (h *ProductHandler) GetProduct(c echo.Context) error {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return domain.NewBadRequestError("Invalid product ID")
+	}
+
+	includeDeleted, err := parseIncludeDeleted(c)
+	if err != nil {
+		return err
+	}
+
+	currency, err := requestedCurrency(c)
+	if err != nil {
+		return err
+	}
+
+	product, err := h.productService.GetProduct(c.Request().Context(), id, includeDeleted)
+	if err != nil {
+		return err
+	}
+
+	if currency != nil {
+		products := []domain.Product{*product}
+		if err := h.currencyService.ConvertProducts(c.Request().Context(), products, *currency); err != nil {
+			return err
+		}
+		product = &products[0]
+	}
+
+	setETag(c, product.Version)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Product retrieved successfully",
+		"data":    product,
+	})
+}
+
+// ReplaceProduct godoc
+// @Summary Replace a product
+// @Description Replace every field of an existing product by ID
 // @Tags products
 // @Accept json
 // @Produce json
 // @Param id path string true "Product ID (UUID)"
-// @Param product body domain.UpdateProductRequest true "Updated product information"
+// @Param If-Match header string false "ETag from the last read of the product; required unless REQUIRE_IF_MATCH is false"
+// @Param product body domain.ReplaceProductRequest true "Complete product information"
 // @Success 200 {object} domain.ProductResponse
+// @Header 200 {string} ETag "Version of the product, for If-Match"
 // @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
 // @Failure 404 {object} domain.ErrorResponse
+// @Failure 412 {object} domain.ErrorResponse "The product has changed since it was read"
+// @Failure 422 {object} domain.ValidationErrorResponse
+// @Failure 428 {object} domain.ErrorResponse "If-Match header missing"
 // @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /products/{id} [put]
-func (h *ProductHandler) UpdateProduct(c echo.Context) error {
+func (h *ProductHandler) ReplaceProduct(c echo.Context) error {
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "Invalid product ID",
-		})
+		return domain.NewBadRequestError("Invalid product ID")
 	}
 
-	var req domain.UpdateProductRequest
+	version, err := h.preconditions.ExpectedVersion(c)
+	if err != nil {
+		return err
+	}
+
+	var req domain.ReplaceProductRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "Invalid request body",
-		})
+		return domain.NewBadRequestError("Invalid request body")
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
 	}
 
-	product, err := h.productService.UpdateProduct(c.Request().Context(), id, &req)
+	product, err := h.productService.ReplaceProduct(c.Request().Context(), id, &req, version)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": err.Error(),
-		})
+		return err
 	}
 
+	setETag(c, product.Version)
+
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"message": "Product updated successfully",
 		"data":    product,
 	})
 }
 
+// PatchProduct godoc
+// @Summary Partially update a product
+// @Description Apply a JSON merge patch to an existing product; omitted fields are left unchanged and unknown fields are rejected
+// @Tags products
+// @Accept application/merge-patch+json
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID (UUID)"
+// @Param If-Match header string false "ETag from the last read of the product; required unless REQUIRE_IF_MATCH is false"
+// @Param product body domain.UpdateProductRequest true "Fields to change"
+// @Success 200 {object} domain.ProductResponse
+// @Header 200 {string} ETag "Version of the product, for If-Match"
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 412 {object} domain.ErrorResponse "The product has changed since it was read"
+// @Failure 415 {object} domain.ErrorResponse
+// @Failure 422 {object} domain.ValidationErrorResponse
+// @Failure 428 {object} domain.ErrorResponse "If-Match header missing"
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /products/{id} [patch]
+func (h *ProductHandler) PatchProduct(c echo.Context) error {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return domain.NewBadRequestError("Invalid product ID")
+	}
+
+	version, err := h.preconditions.ExpectedVersion(c)
+	if err != nil {
+		return err
+	}
+
+	var req domain.UpdateProductRequest
+	if err := bindMergePatch(c, &req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	product, err := h.productService.UpdateProduct(c.Request().Context(), id, &req, version)
+	if err != nil {
+		return err
+	}
+
+	setETag(c, product.Version)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Product updated successfully",
+		"data":    product,
+	})
+}
+
+// SetProductCategories godoc
+// @Summary Set product categories
+// @Description Replace the set of categories a product is assigned to; an empty list removes all assignments
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID (UUID)"
+// @Param categories body domain.SetProductCategoriesRequest true "Category IDs"
+// @Success 200 {object} domain.ProductResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse "Product or category not found"
+// @Failure 422 {object} domain.ValidationErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /products/{id}/categories [put]
+func (h *ProductHandler) SetProductCategories(c echo.Context) error {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return domain.NewBadRequestError("Invalid product ID")
+	}
+
+	var req domain.SetProductCategoriesRequest
+	if err := c.Bind(&req); err != nil {
+		return domain.NewBadRequestError("Invalid request body")
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	product, err := h.productService.SetProductCategories(c.Request().Context(), id, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Product categories updated successfully",
+		"data":    product,
+	})
+}
+
 // DeleteProduct godoc
 // @Summary Delete a product
-// @Description Delete an existing product by ID
+// @Description Soft delete an existing product by ID; it can be restored until it is purged
 // @Tags products
 // @Accept json
 // @Produce json
 // @Param id path string true "Product ID (UUID)"
+// @Param If-Match header string false "ETag from the last read of the product; required unless REQUIRE_IF_MATCH is false"
 // @Success 200 {object} domain.MessageResponse
 // @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
 // @Failure 404 {object} domain.ErrorResponse
+// @Failure 412 {object} domain.ErrorResponse "The product has changed since it was read"
+// @Failure 428 {object} domain.ErrorResponse "If-Match header missing"
 // @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /products/{id} [delete]
 func (h *ProductHandler) DeleteProduct(c echo.Context) error {
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "Invalid product ID",
-		})
+		return domain.NewBadRequestError("Invalid product ID")
 	}
 
-	if err := h.productService.DeleteProduct(c.Request().Context(), id); err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": err.Error(),
-		})
+	version, err := h.preconditions.ExpectedVersion(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.productService.DeleteProduct(c.Request().Context(), id, version); err != nil {
+		return err
 	}
 
 	return c.JSON(http.StatusOK, map[string]string{
 		"message": "Product deleted successfully",
 	})
 }
+
+// RestoreProduct godoc
+// @Summary Restore a deleted product
+// @Description Undo the soft delete of a product. Its brand must not be deleted.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID (UUID)"
+// @Success 200 {object} domain.ProductResponse
+// @Header 200 {string} ETag "Version of the product, for If-Match"
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse "Product is not deleted or its brand is deleted"
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /products/{id}/restore [post]
+func (h *ProductHandler) RestoreProduct(c echo.Context) error {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return domain.NewBadRequestError("Invalid product ID")
+	}
+
+	product, err := h.productService.RestoreProduct(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	setETag(c, product.Version)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Product restored successfully",
+		"data":    product,
+	})
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/rezajo220/ecommerce/internal/domain"
+)
+
+// parseProductFilter reads the listing query parameters. brand_id may be
+// repeated or comma separated; dates accept RFC 3339 or YYYY-MM-DD.
+func parseProductFilter(c echo.Context) (*domain.ProductFilter, error) {
+	filter := &domain.ProductFilter{}
+	query := c.QueryParams()
+
+	for _, value := range query["brand_id"] {
+		for _, raw := range strings.Split(value, ",") {
+			raw = strings.TrimSpace(raw)
+			if raw == "" {
+				continue
+			}
+			id, err := uuid.Parse(raw)
+			if err != nil {
+				return nil, domain.NewBadRequestError("Invalid brand_id %q", raw)
+			}
+			filter.BrandIDs = append(filter.BrandIDs, id)
+		}
+	}
+
+	if raw := c.QueryParam("category_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return nil, domain.NewBadRequestError("Invalid category_id %q", raw)
+		}
+		filter.CategoryID = &id
+	}
+
+	var err error
+	if filter.PriceMin, err = parseDecimalParam(c, "price_min"); err != nil {
+		return nil, err
+	}
+	if filter.PriceMax, err = parseDecimalParam(c, "price_max"); err != nil {
+		return nil, err
+	}
+	if filter.PriceMin != nil && filter.PriceMax != nil && filter.PriceMin.Cmp(*filter.PriceMax) > 0 {
+		return nil, domain.NewBadRequestError("price_min must not be greater than price_max")
+	}
+
+	if raw := c.QueryParam("in_stock"); raw != "" {
+		inStock, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, domain.NewBadRequestError("Invalid in_stock %q", raw)
+		}
+		filter.InStock = &inStock
+	}
+
+	filter.Name = strings.TrimSpace(c.QueryParam("name"))
+
+	if filter.CreatedAfter, err = parseTimeParam(c, "created_after"); err != nil {
+		return nil, err
+	}
+	if filter.CreatedBefore, err = parseTimeParam(c, "created_before"); err != nil {
+		return nil, err
+	}
+
+	for _, include := range strings.Split(c.QueryParam("include"), ",") {
+		switch strings.TrimSpace(include) {
+		case "":
+		case "variants":
+			filter.IncludeVariants = true
+		default:
+			return nil, domain.NewBadRequestError("Invalid include %q", include)
+		}
+	}
+
+	if filter.IncludeDeleted, err = parseIncludeDeleted(c); err != nil {
+		return nil, err
+	}
+
+	if raw := c.QueryParam("sort"); raw != "" {
+		if filter.Sort, err = domain.ParseProductSort(raw); err != nil {
+			return nil, err
+		}
+	}
+
+	return filter, nil
+}
+
+func parseDecimalParam(c echo.Context, name string) (*domain.Decimal, error) {
+	raw := c.QueryParam(name)
+	if raw == "" {
+		return nil, nil
+	}
+	value, err := domain.ParseDecimal(raw)
+	if err != nil {
+		return nil, domain.NewBadRequestError("Invalid %s %q", name, raw)
+	}
+	return &value, nil
+}
+
+func parseTimeParam(c echo.Context, name string) (*time.Time, error) {
+	raw := c.QueryParam(name)
+	if raw == "" {
+		return nil, nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, domain.NewBadRequestError("Invalid %s %q, expected RFC 3339 or YYYY-MM-DD", name, raw)
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rezajo220/ecommerce/internal/domain"
+	"github.com/rezajo220/ecommerce/internal/ratelimit"
+)
+
+// Rate limit response headers, following the IETF RateLimit header fields
+// draft.
+const (
+	HeaderRateLimitLimit     = "RateLimit-Limit"
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	HeaderRateLimitReset     = "RateLimit-Reset"
+	HeaderRateLimitPolicy    = "RateLimit-Policy"
+)
+
+// rateLimitExempt lists path prefixes that are never limited, so health
+// checks and the documentation keep working for a throttled client.
+var rateLimitExempt = []string{"/health", "/swagger/"}
+
+// IPRateLimit caps the requests from one address at limit, whatever
+// credentials they carry. It runs before AccessGuard.Identify, which may hit
+// the database to check a token or API key. Only rejections set headers; the
+// quota clients see is the one RateLimit applies after identifying them.
+func IPRateLimit(store ratelimit.Store, limit ratelimit.Limit) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if rateLimitExempted(c.Request().URL.Path) {
+				return next(c)
+			}
+
+			result, err := store.Take(c.Request().Context(), "ip|"+c.RealIP(), limit)
+			if err != nil {
+				c.Logger().Error(err)
+				return next(c)
+			}
+			if !result.Allowed {
+				c.Response().Header().Set(echo.HeaderRetryAfter, ratelimit.Seconds(result.RetryAfter))
+				return domain.ErrRateLimited
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// RateLimit limits requests per client with the buckets in store. Clients are
+// identified by API key, then user, then IP address, so it must run after
+// AccessGuard.Identify. When the store fails, requests are let through.
+func RateLimit(store ratelimit.Store, policy ratelimit.Policy) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			path := c.Request().URL.Path
+			if rateLimitExempted(path) {
+				return next(c)
+			}
+
+			group, limit := policy.Resolve(c.Request().Method, path)
+			key := group + "|" + rateLimitClient(c)
+
+			result, err := store.Take(c.Request().Context(), key, limit)
+			if err != nil {
+				c.Logger().Error(err)
+				return next(c)
+			}
+
+			header := c.Response().Header()
+			header.Set(HeaderRateLimitLimit, strconv.Itoa(result.Limit))
+			header.Set(HeaderRateLimitRemaining, strconv.Itoa(result.Remaining))
+			header.Set(HeaderRateLimitReset, ratelimit.Seconds(result.ResetAfter))
+			header.Set(HeaderRateLimitPolicy, strconv.Itoa(limit.Requests)+";w="+ratelimit.Seconds(limit.Period))
+
+			if !result.Allowed {
+				header.Set(echo.HeaderRetryAfter, ratelimit.Seconds(result.RetryAfter))
+				return domain.ErrRateLimited
+			}
+
+			return next(c)
+		}
+	}
+}
+
+func rateLimitClient(c echo.Context) string {
+	user := domain.AuthUserFromContext(c.Request().Context())
+	switch {
+	case user == nil:
+		return "ip:" + c.RealIP()
+	case user.APIKey != nil:
+		return "api-key:" + user.ID.String()
+	default:
+		return "user:" + user.ID.String()
+	}
+}
+
+func rateLimitExempted(path string) bool {
+	for _, prefix := range rateLimitExempt {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
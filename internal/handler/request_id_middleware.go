@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/rezajo220/ecommerce/internal/domain"
+)
+
+// maxRequestIDLength bounds client-supplied X-Request-ID values, which end up
+// in logs and the audit log.
+const maxRequestIDLength = 128
+
+// RequestID assigns every request an ID, reusing a client-supplied
+// X-Request-ID when it is short enough. The ID is echoed in the response
+// header and put on the request context for domain.RequestIDFromContext.
+func RequestID() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			requestID := c.Request().Header.Get(echo.HeaderXRequestID)
+			if requestID == "" || len(requestID) > maxRequestIDLength {
+				requestID = uuid.NewString()
+				c.Request().Header.Set(echo.HeaderXRequestID, requestID)
+			}
+			c.Response().Header().Set(echo.HeaderXRequestID, requestID)
+
+			ctx := domain.WithRequestID(c.Request().Context(), requestID)
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/rezajo220/ecommerce/internal/domain"
+	services "github.com/rezajo220/ecommerce/internal/service"
+)
+
+type ReservationHandler struct {
+	reservationService services.ReservationService
+}
+
+func NewReservationHandler(reservationService services.ReservationService) *ReservationHandler {
+	return &ReservationHandler{reservationService: reservationService}
+}
+
+// CreateReservation godoc
+// @Summary Reserve stock
+// @Description Hold units of a product for a holder until the reservation expires (ttl_seconds, 15 minutes by default). Held units are subtracted from available_qty.
+// @Tags reservations
+// @Accept json
+// @Produce json
+// @Param reservation body domain.CreateReservationRequest true "Reservation information"
+// @Success 201 {object} domain.ReservationResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse "Insufficient stock"
+// @Failure 422 {object} domain.ValidationErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /reservations/ [post]
+func (h *ReservationHandler) CreateReservation(c echo.Context) error {
+	var req domain.CreateReservationRequest
+	if err := c.Bind(&req); err != nil {
+		return domain.NewBadRequestError("Invalid request body")
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	reservation, err := h.reservationService.Reserve(c.Request().Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"message": "Stock reserved successfully",
+		"data":    reservation,
+	})
+}
+
+// GetReservation godoc
+// @Summary Get a reservation
+// @Description Get a stock reservation by ID
+// @Tags reservations
+// @Accept json
+// @Produce json
+// @Param id path string true "Reservation ID (UUID)"
+// @Success 200 {object} domain.ReservationResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /reservations/{id} [get]
+func (h *ReservationHandler) GetReservation(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return domain.NewBadRequestError("Invalid reservation ID")
+	}
+
+	reservation, err := h.reservationService.GetReservation(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Reservation retrieved successfully",
+		"data":    reservation,
+	})
+}
+
+// ConfirmReservation godoc
+// @Summary Confirm a reservation
+// @Description Turn the reserved units into a sale, taking them out of stock
+// @Tags reservations
+// @Accept json
+// @Produce json
+// @Param id path string true "Reservation ID (UUID)"
+// @Success 200 {object} domain.ReservationResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse "Reservation is no longer active"
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /reservations/{id}/confirm [post]
+func (h *ReservationHandler) ConfirmReservation(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return domain.NewBadRequestError("Invalid reservation ID")
+	}
+
+	reservation, err := h.reservationService.ConfirmReservation(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Reservation confirmed successfully",
+		"data":    reservation,
+	})
+}
+
+// ReleaseReservation godoc
+// @Summary Release a reservation
+// @Description Give the reserved units back to available stock
+// @Tags reservations
+// @Accept json
+// @Produce json
+// @Param id path string true "Reservation ID (UUID)"
+// @Success 200 {object} domain.ReservationResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse "Reservation is no longer active"
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /reservations/{id}/release [post]
+func (h *ReservationHandler) ReleaseReservation(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return domain.NewBadRequestError("Invalid reservation ID")
+	}
+
+	reservation, err := h.reservationService.ReleaseReservation(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Reservation released successfully",
+		"data":    reservation,
+	})
+}
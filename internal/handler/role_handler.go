@@ -0,0 +1,289 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/rezajo220/ecommerce/internal/domain"
+	services "github.com/rezajo220/ecommerce/internal/service"
+)
+
+type RoleHandler struct {
+	accessService services.AccessService
+}
+
+func NewRoleHandler(accessService services.AccessService) *RoleHandler {
+	return &RoleHandler{accessService: accessService}
+}
+
+// GetPermissions godoc
+// @Summary List permissions
+// @Description List every permission that can be granted to a role
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Success 200 {object} domain.PermissionListResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/permissions [get]
+func (h *RoleHandler) GetPermissions(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Permissions retrieved successfully",
+		"data":    h.accessService.ListPermissions(c.Request().Context()),
+	})
+}
+
+// CreateRole godoc
+// @Summary Create a role
+// @Description Create a role with a set of permissions
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param role body domain.CreateRoleRequest true "Role information"
+// @Success 201 {object} domain.RoleResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse "Role name already taken"
+// @Failure 422 {object} domain.ValidationErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/roles [post]
+func (h *RoleHandler) CreateRole(c echo.Context) error {
+	var req domain.CreateRoleRequest
+	if err := c.Bind(&req); err != nil {
+		return domain.NewBadRequestError("Invalid request body")
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	role, err := h.accessService.CreateRole(c.Request().Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"message": "Role created successfully",
+		"data":    role,
+	})
+}
+
+// GetRoles godoc
+// @Summary List roles
+// @Description List all roles with their permissions
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Success 200 {object} domain.RoleListResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/roles [get]
+func (h *RoleHandler) GetRoles(c echo.Context) error {
+	roles, err := h.accessService.ListRoles(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Roles retrieved successfully",
+		"data":    roles,
+	})
+}
+
+// GetRole godoc
+// @Summary Get a role
+// @Description Get a single role and its permissions by ID
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Role ID (UUID)"
+// @Success 200 {object} domain.RoleResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/roles/{id} [get]
+func (h *RoleHandler) GetRole(c echo.Context) error {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return domain.NewBadRequestError("Invalid role ID")
+	}
+
+	role, err := h.accessService.GetRole(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Role retrieved successfully",
+		"data":    role,
+	})
+}
+
+// ReplaceRole godoc
+// @Summary Replace a role
+// @Description Replace the description and permissions of a role. The admin role cannot be changed.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Role ID (UUID)"
+// @Param role body domain.ReplaceRoleRequest true "Role description and permissions"
+// @Success 200 {object} domain.RoleResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse "The admin role cannot be changed"
+// @Failure 422 {object} domain.ValidationErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/roles/{id} [put]
+func (h *RoleHandler) ReplaceRole(c echo.Context) error {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return domain.NewBadRequestError("Invalid role ID")
+	}
+
+	var req domain.ReplaceRoleRequest
+	if err := c.Bind(&req); err != nil {
+		return domain.NewBadRequestError("Invalid request body")
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	role, err := h.accessService.ReplaceRole(c.Request().Context(), id, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Role updated successfully",
+		"data":    role,
+	})
+}
+
+// DeleteRole godoc
+// @Summary Delete a role
+// @Description Delete a role and remove it from every user. The admin role cannot be deleted.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Role ID (UUID)"
+// @Success 200 {object} domain.MessageResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse "The admin role cannot be deleted"
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/roles/{id} [delete]
+func (h *RoleHandler) DeleteRole(c echo.Context) error {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return domain.NewBadRequestError("Invalid role ID")
+	}
+
+	if err := h.accessService.DeleteRole(c.Request().Context(), id); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Role deleted successfully",
+	})
+}
+
+// GetUserRoles godoc
+// @Summary Get a user's roles
+// @Description List the roles assigned to a user
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID (UUID)"
+// @Success 200 {object} domain.RoleListResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/users/{id}/roles [get]
+func (h *RoleHandler) GetUserRoles(c echo.Context) error {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return domain.NewBadRequestError("Invalid user ID")
+	}
+
+	roles, err := h.accessService.GetUserRoles(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "User roles retrieved successfully",
+		"data":    roles,
+	})
+}
+
+// SetUserRoles godoc
+// @Summary Set a user's roles
+// @Description Replace all role assignments of a user; an empty list removes them
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID (UUID)"
+// @Param roles body domain.SetUserRolesRequest true "Role IDs"
+// @Success 200 {object} domain.RoleListResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse "User or role not found"
+// @Failure 422 {object} domain.ValidationErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/users/{id}/roles [put]
+func (h *RoleHandler) SetUserRoles(c echo.Context) error {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return domain.NewBadRequestError("Invalid user ID")
+	}
+
+	var req domain.SetUserRolesRequest
+	if err := c.Bind(&req); err != nil {
+		return domain.NewBadRequestError("Invalid request body")
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	roles, err := h.accessService.SetUserRoles(c.Request().Context(), id, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "User roles updated successfully",
+		"data":    roles,
+	})
+}
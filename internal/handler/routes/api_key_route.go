@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/rezajo220/ecommerce/internal/domain"
+	handlers "github.com/rezajo220/ecommerce/internal/handler"
+)
+
+func SetupAPIKeyRoutes(e *echo.Echo, apiKeyHandler *handlers.APIKeyHandler, guard *handlers.AccessGuard) {
+	api := e.Group("/v1/admin/api-keys", guard.Require(domain.PermAPIKeysManage))
+
+	api.POST("/", apiKeyHandler.CreateAPIKey)
+	api.GET("/", apiKeyHandler.GetAPIKeys)
+	api.POST("/:id/rotate", apiKeyHandler.RotateAPIKey)
+	api.POST("/:id/revoke", apiKeyHandler.RevokeAPIKey)
+}
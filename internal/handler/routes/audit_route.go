@@ -0,0 +1,11 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/rezajo220/ecommerce/internal/domain"
+	handlers "github.com/rezajo220/ecommerce/internal/handler"
+)
+
+func SetupAuditRoutes(e *echo.Echo, auditHandler *handlers.AuditHandler, guard *handlers.AccessGuard) {
+	e.GET("/v1/audit", auditHandler.GetAuditLog, guard.Require(domain.PermAuditRead))
+}
@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+	handlers "github.com/rezajo220/ecommerce/internal/handler"
+)
+
+func SetupAuthRoutes(e *echo.Echo, authHandler *handlers.AuthHandler) {
+	api := e.Group("/v1/auth")
+
+	api.POST("/login", authHandler.Login)
+	api.POST("/refresh", authHandler.Refresh)
+	api.POST("/logout", authHandler.Logout)
+}
@@ -2,13 +2,18 @@ package routes
 
 import (
 	"github.com/labstack/echo/v4"
+	"github.com/rezajo220/ecommerce/internal/domain"
 	handlers "github.com/rezajo220/ecommerce/internal/handler"
 )
 
-func SetupBrandRoutes(e *echo.Echo, brandHandler *handlers.BrandHandler) {
+func SetupBrandRoutes(e *echo.Echo, brandHandler *handlers.BrandHandler, guard *handlers.AccessGuard) {
 	api := e.Group("/v1/brands")
 
-	api.POST("/", brandHandler.CreateBrand)
-	api.GET("/", brandHandler.GetBrands)
-	api.DELETE("/:id", brandHandler.DeleteBrand)
+	api.POST("/", brandHandler.CreateBrand, guard.Require(domain.PermBrandsWrite))
+	api.GET("/", brandHandler.GetBrands, guard.RequireWhen(handlers.IncludesDeleted, domain.PermBrandsDelete))
+	api.GET("/:id", brandHandler.GetBrand, guard.RequireWhen(handlers.IncludesDeleted, domain.PermBrandsDelete))
+	api.PUT("/:id", brandHandler.ReplaceBrand, guard.Require(domain.PermBrandsWrite))
+	api.PATCH("/:id", brandHandler.PatchBrand, guard.Require(domain.PermBrandsWrite))
+	api.DELETE("/:id", brandHandler.DeleteBrand, guard.Require(domain.PermBrandsDelete))
+	api.POST("/:id/restore", brandHandler.RestoreBrand, guard.Require(domain.PermBrandsDelete))
 }
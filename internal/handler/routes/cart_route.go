@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+	handlers "github.com/rezajo220/ecommerce/internal/handler"
+)
+
+// SetupCartRoutes requires a caller on every cart route; a cart can only be
+// seen and changed by the caller that created it.
+func SetupCartRoutes(e *echo.Echo, cartHandler *handlers.CartHandler, guard *handlers.AccessGuard) {
+	api := e.Group("/v1/carts", guard.Authenticate)
+
+	api.POST("/", cartHandler.CreateCart)
+	api.GET("/:id", cartHandler.GetCart)
+	api.POST("/:id/items", cartHandler.AddCartItem)
+	api.PUT("/:id/items/:item_id", cartHandler.UpdateCartItem)
+	api.DELETE("/:id/items/:item_id", cartHandler.RemoveCartItem)
+}
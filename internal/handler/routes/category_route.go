@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/rezajo220/ecommerce/internal/domain"
+	handlers "github.com/rezajo220/ecommerce/internal/handler"
+)
+
+func SetupCategoryRoutes(e *echo.Echo, categoryHandler *handlers.CategoryHandler, guard *handlers.AccessGuard) {
+	api := e.Group("/v1/categories")
+
+	api.POST("/", categoryHandler.CreateCategory, guard.Require(domain.PermCategoriesWrite))
+	api.GET("/", categoryHandler.GetCategories)
+	api.GET("/:id", categoryHandler.GetCategory)
+	api.PUT("/:id", categoryHandler.ReplaceCategory, guard.Require(domain.PermCategoriesWrite))
+	api.DELETE("/:id", categoryHandler.DeleteCategory, guard.Require(domain.PermCategoriesDelete))
+	api.GET("/:id/products", categoryHandler.GetCategoryProducts)
+}
@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/rezajo220/ecommerce/internal/domain"
+	handlers "github.com/rezajo220/ecommerce/internal/handler"
+)
+
+func SetupCurrencyRoutes(e *echo.Echo, currencyHandler *handlers.CurrencyHandler, guard *handlers.AccessGuard) {
+	api := e.Group("/v1/admin/exchange-rates", guard.Require(domain.PermExchangeRatesManage))
+
+	api.GET("/", currencyHandler.GetExchangeRates)
+	api.POST("/import", currencyHandler.ImportExchangeRates)
+	api.PUT("/:base/:quote", currencyHandler.SetExchangeRate)
+	api.DELETE("/:base/:quote", currencyHandler.DeleteExchangeRate)
+
+	prices := e.Group("/v1/products/:id/prices")
+
+	prices.GET("/", currencyHandler.GetProductPrices)
+	prices.PUT("/:currency", currencyHandler.SetProductPrice, guard.Require(domain.PermProductsWrite))
+	prices.DELETE("/:currency", currencyHandler.DeleteProductPrice, guard.Require(domain.PermProductsWrite))
+}
@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/rezajo220/ecommerce/internal/domain"
+	handlers "github.com/rezajo220/ecommerce/internal/handler"
+)
+
+func SetupInventoryRoutes(e *echo.Echo, inventoryHandler *handlers.InventoryHandler, guard *handlers.AccessGuard) {
+	api := e.Group("/v1/products/:id")
+
+	api.POST("/stock-adjustments", inventoryHandler.AdjustStock, guard.Require(domain.PermStockAdjust))
+	api.GET("/stock-history", inventoryHandler.GetStockHistory, guard.Require(domain.PermStockRead))
+
+	e.GET("/v1/inventory/reconciliation", inventoryHandler.ReconcileStock, guard.Require(domain.PermStockRead))
+}
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/rezajo220/ecommerce/internal/domain"
+	handlers "github.com/rezajo220/ecommerce/internal/handler"
+)
+
+func SetupOrderRoutes(e *echo.Echo, orderHandler *handlers.OrderHandler, guard *handlers.AccessGuard) {
+	e.POST("/v1/checkout", orderHandler.Checkout, guard.Authenticate)
+
+	api := e.Group("/v1/orders")
+
+	api.GET("/", orderHandler.GetOrders, guard.Require(domain.PermOrdersRead))
+	api.GET("/:id", orderHandler.GetOrder, guard.Authenticate)
+	api.PUT("/:id/status", orderHandler.UpdateOrderStatus, guard.Require(domain.PermOrdersWrite))
+}
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/rezajo220/ecommerce/internal/domain"
+	handlers "github.com/rezajo220/ecommerce/internal/handler"
+)
+
+func SetupPaymentRoutes(e *echo.Echo, paymentHandler *handlers.PaymentHandler, guard *handlers.AccessGuard) {
+	e.POST("/v1/orders/:id/payments", paymentHandler.PayOrder, guard.Authenticate)
+	e.GET("/v1/orders/:id/payments", paymentHandler.GetOrderPayments, guard.Authenticate)
+
+	api := e.Group("/v1/payments")
+
+	api.POST("/webhook", paymentHandler.PaymentWebhook)
+	api.POST("/:id/refund", paymentHandler.RefundPayment, guard.Require(domain.PermPaymentsRefund))
+}
@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/rezajo220/ecommerce/internal/domain"
+	handlers "github.com/rezajo220/ecommerce/internal/handler"
+)
+
+func SetupPriceRoutes(e *echo.Echo, priceHandler *handlers.PriceHandler, guard *handlers.AccessGuard) {
+	api := e.Group("/v1/products/:id")
+
+	api.GET("/price-history", priceHandler.GetPriceHistory)
+	api.POST("/scheduled-prices", priceHandler.SchedulePrice, guard.Require(domain.PermProductsWrite))
+	api.DELETE("/scheduled-prices/:price_id", priceHandler.CancelScheduledPrice, guard.Require(domain.PermProductsWrite))
+}
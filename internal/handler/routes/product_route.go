@@ -2,14 +2,20 @@ package routes
 
 import (
 	"github.com/labstack/echo/v4"
+	"github.com/rezajo220/ecommerce/internal/domain"
 	handlers "github.com/rezajo220/ecommerce/internal/handler"
 )
 
-func SetupProductRoutes(e *echo.Echo, productHandler *handlers.ProductHandler) {
+func SetupProductRoutes(e *echo.Echo, productHandler *handlers.ProductHandler, guard *handlers.AccessGuard) {
 	api := e.Group("/v1/products")
 
-	api.POST("/", productHandler.CreateProduct)
-	api.GET("/", productHandler.GetProducts)
-	api.PUT("/:id", productHandler.UpdateProduct)
-	api.DELETE("/:id", productHandler.DeleteProduct)
+	api.POST("/", productHandler.CreateProduct, guard.Require(domain.PermProductsWrite))
+	api.GET("/", productHandler.GetProducts, guard.RequireWhen(handlers.IncludesDeleted, domain.PermProductsDelete))
+	api.GET("/search", productHandler.SearchProducts)
+	api.GET("/:id", productHandler.GetProduct, guard.RequireWhen(handlers.IncludesDeleted, domain.PermProductsDelete))
+	api.PUT("/:id", productHandler.ReplaceProduct, guard.Require(domain.PermProductsWrite))
+	api.PATCH("/:id", productHandler.PatchProduct, guard.Require(domain.PermProductsWrite))
+	api.DELETE("/:id", productHandler.DeleteProduct, guard.Require(domain.PermProductsDelete))
+	api.POST("/:id/restore", productHandler.RestoreProduct, guard.Require(domain.PermProductsDelete))
+	api.PUT("/:id/categories", productHandler.SetProductCategories, guard.Require(domain.PermProductsWrite))
 }
@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/rezajo220/ecommerce/internal/domain"
+	handlers "github.com/rezajo220/ecommerce/internal/handler"
+)
+
+func SetupReservationRoutes(e *echo.Echo, reservationHandler *handlers.ReservationHandler, guard *handlers.AccessGuard) {
+	api := e.Group("/v1/reservations")
+
+	api.POST("/", reservationHandler.CreateReservation, guard.Require(domain.PermReservationsWrite))
+	api.GET("/:id", reservationHandler.GetReservation)
+	api.POST("/:id/confirm", reservationHandler.ConfirmReservation, guard.Require(domain.PermReservationsWrite))
+	api.POST("/:id/release", reservationHandler.ReleaseReservation, guard.Require(domain.PermReservationsWrite))
+}
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/rezajo220/ecommerce/internal/domain"
+	handlers "github.com/rezajo220/ecommerce/internal/handler"
+)
+
+func SetupRoleRoutes(e *echo.Echo, roleHandler *handlers.RoleHandler, guard *handlers.AccessGuard) {
+	api := e.Group("/v1/admin", guard.Require(domain.PermRolesManage))
+
+	api.GET("/permissions", roleHandler.GetPermissions)
+	api.POST("/roles/", roleHandler.CreateRole)
+	api.GET("/roles/", roleHandler.GetRoles)
+	api.GET("/roles/:id", roleHandler.GetRole)
+	api.PUT("/roles/:id", roleHandler.ReplaceRole)
+	api.DELETE("/roles/:id", roleHandler.DeleteRole)
+	api.GET("/users/:id/roles", roleHandler.GetUserRoles)
+	api.PUT("/users/:id/roles", roleHandler.SetUserRoles)
+}
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/rezajo220/ecommerce/internal/domain"
+	handlers "github.com/rezajo220/ecommerce/internal/handler"
+)
+
+func SetupVariantRoutes(e *echo.Echo, variantHandler *handlers.VariantHandler, guard *handlers.AccessGuard) {
+	api := e.Group("/v1/products/:id/variants")
+
+	api.POST("/", variantHandler.CreateVariant, guard.Require(domain.PermProductsWrite))
+	api.GET("/", variantHandler.GetVariants)
+	api.PATCH("/:variant_id", variantHandler.PatchVariant, guard.Require(domain.PermProductsWrite))
+	api.DELETE("/:variant_id", variantHandler.DeleteVariant, guard.Require(domain.PermProductsDelete))
+
+	e.GET("/v1/skus/:sku", variantHandler.GetVariantBySKU)
+}
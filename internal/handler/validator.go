@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/rezajo220/ecommerce/internal/domain"
+)
+
+// RequestValidator implements echo.Validator on top of go-playground/validator
+// using the `validate` struct tags declared on the domain request types.
+type RequestValidator struct {
+	validate *validator.Validate
+}
+
+func NewRequestValidator() *RequestValidator {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.RegisterTagNameFunc(jsonFieldName)
+	v.RegisterCustomTypeFunc(decimalValue, domain.Decimal{})
+	return &RequestValidator{validate: v}
+}
+
+func (rv *RequestValidator) Validate(i interface{}) error {
+	err := rv.validate.Struct(i)
+	if err == nil {
+		return nil
+	}
+
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return err
+	}
+
+	fields := make(domain.ValidationErrors, 0, len(verrs))
+	for _, fe := range verrs {
+		fields = append(fields, domain.FieldError{
+			Field:   fieldPath(fe),
+			Rule:    fe.Tag(),
+			Param:   fe.Param(),
+			Message: fieldMessage(fe),
+		})
+	}
+	return fields
+}
+
+// decimalValue lets numeric rules such as gt=0 apply to domain.Decimal.
+func decimalValue(field reflect.Value) interface{} {
+	return field.Interface().(domain.Decimal).Float64()
+}
+
+func jsonFieldName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// fieldPath strips the top-level struct name from the namespace, so
+// "CreateProductRequest.price" is reported as "price".
+func fieldPath(fe validator.FieldError) string {
+	ns := fe.Namespace()
+	if i := strings.Index(ns, "."); i >= 0 {
+		return ns[i+1:]
+	}
+	return fe.Field()
+}
+
+func fieldMessage(fe validator.FieldError) string {
+	field := fieldPath(fe)
+	switch fe.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", field)
+	case "gt":
+		return fmt.Sprintf("%s must be greater than %s", field, fe.Param())
+	case "gte":
+		return fmt.Sprintf("%s must be greater than or equal to %s", field, fe.Param())
+	case "lt":
+		return fmt.Sprintf("%s must be less than %s", field, fe.Param())
+	case "lte":
+		return fmt.Sprintf("%s must be less than or equal to %s", field, fe.Param())
+	case "min":
+		return fmt.Sprintf("%s must be at least %s", field, fe.Param())
+	case "max":
+		return fmt.Sprintf("%s must be at most %s", field, fe.Param())
+	case "oneof":
+		return fmt.Sprintf("%s must be one of [%s]", field, fe.Param())
+	case "email":
+		return fmt.Sprintf("%s must be a valid email address", field)
+	}
+	return fmt.Sprintf("%s failed the %s rule", field, fe.Tag())
+}
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/rezajo220/ecommerce/internal/domain"
+	services "github.com/rezajo220/ecommerce/internal/service"
+)
+
+type VariantHandler struct {
+	variantService services.VariantService
+}
+
+func NewVariantHandler(variantService services.VariantService) *VariantHandler {
+	return &VariantHandler{variantService: variantService}
+}
+
+// CreateVariant godoc
+// @Summary Create a product variant
+// @Description Create a sellable variant (SKU) under a product
+// @Tags variants
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID (UUID)"
+// @Param variant body domain.CreateVariantRequest true "Variant information"
+// @Success 201 {object} domain.VariantResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse "SKU already exists"
+// @Failure 422 {object} domain.ValidationErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /products/{id}/variants [post]
+func (h *VariantHandler) CreateVariant(c echo.Context) error {
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return domain.NewBadRequestError("Invalid product ID")
+	}
+
+	var req domain.CreateVariantRequest
+	if err := c.Bind(&req); err != nil {
+		return domain.NewBadRequestError("Invalid request body")
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	variant, err := h.variantService.CreateVariant(c.Request().Context(), productID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"message": "Variant created successfully",
+		"data":    variant,
+	})
+}
+
+// GetVariants godoc
+// @Summary Get product variants
+// @Description Get all variants of a product
+// @Tags variants
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID (UUID)"
+// @Success 200 {object} domain.VariantListResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /products/{id}/variants [get]
+func (h *VariantHandler) GetVariants(c echo.Context) error {
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return domain.NewBadRequestError("Invalid product ID")
+	}
+
+	variants, err := h.variantService.ListVariants(c.Request().Context(), productID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Variants retrieved successfully",
+		"data":    variants,
+	})
+}
+
+// PatchVariant godoc
+// @Summary Partially update a product variant
+// @Description Apply a JSON merge patch to a variant; omitted fields are left unchanged
+// @Tags variants
+// @Accept application/merge-patch+json
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID (UUID)"
+// @Param variant_id path string true "Variant ID (UUID)"
+// @Param variant body domain.UpdateVariantRequest true "Fields to change"
+// @Success 200 {object} domain.VariantResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse "SKU already exists"
+// @Failure 415 {object} domain.ErrorResponse
+// @Failure 422 {object} domain.ValidationErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /products/{id}/variants/{variant_id} [patch]
+func (h *VariantHandler) PatchVariant(c echo.Context) error {
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return domain.NewBadRequestError("Invalid product ID")
+	}
+	id, err := uuid.Parse(c.Param("variant_id"))
+	if err != nil {
+		return domain.NewBadRequestError("Invalid variant ID")
+	}
+
+	var req domain.UpdateVariantRequest
+	if err := bindMergePatch(c, &req); err != nil {
+		return err
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	variant, err := h.variantService.UpdateVariant(c.Request().Context(), productID, id, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Variant updated successfully",
+		"data":    variant,
+	})
+}
+
+// DeleteVariant godoc
+// @Summary Delete a product variant
+// @Description Delete a variant of a product
+// @Tags variants
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID (UUID)"
+// @Param variant_id path string true "Variant ID (UUID)"
+// @Success 200 {object} domain.MessageResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /products/{id}/variants/{variant_id} [delete]
+func (h *VariantHandler) DeleteVariant(c echo.Context) error {
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return domain.NewBadRequestError("Invalid product ID")
+	}
+	id, err := uuid.Parse(c.Param("variant_id"))
+	if err != nil {
+		return domain.NewBadRequestError("Invalid variant ID")
+	}
+
+	if err := h.variantService.DeleteVariant(c.Request().Context(), productID, id); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Variant deleted successfully",
+	})
+}
+
+// GetVariantBySKU godoc
+// @Summary Look up a variant by SKU
+// @Description Get a single variant by its SKU (case-insensitive)
+// @Tags variants
+// @Accept json
+// @Produce json
+// @Param sku path string true "SKU"
+// @Success 200 {object} domain.VariantResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /skus/{sku} [get]
+func (h *VariantHandler) GetVariantBySKU(c echo.Context) error {
+	variant, err := h.variantService.GetVariantBySKU(c.Request().Context(), c.Param("sku"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Variant retrieved successfully",
+		"data":    variant,
+	})
+}
@@ -0,0 +1,291 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// advisoryLockID serialises migrations when several instances start with
+// MIGRATE_ON_START at the same time.
+const advisoryLockID = 7245193021
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+type Status struct {
+	Version   int64      `db:"version"`
+	Name      string     `db:"name"`
+	AppliedAt *time.Time `db:"applied_at"`
+}
+
+func (s Status) Applied() bool {
+	return s.AppliedAt != nil
+}
+
+type Migrator struct {
+	db         *sqlx.DB
+	migrations []Migration
+}
+
+func New(db *sqlx.DB, fsys fs.FS) (*Migrator, error) {
+	migrations, err := load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+func load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version %q: %w", entry.Name(), err)
+		}
+
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration version %d has conflicting names %q and %q", version, m.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+func (m *Migrator) ensureTable(ctx context.Context, conn sqlx.ExecerContext) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`
+	_, err := conn.ExecContext(ctx, query)
+	return err
+}
+
+func (m *Migrator) applied(ctx context.Context, conn sqlx.QueryerContext) (map[int64]time.Time, error) {
+	var rows []Status
+	query := `SELECT version, name, applied_at FROM schema_migrations ORDER BY version`
+	if err := sqlx.SelectContext(ctx, conn, &rows, query); err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int64]time.Time, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = *row.AppliedAt
+	}
+	return applied, nil
+}
+
+// withLock runs fn on a dedicated connection holding the migration advisory lock.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sqlx.Conn) error) error {
+	conn, err := m.db.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, advisoryLockID); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, advisoryLockID)
+
+	if err := m.ensureTable(ctx, conn); err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	return fn(conn)
+}
+
+// Up applies every pending migration in version order, each in its own transaction.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var done []Migration
+	err := m.withLock(ctx, func(conn *sqlx.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+			insert := `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`
+			if err := runInTx(ctx, conn, migration.Up, insert, migration.Version, migration.Name); err != nil {
+				return fmt.Errorf("migration %d_%s failed: %w", migration.Version, migration.Name, err)
+			}
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down rolls back the latest steps applied migrations.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var done []Migration
+	err := m.withLock(ctx, func(conn *sqlx.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+			if migration.Down == "" {
+				return fmt.Errorf("migration %d_%s has no down file", migration.Version, migration.Name)
+			}
+			remove := `DELETE FROM schema_migrations WHERE version = $1`
+			if err := runInTx(ctx, conn, migration.Down, remove, migration.Version); err != nil {
+				return fmt.Errorf("rollback of %d_%s failed: %w", migration.Version, migration.Name, err)
+			}
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Status lists every known migration together with when it was applied. It
+// only reads: it takes no lock and treats a database without the
+// schema_migrations table as having every migration pending.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var exists bool
+	if err := m.db.GetContext(ctx, &exists, `SELECT to_regclass('schema_migrations') IS NOT NULL`); err != nil {
+		return nil, err
+	}
+
+	applied := map[int64]time.Time{}
+	if exists {
+		var err error
+		if applied, err = m.applied(ctx, m.db); err != nil {
+			return nil, err
+		}
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Version: migration.Version, Name: migration.Name}
+		if at, ok := applied[migration.Version]; ok {
+			status.AppliedAt = &at
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Pending returns the migrations that have not been applied yet.
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for i, status := range statuses {
+		if !status.Applied() {
+			pending = append(pending, m.migrations[i])
+		}
+	}
+	return pending, nil
+}
+
+func runInTx(ctx context.Context, conn *sqlx.Conn, script, bookkeeping string, args ...interface{}) error {
+	tx, err := conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, bookkeeping, args...); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Create writes an empty up/down pair for a new migration into dir, numbered
+// one past the highest version already present there.
+func Create(dir, name string) ([]string, error) {
+	if !regexp.MustCompile(`^[a-z0-9_]+$`).MatchString(name) {
+		return nil, fmt.Errorf("migration name %q must be lower_snake_case", name)
+	}
+
+	existing, err := load(os.DirFS(dir))
+	if err != nil {
+		return nil, err
+	}
+
+	var next int64 = 1
+	if len(existing) > 0 {
+		next = existing[len(existing)-1].Version + 1
+	}
+
+	var files []string
+	for _, direction := range []string{"up", "down"} {
+		path := filepath.Join(dir, fmt.Sprintf("%06d_%s.%s.sql", next, name, direction))
+		content := fmt.Sprintf("-- %d_%s (%s)\n", next, name, direction)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			return nil, err
+		}
+		files = append(files, path)
+	}
+	return files, nil
+}
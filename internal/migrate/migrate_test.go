@@ -0,0 +1,82 @@
+package migrate
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    fstest.MapFS
+		want     []string
+		wantErr  string
+		wantDown map[int64]string
+	}{
+		{name: "empty", files: fstest.MapFS{}, want: []string{}},
+		{
+			name: "sorted by version, not name",
+			files: fstest.MapFS{
+				"000010_add_orders.up.sql":         {Data: []byte("up 10")},
+				"000002_add_brands.up.sql":         {Data: []byte("up 2")},
+				"000002_add_brands.down.sql":       {Data: []byte("down 2")},
+				"000001_init.up.sql":               {Data: []byte("up 1")},
+				"000001_init.down.sql":             {Data: []byte("down 1")},
+				"100_later_without_padding.up.sql": {Data: []byte("up 100")},
+			},
+			want:     []string{"1_init", "2_add_brands", "10_add_orders", "100_later_without_padding"},
+			wantDown: map[int64]string{1: "down 1", 2: "down 2", 10: ""},
+		},
+		{
+			name: "ignores other files",
+			files: fstest.MapFS{
+				"000001_init.up.sql": {Data: []byte("up 1")},
+				"README.md":          {Data: []byte("docs")},
+				"000002_Bad.up.sql":  {Data: []byte("up 2")},
+				"000003_x.sql":       {Data: []byte("up 3")},
+				"embed.go":           {Data: []byte("package migrations")},
+			},
+			want: []string{"1_init"},
+		},
+		{
+			name:    "down without up",
+			files:   fstest.MapFS{"000001_init.down.sql": {Data: []byte("down 1")}},
+			wantErr: "has no up file",
+		},
+		{
+			name: "conflicting names",
+			files: fstest.MapFS{
+				"000001_init.up.sql":  {Data: []byte("up 1")},
+				"000001_other.up.sql": {Data: []byte("up 1")},
+			},
+			wantErr: "conflicting names",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := load(tt.files)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("load error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := make([]string, len(migrations))
+			for i, m := range migrations {
+				got[i] = strings.TrimPrefix(m.Up, "up ") + "_" + m.Name
+				if want, ok := tt.wantDown[m.Version]; ok && m.Down != want {
+					t.Errorf("migration %d down = %q, want %q", m.Version, m.Down, want)
+				}
+			}
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("load = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package payment
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rezajo220/ecommerce/internal/domain"
+)
+
+// PaymentGateway is a payment provider. Implementations talk to the provider
+// and return its view of the payment; recording it is left to the caller.
+type PaymentGateway interface {
+	// Name identifies the provider on stored payments.
+	Name() string
+	Authorize(ctx context.Context, req *AuthorizeRequest) (*Result, error)
+	Capture(ctx context.Context, providerRef string, amount domain.Decimal) (*Result, error)
+	// Void releases an authorization that will not be captured.
+	Void(ctx context.Context, providerRef string) (*Result, error)
+	Refund(ctx context.Context, providerRef string, amount domain.Decimal) (*Result, error)
+	// VerifyWebhookSignature returns domain.ErrInvalidWebhookSignature unless
+	// signature was produced by the provider for payload.
+	VerifyWebhookSignature(payload []byte, signature string) error
+}
+
+// AuthorizeRequest asks for the order's amount, in the order's currency, to
+// be authorized. PaymentID is unique per attempt, so providers can use it as
+// an idempotency key.
+type AuthorizeRequest struct {
+	PaymentID     uuid.UUID
+	OrderID       uuid.UUID
+	Amount        domain.Decimal
+	Currency      string
+	PaymentMethod string
+}
+
+// Result is the provider's answer to an operation on a payment.
+type Result struct {
+	ProviderRef string
+	Status      domain.PaymentStatus
+}
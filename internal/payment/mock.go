@@ -0,0 +1,74 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/rezajo220/ecommerce/internal/domain"
+)
+
+// DeclinePaymentMethod is the payment method the mock gateway always declines.
+const DeclinePaymentMethod = "tok_decline"
+
+// MockGateway is an in-process PaymentGateway for tests and local
+// development. It never talks to the network and is deterministic: the same
+// payment always gets the same reference, every payment method but
+// DeclinePaymentMethod is approved, and webhooks are signed with an
+// HMAC-SHA256 of the payload keyed with the configured secret.
+type MockGateway struct {
+	secret []byte
+}
+
+func NewMockGateway(webhookSecret string) *MockGateway {
+	return &MockGateway{secret: []byte(webhookSecret)}
+}
+
+func (g *MockGateway) Name() string {
+	return "mock"
+}
+
+func (g *MockGateway) Authorize(ctx context.Context, req *AuthorizeRequest) (*Result, error) {
+	if req.PaymentMethod == DeclinePaymentMethod {
+		return nil, domain.ErrPaymentDeclined
+	}
+
+	sum := sha256.Sum256([]byte(req.PaymentID.String()))
+	return &Result{
+		ProviderRef: "mock_" + hex.EncodeToString(sum[:12]),
+		Status:      domain.PaymentAuthorized,
+	}, nil
+}
+
+func (g *MockGateway) Capture(ctx context.Context, providerRef string, amount domain.Decimal) (*Result, error) {
+	return &Result{ProviderRef: providerRef, Status: domain.PaymentCaptured}, nil
+}
+
+func (g *MockGateway) Void(ctx context.Context, providerRef string) (*Result, error) {
+	return &Result{ProviderRef: providerRef, Status: domain.PaymentFailed}, nil
+}
+
+func (g *MockGateway) Refund(ctx context.Context, providerRef string, amount domain.Decimal) (*Result, error) {
+	return &Result{ProviderRef: providerRef, Status: domain.PaymentRefunded}, nil
+}
+
+func (g *MockGateway) VerifyWebhookSignature(payload []byte, signature string) error {
+	expected, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(expected, g.sign(payload)) {
+		return domain.ErrInvalidWebhookSignature
+	}
+	return nil
+}
+
+// Sign returns the signature the mock expects on payload, for sending test
+// webhooks.
+func (g *MockGateway) Sign(payload []byte) string {
+	return hex.EncodeToString(g.sign(payload))
+}
+
+func (g *MockGateway) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, g.secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
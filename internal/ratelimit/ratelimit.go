@@ -0,0 +1,167 @@
+// Package ratelimit implements token-bucket rate limiting. The Policy picks a
+// Limit for a request; a Store keeps the buckets, so they can live in process
+// memory or in a shared backend.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit allows Requests per Period. Buckets hold Requests tokens, so a full
+// quota can be spent in one burst, and refill evenly over Period.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// Rate is the refill rate in tokens per second.
+func (l Limit) Rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+func (l Limit) String() string {
+	return fmt.Sprintf("%d/%s", l.Requests, l.Period)
+}
+
+// Result describes the bucket after a Take.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// ResetAfter is the time until the bucket is full again.
+	ResetAfter time.Duration
+	// RetryAfter is the time until the next request would be allowed; zero
+	// when Allowed.
+	RetryAfter time.Duration
+}
+
+// Store takes a token from the bucket named key, creating it full if it does
+// not exist yet.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// Rule applies Limit to requests whose method matches Method (any method
+// when empty) and whose path matches Path. A Path matches only itself, with
+// or without a trailing slash, unless it ends in "/*": then it also matches
+// every path below it. "/v1/auth/*" matches "/v1/auth" and
+// "/v1/auth/login" but not "/v1/authors".
+type Rule struct {
+	Method string
+	Path   string
+	Limit  Limit
+}
+
+func (r Rule) matches(method, path string) bool {
+	if r.Method != "" && !strings.EqualFold(r.Method, method) {
+		return false
+	}
+
+	path = trimSlash(path)
+	if base, ok := strings.CutSuffix(r.Path, "/*"); ok {
+		base = trimSlash(base)
+		return path == base || strings.HasPrefix(path, base+"/")
+	}
+	return path == trimSlash(r.Path)
+}
+
+// trimSlash drops a trailing slash, except from the root path.
+func trimSlash(path string) string {
+	if len(path) > 1 {
+		return strings.TrimSuffix(path, "/")
+	}
+	return path
+}
+
+// name identifies the rule's buckets, so every rule has its own quota.
+func (r Rule) name() string {
+	if r.Method == "" {
+		return "* " + r.Path
+	}
+	return strings.ToUpper(r.Method) + " " + r.Path
+}
+
+// Policy is the configured set of limits. The first matching rule wins;
+// requests matching no rule share the Default quota. PerIP caps all requests
+// from one address before the caller is identified, so floods of requests
+// with made-up credentials are turned away before they reach the database.
+type Policy struct {
+	Default Limit
+	Rules   []Rule
+	PerIP   Limit
+}
+
+// Resolve returns the bucket group and limit for a request.
+func (p Policy) Resolve(method, path string) (string, Limit) {
+	for _, rule := range p.Rules {
+		if rule.matches(method, path) {
+			return rule.name(), rule.Limit
+		}
+	}
+	return "default", p.Default
+}
+
+// ParseLimit parses "<requests>/<period>", e.g. "100/1m" or "5/30s".
+func ParseLimit(s string) (Limit, error) {
+	requests, period, ok := strings.Cut(strings.TrimSpace(s), "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: want <requests>/<period>", s)
+	}
+
+	n, err := strconv.Atoi(requests)
+	if err != nil || n <= 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: requests must be a positive integer", s)
+	}
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: period must be a positive duration", s)
+	}
+
+	return Limit{Requests: n, Period: d}, nil
+}
+
+// ParseRules parses a ";"-separated list of "[METHOD ]<path>=<limit>", e.g.
+// "POST /v1/products=30/1m; /v1/auth/*=20/1m". See Rule for how paths match.
+func ParseRules(s string) ([]Rule, error) {
+	var rules []Rule
+	for _, entry := range strings.Split(s, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		target, limitStr, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid rate limit rule %q: want [METHOD ]<path>=<limit>", entry)
+		}
+		limit, err := ParseLimit(limitStr)
+		if err != nil {
+			return nil, err
+		}
+
+		rule := Rule{Path: strings.TrimSpace(target), Limit: limit}
+		if method, path, ok := strings.Cut(rule.Path, " "); ok {
+			rule.Method, rule.Path = strings.ToUpper(method), strings.TrimSpace(path)
+		}
+		if !strings.HasPrefix(rule.Path, "/") {
+			return nil, fmt.Errorf("invalid rate limit rule %q: path must start with /", entry)
+		}
+		if strings.Contains(strings.TrimSuffix(rule.Path, "/*"), "*") {
+			return nil, fmt.Errorf("invalid rate limit rule %q: * is only allowed as the last path segment", entry)
+		}
+
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// Seconds formats d for the RateLimit-Reset and Retry-After headers, which
+// carry whole seconds; it rounds up so clients never retry too early.
+func Seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,118 @@
+package ratelimit
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Limit
+		wantErr bool
+	}{
+		{in: "100/1m", want: Limit{Requests: 100, Period: time.Minute}},
+		{in: " 5/30s ", want: Limit{Requests: 5, Period: 30 * time.Second}},
+		{in: "100", wantErr: true},
+		{in: "0/1m", wantErr: true},
+		{in: "-1/1m", wantErr: true},
+		{in: "x/1m", wantErr: true},
+		{in: "10/", wantErr: true},
+		{in: "10/0s", wantErr: true},
+		{in: "10/minute", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseLimit(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLimit(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseLimit(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	perMinute := func(n int) Limit { return Limit{Requests: n, Period: time.Minute} }
+
+	tests := []struct {
+		name    string
+		in      string
+		want    []Rule
+		wantErr bool
+	}{
+		{name: "empty", in: "", want: nil},
+		{name: "blank entries", in: " ; ;", want: nil},
+		{
+			name: "method and path",
+			in:   "post /v1/products=30/1m",
+			want: []Rule{{Method: "POST", Path: "/v1/products", Limit: perMinute(30)}},
+		},
+		{
+			name: "any method and subtree",
+			in:   "POST /v1/products=30/1m; /v1/auth/*=20/1m",
+			want: []Rule{
+				{Method: "POST", Path: "/v1/products", Limit: perMinute(30)},
+				{Path: "/v1/auth/*", Limit: perMinute(20)},
+			},
+		},
+		{name: "missing limit", in: "/v1/products", wantErr: true},
+		{name: "invalid limit", in: "/v1/products=fast", wantErr: true},
+		{name: "relative path", in: "POST v1/products=30/1m", wantErr: true},
+		{name: "wildcard inside path", in: "/v1/*/variants=30/1m", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRules(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRules(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseRules(%q) = %+v, want %+v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicyResolve(t *testing.T) {
+	rules, err := ParseRules("POST /v1/products=30/1m; /v1/auth/*=20/1m; /*=50/1m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy := Policy{Default: Limit{Requests: 300, Period: time.Minute}, Rules: rules[:2]}
+	catchAll := Policy{Default: policy.Default, Rules: rules}
+
+	tests := []struct {
+		name      string
+		policy    Policy
+		method    string
+		path      string
+		wantGroup string
+	}{
+		{name: "exact path", policy: policy, method: "POST", path: "/v1/products", wantGroup: "POST /v1/products"},
+		{name: "trailing slash", policy: policy, method: "POST", path: "/v1/products/", wantGroup: "POST /v1/products"},
+		{name: "method is case insensitive", policy: policy, method: "post", path: "/v1/products", wantGroup: "POST /v1/products"},
+		{name: "other method", policy: policy, method: "GET", path: "/v1/products", wantGroup: "default"},
+		{name: "nested path of exact rule", policy: policy, method: "POST", path: "/v1/products/1/stock-adjustments", wantGroup: "default"},
+		{name: "sibling sharing a prefix", policy: policy, method: "POST", path: "/v1/productsx", wantGroup: "default"},
+		{name: "subtree root", policy: policy, method: "POST", path: "/v1/auth", wantGroup: "* /v1/auth/*"},
+		{name: "subtree child", policy: policy, method: "POST", path: "/v1/auth/login", wantGroup: "* /v1/auth/*"},
+		{name: "subtree sibling", policy: policy, method: "GET", path: "/v1/authors", wantGroup: "default"},
+		{name: "first match wins", policy: catchAll, method: "POST", path: "/v1/auth/login", wantGroup: "* /v1/auth/*"},
+		{name: "root wildcard", policy: catchAll, method: "GET", path: "/v1/brands", wantGroup: "* /*"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group, _ := tt.policy.Resolve(tt.method, tt.path)
+			if group != tt.wantGroup {
+				t.Errorf("Resolve(%q, %q) group = %q, want %q", tt.method, tt.path, group, tt.wantGroup)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/rezajo220/ecommerce/internal/domain"
+)
+
+// lastUsedResolution limits how often last_used_at is written, so a busy
+// integration does not turn every request into an UPDATE.
+const lastUsedResolution = "1 minute"
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *domain.APIKey) (*domain.APIKey, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.APIKey, error)
+	GetByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error)
+	LockByID(ctx context.Context, id uuid.UUID) (*domain.APIKey, error)
+	List(ctx context.Context) ([]domain.APIKey, error)
+	UpdateSecret(ctx context.Context, id uuid.UUID, prefix, secretHash string) (*domain.APIKey, error)
+	Revoke(ctx context.Context, id uuid.UUID) (*domain.APIKey, error)
+	TouchLastUsed(ctx context.Context, id uuid.UUID) error
+}
+
+type apiKeyRepository struct {
+	db *sqlx.DB
+}
+
+func NewAPIKeyRepository(db *sqlx.DB) APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+// apiKeyRow scans the array columns, which domain.APIKey keeps as plain
+// string slices.
+type apiKeyRow struct {
+	domain.APIKey
+	ScopesArray     pq.StringArray `db:"scopes"`
+	AllowedIPsArray pq.StringArray `db:"allowed_ips"`
+}
+
+func (row *apiKeyRow) toDomain() *domain.APIKey {
+	key := row.APIKey
+	key.Scopes = append([]string{}, row.ScopesArray...)
+	key.AllowedIPs = append([]string{}, row.AllowedIPsArray...)
+	return &key
+}
+
+const apiKeyColumns = `id, name, prefix, secret_hash, scopes, allowed_ips, expires_at, last_used_at, revoked_at, created_by, created_at, updated_at`
+
+func (r *apiKeyRepository) Create(ctx context.Context, key *domain.APIKey) (*domain.APIKey, error) {
+	query := `
+		INSERT INTO api_keys (name, prefix, secret_hash, scopes, allowed_ips, expires_at, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING ` + apiKeyColumns
+
+	now := time.Now()
+	var row apiKeyRow
+
+	err := conn(ctx, r.db).QueryRowxContext(ctx, query,
+		key.Name, key.Prefix, key.SecretHash,
+		append(pq.StringArray{}, key.Scopes...), append(pq.StringArray{}, key.AllowedIPs...),
+		key.ExpiresAt, key.CreatedBy, now, now,
+	).StructScan(&row)
+	if err != nil {
+		return nil, translateError(err, domain.ErrAPIKeyNotFound)
+	}
+
+	return row.toDomain(), nil
+}
+
+func (r *apiKeyRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.APIKey, error) {
+	return r.getOne(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE id = $1`, id, domain.ErrAPIKeyNotFound)
+}
+
+// GetByPrefix looks a key up for authentication; an unknown prefix is
+// reported as an invalid key.
+func (r *apiKeyRepository) GetByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error) {
+	return r.getOne(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE prefix = $1`, prefix, domain.ErrInvalidAPIKey)
+}
+
+func (r *apiKeyRepository) LockByID(ctx context.Context, id uuid.UUID) (*domain.APIKey, error) {
+	return r.getOne(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE id = $1 FOR UPDATE`, id, domain.ErrAPIKeyNotFound)
+}
+
+func (r *apiKeyRepository) List(ctx context.Context) ([]domain.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys ORDER BY created_at DESC`
+
+	var rows []apiKeyRow
+	if err := conn(ctx, r.db).SelectContext(ctx, &rows, query); err != nil {
+		return nil, err
+	}
+
+	keys := make([]domain.APIKey, len(rows))
+	for i := range rows {
+		keys[i] = *rows[i].toDomain()
+	}
+	return keys, nil
+}
+
+func (r *apiKeyRepository) UpdateSecret(ctx context.Context, id uuid.UUID, prefix, secretHash string) (*domain.APIKey, error) {
+	query := `
+		UPDATE api_keys
+		SET prefix = $1, secret_hash = $2, updated_at = $3
+		WHERE id = $4
+		RETURNING ` + apiKeyColumns
+
+	var row apiKeyRow
+	err := conn(ctx, r.db).QueryRowxContext(ctx, query, prefix, secretHash, time.Now(), id).StructScan(&row)
+	if err != nil {
+		return nil, translateError(err, domain.ErrAPIKeyNotFound)
+	}
+
+	return row.toDomain(), nil
+}
+
+// Revoke marks the key revoked. Revoking an already revoked key keeps the
+// original revocation time.
+func (r *apiKeyRepository) Revoke(ctx context.Context, id uuid.UUID) (*domain.APIKey, error) {
+	query := `
+		UPDATE api_keys
+		SET revoked_at = COALESCE(revoked_at, now()), updated_at = $1
+		WHERE id = $2
+		RETURNING ` + apiKeyColumns
+
+	var row apiKeyRow
+	err := conn(ctx, r.db).QueryRowxContext(ctx, query, time.Now(), id).StructScan(&row)
+	if err != nil {
+		return nil, translateError(err, domain.ErrAPIKeyNotFound)
+	}
+
+	return row.toDomain(), nil
+}
+
+func (r *apiKeyRepository) TouchLastUsed(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE api_keys
+		SET last_used_at = now()
+		WHERE id = $1
+			AND (last_used_at IS NULL OR last_used_at < now() - interval '` + lastUsedResolution + `')`
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, id)
+	return err
+}
+
+func (r *apiKeyRepository) getOne(ctx context.Context, query string, id interface{}, notFound error) (*domain.APIKey, error) {
+	var row apiKeyRow
+	if err := conn(ctx, r.db).GetContext(ctx, &row, query, id); err != nil {
+		return nil, translateError(err, notFound)
+	}
+	return row.toDomain(), nil
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rezajo220/ecommerce/internal/domain"
+)
+
+type AuditRepository interface {
+	Record(ctx context.Context, entry *domain.AuditEntry) error
+	List(ctx context.Context, filter *domain.AuditFilter, limit, offset int) ([]domain.AuditEntry, int, error)
+}
+
+type auditRepository struct {
+	db *sqlx.DB
+}
+
+func NewAuditRepository(db *sqlx.DB) AuditRepository {
+	return &auditRepository{db: db}
+}
+
+// Record writes an entry through the transaction in ctx, if any, so it is
+// committed or rolled back together with the change it describes.
+func (r *auditRepository) Record(ctx context.Context, entry *domain.AuditEntry) error {
+	query := `
+		INSERT INTO audit_log (actor, action, entity_type, entity_id, changes, request_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	changes, err := json.Marshal(entry.Changes)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
+		entry.Actor, entry.Action, entry.EntityType, entry.EntityID, string(changes), entry.RequestID, time.Now(),
+	)
+	return err
+}
+
+const auditFilterClause = `
+		WHERE ($1 = '' OR entity_type = $1)
+			AND ($2::uuid IS NULL OR entity_id = $2)
+			AND ($3 = '' OR actor = $3)
+			AND ($4 = '' OR action = $4)
+			AND ($5::timestamptz IS NULL OR created_at >= $5)
+			AND ($6::timestamptz IS NULL OR created_at < $6)`
+
+func (r *auditRepository) List(ctx context.Context, filter *domain.AuditFilter, limit, offset int) ([]domain.AuditEntry, int, error) {
+	args := []interface{}{filter.EntityType, filter.EntityID, filter.Actor, filter.Action, filter.From, filter.To}
+
+	var total int
+	countQuery := `SELECT COUNT(*) FROM audit_log` + auditFilterClause
+	if err := conn(ctx, r.db).GetContext(ctx, &total, countQuery, args...); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT id, actor, action, entity_type, entity_id, changes, request_id, created_at
+		FROM audit_log` + auditFilterClause + `
+		ORDER BY created_at DESC, id DESC
+		LIMIT $7 OFFSET $8`
+
+	var rows []struct {
+		domain.AuditEntry
+		ChangesJSON []byte `db:"changes"`
+	}
+	if err := conn(ctx, r.db).SelectContext(ctx, &rows, query, append(args, limit, offset)...); err != nil {
+		return nil, 0, err
+	}
+
+	entries := make([]domain.AuditEntry, len(rows))
+	for i, row := range rows {
+		entries[i] = row.AuditEntry
+		if err := json.Unmarshal(row.ChangesJSON, &entries[i].Changes); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return entries, total, nil
+}
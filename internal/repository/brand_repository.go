@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,8 +14,14 @@ import (
 type BrandRepository interface {
 	Create(ctx context.Context, brand *domain.CreateBrandRequest) (*domain.Brand, error)
 	GetByID(ctx context.Context, id uuid.UUID) (*domain.Brand, error)
-	Delete(ctx context.Context, id uuid.UUID) error
-	List(ctx context.Context) ([]domain.Brand, error)
+	GetByIDIncludingDeleted(ctx context.Context, id uuid.UUID) (*domain.Brand, error)
+	LockByID(ctx context.Context, id uuid.UUID) (*domain.Brand, error)
+	ShareLockByID(ctx context.Context, id uuid.UUID) (*domain.Brand, error)
+	Update(ctx context.Context, id uuid.UUID, brand *domain.UpdateBrandRequest, version *int) (*domain.Brand, error)
+	Delete(ctx context.Context, id uuid.UUID, version *int) error
+	Restore(ctx context.Context, id uuid.UUID) (*domain.Brand, error)
+	PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int, error)
+	List(ctx context.Context, includeDeleted bool) ([]domain.Brand, error)
 	IsUsedByProducts(ctx context.Context, id uuid.UUID) (bool, error)
 }
 
@@ -30,14 +37,14 @@ func (r *brandRepository) Create(ctx context.Context, req *domain.CreateBrandReq
 	query := `
 		INSERT INTO brands (brand_name, created_at, updated_at)
 		VALUES ($1, $2, $3)
-		RETURNING id, brand_name, created_at, updated_at`
+		RETURNING id, brand_name, created_at, updated_at, version, deleted_at`
 
 	now := time.Now()
 	var brand domain.Brand
 
-	err := r.db.QueryRowxContext(ctx, query, req.BrandName, now, now).StructScan(&brand)
+	err := conn(ctx, r.db).QueryRowxContext(ctx, query, req.BrandName, now, now).StructScan(&brand)
 	if err != nil {
-		return nil, err
+		return nil, translateError(err, domain.ErrBrandNotFound)
 	}
 
 	return &brand, nil
@@ -45,27 +52,102 @@ func (r *brandRepository) Create(ctx context.Context, req *domain.CreateBrandReq
 
 func (r *brandRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Brand, error) {
 	query := `
-		SELECT id, brand_name, created_at, updated_at
+		SELECT id, brand_name, created_at, updated_at, version, deleted_at
+		FROM brands
+		WHERE id = $1 AND deleted_at IS NULL`
+
+	var brand domain.Brand
+	err := conn(ctx, r.db).GetContext(ctx, &brand, query, id)
+	if err != nil {
+		return nil, translateError(err, domain.ErrBrandNotFound)
+	}
+
+	return &brand, nil
+}
+
+// GetByIDIncludingDeleted is GetByID for admin views and restores, which
+// also need soft-deleted brands.
+func (r *brandRepository) GetByIDIncludingDeleted(ctx context.Context, id uuid.UUID) (*domain.Brand, error) {
+	query := `
+		SELECT id, brand_name, created_at, updated_at, version, deleted_at
 		FROM brands
 		WHERE id = $1`
 
 	var brand domain.Brand
-	err := r.db.GetContext(ctx, &brand, query, id)
+	err := conn(ctx, r.db).GetContext(ctx, &brand, query, id)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil
-		}
-		return nil, err
+		return nil, translateError(err, domain.ErrBrandNotFound)
 	}
 
 	return &brand, nil
 }
 
-func (r *brandRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `DELETE FROM brands WHERE id = $1`
-	result, err := r.db.ExecContext(ctx, query, id)
+// LockByID reads a brand and locks its row until the surrounding transaction
+// ends.
+func (r *brandRepository) LockByID(ctx context.Context, id uuid.UUID) (*domain.Brand, error) {
+	query := `
+		SELECT id, brand_name, created_at, updated_at, version, deleted_at
+		FROM brands
+		WHERE id = $1 AND deleted_at IS NULL
+		FOR UPDATE`
+
+	var brand domain.Brand
+	err := conn(ctx, r.db).GetContext(ctx, &brand, query, id)
 	if err != nil {
-		return err
+		return nil, translateError(err, domain.ErrBrandNotFound)
+	}
+
+	return &brand, nil
+}
+
+// ShareLockByID reads a brand that is not deleted and keeps it from being
+// changed or deleted until the surrounding transaction ends, while letting
+// other transactions share the lock. Product writes hold it on their brand,
+// so a brand cannot be deleted while a product is assigned to it.
+func (r *brandRepository) ShareLockByID(ctx context.Context, id uuid.UUID) (*domain.Brand, error) {
+	query := `
+		SELECT id, brand_name, created_at, updated_at, version, deleted_at
+		FROM brands
+		WHERE id = $1 AND deleted_at IS NULL
+		FOR SHARE`
+
+	var brand domain.Brand
+	err := conn(ctx, r.db).GetContext(ctx, &brand, query, id)
+	if err != nil {
+		return nil, translateError(err, domain.ErrBrandNotFound)
+	}
+
+	return &brand, nil
+}
+
+// Update and Delete bump the brand's version. When version is set they only
+// apply while the stored version still equals it, and fail with
+// domain.ErrVersionMismatch otherwise.
+func (r *brandRepository) Update(ctx context.Context, id uuid.UUID, req *domain.UpdateBrandRequest, version *int) (*domain.Brand, error) {
+	query := `
+		UPDATE brands
+		SET brand_name = COALESCE($1, brand_name), updated_at = $2, version = version + 1
+		WHERE id = $3 AND deleted_at IS NULL AND ($4::int IS NULL OR version = $4)
+		RETURNING id, brand_name, created_at, updated_at, version, deleted_at`
+
+	var brand domain.Brand
+	err := conn(ctx, r.db).QueryRowxContext(ctx, query, req.BrandName, time.Now(), id, version).StructScan(&brand)
+	if err != nil {
+		return nil, r.writeError(ctx, err, id, version)
+	}
+
+	return &brand, nil
+}
+
+// Delete soft deletes the brand; PurgeDeleted removes it later.
+func (r *brandRepository) Delete(ctx context.Context, id uuid.UUID, version *int) error {
+	query := `
+		UPDATE brands
+		SET deleted_at = now(), updated_at = $1, version = version + 1
+		WHERE id = $2 AND deleted_at IS NULL AND ($3::int IS NULL OR version = $3)`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, time.Now(), id, version)
+	if err != nil {
+		return translateError(err, domain.ErrBrandNotFound)
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -74,27 +156,72 @@ func (r *brandRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	}
 
 	if rowsAffected == 0 {
-		return sql.ErrNoRows
+		return r.writeError(ctx, sql.ErrNoRows, id, version)
 	}
 
 	return nil
 }
 
-func (r *brandRepository) List(ctx context.Context) ([]domain.Brand, error) {
+// writeError explains a versioned write that failed with err: when no row
+// matched, the brand is either gone or at another version.
+func (r *brandRepository) writeError(ctx context.Context, err error, id uuid.UUID, version *int) error {
+	if errors.Is(err, sql.ErrNoRows) && version != nil {
+		return staleOrNotFound(ctx, r.db, "brands", id, domain.ErrBrandNotFound)
+	}
+	return translateError(err, domain.ErrBrandNotFound)
+}
+
+func (r *brandRepository) Restore(ctx context.Context, id uuid.UUID) (*domain.Brand, error) {
+	query := `
+		UPDATE brands
+		SET deleted_at = NULL, updated_at = $1, version = version + 1
+		WHERE id = $2 AND deleted_at IS NOT NULL
+		RETURNING id, brand_name, created_at, updated_at, version, deleted_at`
+
+	var brand domain.Brand
+	err := conn(ctx, r.db).QueryRowxContext(ctx, query, time.Now(), id).StructScan(&brand)
+	if err != nil {
+		return nil, translateError(err, domain.ErrBrandNotFound)
+	}
+
+	return &brand, nil
+}
+
+// PurgeDeleted permanently removes brands soft deleted before deletedBefore.
+// Brands still referenced by soft-deleted products are kept until those
+// products are purged.
+func (r *brandRepository) PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int, error) {
+	query := `
+		DELETE FROM brands b
+		WHERE b.deleted_at < $1
+			AND NOT EXISTS (SELECT 1 FROM products p WHERE p.brand_id = b.id)`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+
+	purged, err := result.RowsAffected()
+	return int(purged), err
+}
+
+func (r *brandRepository) List(ctx context.Context, includeDeleted bool) ([]domain.Brand, error) {
 	query := `
-		SELECT id, brand_name, created_at, updated_at
+		SELECT id, brand_name, created_at, updated_at, version, deleted_at
 		FROM brands
+		WHERE $1 OR deleted_at IS NULL
 		ORDER BY brand_name ASC`
 
 	var brands []domain.Brand
-	err := r.db.SelectContext(ctx, &brands, query)
+	err := conn(ctx, r.db).SelectContext(ctx, &brands, query, includeDeleted)
 	return brands, err
 }
 
+// IsUsedByProducts ignores soft-deleted products, so a brand can be deleted
+// once all of its products are.
 func (r *brandRepository) IsUsedByProducts(ctx context.Context, id uuid.UUID) (bool, error) {
 	var count int
-	query := `SELECT COUNT(*) FROM products WHERE brand_id = $1`
-	err := r.db.GetContext(ctx, &count, query, id)
+	query := `SELECT COUNT(*) FROM products WHERE brand_id = $1 AND deleted_at IS NULL`
+	err := conn(ctx, r.db).GetContext(ctx, &count, query, id)
 	if err != nil {
 		return false, err
 	}
@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/rezajo220/ecommerce/internal/domain"
+)
+
+type CartRepository interface {
+	Create(ctx context.Context, holderID uuid.UUID) (*domain.Cart, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Cart, error)
+	LockByID(ctx context.Context, id uuid.UUID) (*domain.Cart, error)
+	MarkCheckedOut(ctx context.Context, id uuid.UUID) error
+	ListItems(ctx context.Context, cartID uuid.UUID) ([]domain.CartItem, error)
+	AddItem(ctx context.Context, cartID uuid.UUID, item *domain.AddCartItemRequest, unitPrice domain.Decimal, currency string) error
+	UpdateItemQty(ctx context.Context, cartID, itemID uuid.UUID, qty float64) error
+	UpdateItemPrice(ctx context.Context, itemID uuid.UUID, unitPrice domain.Decimal, currency string) error
+	DeleteItem(ctx context.Context, cartID, itemID uuid.UUID) error
+}
+
+type cartRepository struct {
+	db *sqlx.DB
+}
+
+func NewCartRepository(db *sqlx.DB) CartRepository {
+	return &cartRepository{db: db}
+}
+
+func (r *cartRepository) Create(ctx context.Context, holderID uuid.UUID) (*domain.Cart, error) {
+	query := `
+		INSERT INTO carts (holder_id, created_at, updated_at)
+		VALUES ($1, $2, $3)
+		RETURNING id, holder_id, created_at, updated_at, checked_out_at`
+
+	now := time.Now()
+	var cart domain.Cart
+
+	err := conn(ctx, r.db).QueryRowxContext(ctx, query, holderID, now, now).StructScan(&cart)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cart, nil
+}
+
+func (r *cartRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Cart, error) {
+	query := `
+		SELECT id, holder_id, created_at, updated_at, checked_out_at
+		FROM carts
+		WHERE id = $1`
+
+	var cart domain.Cart
+	err := conn(ctx, r.db).GetContext(ctx, &cart, query, id)
+	if err != nil {
+		return nil, translateError(err, domain.ErrCartNotFound)
+	}
+
+	return &cart, nil
+}
+
+// LockByID is GetByID that also locks the cart until the surrounding
+// transaction ends.
+func (r *cartRepository) LockByID(ctx context.Context, id uuid.UUID) (*domain.Cart, error) {
+	query := `
+		SELECT id, holder_id, created_at, updated_at, checked_out_at
+		FROM carts
+		WHERE id = $1
+		FOR UPDATE`
+
+	var cart domain.Cart
+	err := conn(ctx, r.db).GetContext(ctx, &cart, query, id)
+	if err != nil {
+		return nil, translateError(err, domain.ErrCartNotFound)
+	}
+
+	return &cart, nil
+}
+
+func (r *cartRepository) MarkCheckedOut(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE carts SET checked_out_at = $1, updated_at = $1 WHERE id = $2`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, time.Now(), id)
+	return err
+}
+
+// ListItems returns the cart's lines together with the current price,
+// currency and availability of their products, oldest line first.
+func (r *cartRepository) ListItems(ctx context.Context, cartID uuid.UUID) ([]domain.CartItem, error) {
+	query := `
+		SELECT ci.id, ci.cart_id, ci.product_id, ci.qty, ci.unit_price, ci.currency, ci.created_at, ci.updated_at,
+			p.product_name, p.price AS current_price, p.currency AS current_currency, ` + availableQtyColumn + `
+		FROM cart_items ci
+		JOIN products p ON p.id = ci.product_id
+		WHERE ci.cart_id = $1
+		ORDER BY ci.created_at ASC, ci.id ASC`
+
+	items := []domain.CartItem{}
+	err := conn(ctx, r.db).SelectContext(ctx, &items, query, cartID)
+	return items, err
+}
+
+// AddItem adds a line for the product, or adds to its qty when the cart
+// already holds the product.
+func (r *cartRepository) AddItem(ctx context.Context, cartID uuid.UUID, req *domain.AddCartItemRequest, unitPrice domain.Decimal, currency string) error {
+	query := `
+		INSERT INTO cart_items (cart_id, product_id, qty, unit_price, currency, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $6)
+		ON CONFLICT (cart_id, product_id)
+		DO UPDATE SET qty = cart_items.qty + EXCLUDED.qty, unit_price = EXCLUDED.unit_price,
+			currency = EXCLUDED.currency, updated_at = EXCLUDED.updated_at`
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, cartID, req.ProductID, req.Qty, unitPrice, currency, time.Now())
+	if err != nil {
+		return translateError(err, domain.ErrCartNotFound)
+	}
+
+	return r.touch(ctx, cartID)
+}
+
+func (r *cartRepository) UpdateItemQty(ctx context.Context, cartID, itemID uuid.UUID, qty float64) error {
+	query := `UPDATE cart_items SET qty = $1, updated_at = $2 WHERE id = $3 AND cart_id = $4`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, qty, time.Now(), itemID, cartID)
+	if err != nil {
+		return translateError(err, domain.ErrCartItemNotFound)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrCartItemNotFound
+	}
+
+	return r.touch(ctx, cartID)
+}
+
+func (r *cartRepository) UpdateItemPrice(ctx context.Context, itemID uuid.UUID, unitPrice domain.Decimal, currency string) error {
+	query := `UPDATE cart_items SET unit_price = $1, currency = $2, updated_at = $3 WHERE id = $4`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, unitPrice, currency, time.Now(), itemID)
+	return err
+}
+
+func (r *cartRepository) DeleteItem(ctx context.Context, cartID, itemID uuid.UUID) error {
+	query := `DELETE FROM cart_items WHERE id = $1 AND cart_id = $2`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, itemID, cartID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrCartItemNotFound
+	}
+
+	return r.touch(ctx, cartID)
+}
+
+func (r *cartRepository) touch(ctx context.Context, cartID uuid.UUID) error {
+	query := `UPDATE carts SET updated_at = $1 WHERE id = $2`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, time.Now(), cartID)
+	return err
+}
@@ -0,0 +1,252 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/rezajo220/ecommerce/internal/domain"
+)
+
+type CategoryRepository interface {
+	Create(ctx context.Context, category *domain.CreateCategoryRequest) (*domain.Category, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Category, error)
+	Replace(ctx context.Context, id uuid.UUID, category *domain.ReplaceCategoryRequest) (*domain.Category, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context) ([]domain.Category, error)
+	CountExisting(ctx context.Context, ids []uuid.UUID) (int, error)
+	IsDescendant(ctx context.Context, ancestorID, id uuid.UUID) (bool, error)
+	LockTree(ctx context.Context) error
+	HasChildren(ctx context.Context, id uuid.UUID) (bool, error)
+	IsUsedByProducts(ctx context.Context, id uuid.UUID) (bool, error)
+	Path(ctx context.Context, id uuid.UUID) ([]domain.CategoryRef, error)
+	PathsForProducts(ctx context.Context, productIDs []uuid.UUID) (map[uuid.UUID][]domain.CategoryPath, error)
+	SetProductCategories(ctx context.Context, productID uuid.UUID, categoryIDs []uuid.UUID) error
+}
+
+// categoryTreeLockID is the transaction-level advisory lock that serialises
+// changes to the category hierarchy.
+const categoryTreeLockID = 7245193022
+
+// maxCategoryDepth bounds the ancestry walks, so they end even if the tree
+// somehow contains a cycle.
+const maxCategoryDepth = 64
+
+type categoryRepository struct {
+	db *sqlx.DB
+}
+
+func NewCategoryRepository(db *sqlx.DB) CategoryRepository {
+	return &categoryRepository{db: db}
+}
+
+func (r *categoryRepository) Create(ctx context.Context, req *domain.CreateCategoryRequest) (*domain.Category, error) {
+	query := `
+		INSERT INTO categories (category_name, parent_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, category_name, parent_id, created_at, updated_at`
+
+	now := time.Now()
+	var category domain.Category
+
+	err := conn(ctx, r.db).QueryRowxContext(ctx, query, req.CategoryName, req.ParentID, now, now).StructScan(&category)
+	if err != nil {
+		return nil, translateError(err, domain.ErrCategoryNotFound)
+	}
+
+	return &category, nil
+}
+
+func (r *categoryRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Category, error) {
+	query := `
+		SELECT id, category_name, parent_id, created_at, updated_at
+		FROM categories
+		WHERE id = $1`
+
+	var category domain.Category
+	err := conn(ctx, r.db).GetContext(ctx, &category, query, id)
+	if err != nil {
+		return nil, translateError(err, domain.ErrCategoryNotFound)
+	}
+
+	return &category, nil
+}
+
+func (r *categoryRepository) Replace(ctx context.Context, id uuid.UUID, req *domain.ReplaceCategoryRequest) (*domain.Category, error) {
+	query := `
+		UPDATE categories
+		SET category_name = $1, parent_id = $2, updated_at = $3
+		WHERE id = $4
+		RETURNING id, category_name, parent_id, created_at, updated_at`
+
+	var category domain.Category
+	err := conn(ctx, r.db).QueryRowxContext(ctx, query, req.CategoryName, req.ParentID, time.Now(), id).StructScan(&category)
+	if err != nil {
+		return nil, translateError(err, domain.ErrCategoryNotFound)
+	}
+
+	return &category, nil
+}
+
+func (r *categoryRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM categories WHERE id = $1`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, id)
+	if err != nil {
+		return translateError(err, domain.ErrCategoryNotFound)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrCategoryNotFound
+	}
+
+	return nil
+}
+
+func (r *categoryRepository) List(ctx context.Context) ([]domain.Category, error) {
+	query := `
+		SELECT id, category_name, parent_id, created_at, updated_at
+		FROM categories
+		ORDER BY category_name ASC`
+
+	var categories []domain.Category
+	err := conn(ctx, r.db).SelectContext(ctx, &categories, query)
+	return categories, err
+}
+
+func (r *categoryRepository) CountExisting(ctx context.Context, ids []uuid.UUID) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM categories WHERE id = ANY($1::uuid[])`
+	err := conn(ctx, r.db).GetContext(ctx, &count, query, uuidArray(ids))
+	return count, err
+}
+
+// IsDescendant reports whether id is ancestorID itself or lies anywhere below it.
+func (r *categoryRepository) IsDescendant(ctx context.Context, ancestorID, id uuid.UUID) (bool, error) {
+	query := `
+		WITH RECURSIVE tree AS (
+			SELECT id FROM categories WHERE id = $1
+			UNION
+			SELECT c.id FROM categories c JOIN tree t ON c.parent_id = t.id
+		)
+		SELECT EXISTS (SELECT 1 FROM tree WHERE id = $2)`
+
+	var exists bool
+	err := conn(ctx, r.db).GetContext(ctx, &exists, query, ancestorID, id)
+	return exists, err
+}
+
+// LockTree takes the category hierarchy lock until the transaction ends. A
+// re-parent holds it from its cycle check to its write, so two moves cannot
+// each pass the check and together form a cycle.
+func (r *categoryRepository) LockTree(ctx context.Context) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, categoryTreeLockID)
+	return err
+}
+
+func (r *categoryRepository) HasChildren(ctx context.Context, id uuid.UUID) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM categories WHERE parent_id = $1)`
+	err := conn(ctx, r.db).GetContext(ctx, &exists, query, id)
+	return exists, err
+}
+
+func (r *categoryRepository) IsUsedByProducts(ctx context.Context, id uuid.UUID) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM product_categories WHERE category_id = $1)`
+	err := conn(ctx, r.db).GetContext(ctx, &exists, query, id)
+	return exists, err
+}
+
+// Path returns the breadcrumb from the root category down to id.
+func (r *categoryRepository) Path(ctx context.Context, id uuid.UUID) ([]domain.CategoryRef, error) {
+	query := `
+		WITH RECURSIVE ancestry AS (
+			SELECT id, category_name, parent_id, 0 AS depth FROM categories WHERE id = $1
+			UNION ALL
+			SELECT c.id, c.category_name, c.parent_id, a.depth + 1
+			FROM categories c JOIN ancestry a ON c.id = a.parent_id
+			WHERE a.depth < $2
+		)
+		SELECT id, category_name FROM ancestry ORDER BY depth DESC`
+
+	var path []domain.CategoryRef
+	err := conn(ctx, r.db).SelectContext(ctx, &path, query, id, maxCategoryDepth)
+	return path, err
+}
+
+// PathsForProducts returns, per product, the breadcrumb of every category the
+// product is assigned to, in one round trip.
+func (r *categoryRepository) PathsForProducts(ctx context.Context, productIDs []uuid.UUID) (map[uuid.UUID][]domain.CategoryPath, error) {
+	paths := map[uuid.UUID][]domain.CategoryPath{}
+	if len(productIDs) == 0 {
+		return paths, nil
+	}
+
+	query := `
+		WITH RECURSIVE ancestry AS (
+			SELECT pc.product_id, pc.category_id AS leaf_id, c.id, c.category_name, c.parent_id, 0 AS depth
+			FROM product_categories pc
+			JOIN categories c ON c.id = pc.category_id
+			WHERE pc.product_id = ANY($1::uuid[])
+			UNION ALL
+			SELECT a.product_id, a.leaf_id, c.id, c.category_name, c.parent_id, a.depth + 1
+			FROM ancestry a
+			JOIN categories c ON c.id = a.parent_id
+			WHERE a.depth < $2
+		)
+		SELECT product_id, leaf_id, id, category_name
+		FROM ancestry
+		ORDER BY product_id, leaf_id, depth DESC`
+
+	var rows []struct {
+		ProductID uuid.UUID `db:"product_id"`
+		LeafID    uuid.UUID `db:"leaf_id"`
+		domain.CategoryRef
+	}
+	if err := conn(ctx, r.db).SelectContext(ctx, &rows, query, uuidArray(productIDs), maxCategoryDepth); err != nil {
+		return nil, err
+	}
+
+	var current domain.CategoryPath
+	for i, row := range rows {
+		current = append(current, row.CategoryRef)
+		last := i == len(rows)-1 || rows[i+1].ProductID != row.ProductID || rows[i+1].LeafID != row.LeafID
+		if last {
+			paths[row.ProductID] = append(paths[row.ProductID], current)
+			current = nil
+		}
+	}
+
+	return paths, nil
+}
+
+// SetProductCategories replaces the product's category assignments in a
+// single statement, so the set is never observed half-updated.
+func (r *categoryRepository) SetProductCategories(ctx context.Context, productID uuid.UUID, categoryIDs []uuid.UUID) error {
+	query := `
+		WITH removed AS (
+			DELETE FROM product_categories
+			WHERE product_id = $1 AND NOT (category_id = ANY($2::uuid[]))
+		)
+		INSERT INTO product_categories (product_id, category_id)
+		SELECT $1, unnest($2::uuid[])
+		ON CONFLICT DO NOTHING`
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, productID, uuidArray(categoryIDs))
+	return translateError(err, domain.ErrProductNotFound)
+}
+
+func uuidArray(ids []uuid.UUID) pq.StringArray {
+	array := make(pq.StringArray, len(ids))
+	for i, id := range ids {
+		array[i] = id.String()
+	}
+	return array
+}
@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/rezajo220/ecommerce/internal/domain"
+)
+
+// CurrencyRepository stores exchange rates and per-currency price overrides
+// of products. Currency codes are expected in upper case.
+type CurrencyRepository interface {
+	ListRates(ctx context.Context) ([]domain.ExchangeRate, error)
+	UpsertRate(ctx context.Context, base, quote string, rate domain.Decimal) (*domain.ExchangeRate, error)
+	DeleteRate(ctx context.Context, base, quote string) error
+	ListPrices(ctx context.Context, productID uuid.UUID) ([]domain.ProductCurrencyPrice, error)
+	PricesForProducts(ctx context.Context, productIDs []uuid.UUID, currency string) (map[uuid.UUID]domain.Decimal, error)
+	GetPrice(ctx context.Context, productID uuid.UUID, currency string) (*domain.ProductCurrencyPrice, error)
+	UpsertPrice(ctx context.Context, productID uuid.UUID, currency string, price domain.Decimal) (*domain.ProductCurrencyPrice, error)
+	DeletePrice(ctx context.Context, productID uuid.UUID, currency string) error
+}
+
+type currencyRepository struct {
+	db *sqlx.DB
+}
+
+func NewCurrencyRepository(db *sqlx.DB) CurrencyRepository {
+	return &currencyRepository{db: db}
+}
+
+func (r *currencyRepository) ListRates(ctx context.Context) ([]domain.ExchangeRate, error) {
+	query := `
+		SELECT base_currency, quote_currency, rate, updated_at
+		FROM exchange_rates
+		ORDER BY base_currency, quote_currency`
+
+	rates := []domain.ExchangeRate{}
+	err := conn(ctx, r.db).SelectContext(ctx, &rates, query)
+	return rates, err
+}
+
+func (r *currencyRepository) UpsertRate(ctx context.Context, base, quote string, rate domain.Decimal) (*domain.ExchangeRate, error) {
+	query := `
+		INSERT INTO exchange_rates (base_currency, quote_currency, rate, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (base_currency, quote_currency)
+		DO UPDATE SET rate = EXCLUDED.rate, updated_at = EXCLUDED.updated_at
+		RETURNING base_currency, quote_currency, rate, updated_at`
+
+	var stored domain.ExchangeRate
+	err := conn(ctx, r.db).QueryRowxContext(ctx, query, base, quote, rate, time.Now()).StructScan(&stored)
+	if err != nil {
+		return nil, translateError(err, domain.ErrExchangeRateNotFound)
+	}
+
+	return &stored, nil
+}
+
+func (r *currencyRepository) DeleteRate(ctx context.Context, base, quote string) error {
+	query := `DELETE FROM exchange_rates WHERE base_currency = $1 AND quote_currency = $2`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, base, quote)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrExchangeRateNotFound
+	}
+
+	return nil
+}
+
+func (r *currencyRepository) ListPrices(ctx context.Context, productID uuid.UUID) ([]domain.ProductCurrencyPrice, error) {
+	query := `
+		SELECT product_id, currency, price, created_at, updated_at
+		FROM product_currency_prices
+		WHERE product_id = $1
+		ORDER BY currency`
+
+	prices := []domain.ProductCurrencyPrice{}
+	err := conn(ctx, r.db).SelectContext(ctx, &prices, query, productID)
+	return prices, err
+}
+
+// PricesForProducts returns the overrides in currency of the given products,
+// keyed by product; products without one are left out.
+func (r *currencyRepository) PricesForProducts(ctx context.Context, productIDs []uuid.UUID, currency string) (map[uuid.UUID]domain.Decimal, error) {
+	byProduct := map[uuid.UUID]domain.Decimal{}
+	if len(productIDs) == 0 {
+		return byProduct, nil
+	}
+
+	query := `
+		SELECT product_id, currency, price, created_at, updated_at
+		FROM product_currency_prices
+		WHERE product_id = ANY($1::uuid[]) AND currency = $2`
+
+	var prices []domain.ProductCurrencyPrice
+	if err := conn(ctx, r.db).SelectContext(ctx, &prices, query, uuidArray(productIDs), currency); err != nil {
+		return nil, err
+	}
+
+	for _, price := range prices {
+		byProduct[price.ProductID] = price.Price
+	}
+	return byProduct, nil
+}
+
+func (r *currencyRepository) GetPrice(ctx context.Context, productID uuid.UUID, currency string) (*domain.ProductCurrencyPrice, error) {
+	query := `
+		SELECT product_id, currency, price, created_at, updated_at
+		FROM product_currency_prices
+		WHERE product_id = $1 AND currency = $2`
+
+	var price domain.ProductCurrencyPrice
+	err := conn(ctx, r.db).GetContext(ctx, &price, query, productID, currency)
+	if err != nil {
+		return nil, translateError(err, domain.ErrCurrencyPriceNotFound)
+	}
+
+	return &price, nil
+}
+
+func (r *currencyRepository) UpsertPrice(ctx context.Context, productID uuid.UUID, currency string, price domain.Decimal) (*domain.ProductCurrencyPrice, error) {
+	query := `
+		INSERT INTO product_currency_prices (product_id, currency, price, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $4)
+		ON CONFLICT (product_id, currency)
+		DO UPDATE SET price = EXCLUDED.price, updated_at = EXCLUDED.updated_at
+		RETURNING product_id, currency, price, created_at, updated_at`
+
+	var stored domain.ProductCurrencyPrice
+	err := conn(ctx, r.db).QueryRowxContext(ctx, query, productID, currency, price, time.Now()).StructScan(&stored)
+	if err != nil {
+		return nil, translateError(err, domain.ErrProductNotFound)
+	}
+
+	return &stored, nil
+}
+
+func (r *currencyRepository) DeletePrice(ctx context.Context, productID uuid.UUID, currency string) error {
+	query := `DELETE FROM product_currency_prices WHERE product_id = $1 AND currency = $2`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, productID, currency)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrCurrencyPriceNotFound
+	}
+
+	return nil
+}
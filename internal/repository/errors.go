@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/rezajo220/ecommerce/internal/domain"
+)
+
+const (
+	pqForeignKeyViolation = "23503"
+	pqUniqueViolation     = "23505"
+	pqCheckViolation      = "23514"
+)
+
+// translateError converts driver errors into domain errors. notFound is
+// returned in place of sql.ErrNoRows so callers never see the raw driver
+// sentinel.
+func translateError(err error, notFound error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return notFound
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case pqUniqueViolation:
+			return domain.NewConflictError("resource already exists")
+		case pqForeignKeyViolation:
+			return domain.NewConflictError("operation violates a reference to another resource")
+		case pqCheckViolation:
+			return domain.NewValidationError("value violates constraint %s", pqErr.Constraint)
+		}
+	}
+
+	return err
+}
+
+// staleOrNotFound is the error for a write to table guarded by id and version
+// that matched no row: domain.ErrVersionMismatch when the row still exists,
+// notFound otherwise.
+func staleOrNotFound(ctx context.Context, db *sqlx.DB, table string, id uuid.UUID, notFound error) error {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM ` + table + ` WHERE id = $1 AND deleted_at IS NULL)`
+	if err := conn(ctx, db).GetContext(ctx, &exists, query, id); err != nil {
+		return err
+	}
+	if exists {
+		return domain.ErrVersionMismatch
+	}
+	return notFound
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == pqUniqueViolation
+}
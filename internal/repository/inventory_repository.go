@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/rezajo220/ecommerce/internal/domain"
+)
+
+type InventoryRepository interface {
+	LockStock(ctx context.Context, productID uuid.UUID) (*domain.StockLevel, error)
+	LockStockIncludingDeleted(ctx context.Context, productID uuid.UUID) (*domain.StockLevel, error)
+	RecordMovement(ctx context.Context, movement *domain.InventoryMovement) (*domain.InventoryMovement, error)
+	ListMovements(ctx context.Context, productID uuid.UUID, limit, offset int) ([]domain.InventoryMovement, int, error)
+	Reconcile(ctx context.Context) ([]domain.StockDiscrepancy, error)
+}
+
+type inventoryRepository struct {
+	db *sqlx.DB
+}
+
+func NewInventoryRepository(db *sqlx.DB) InventoryRepository {
+	return &inventoryRepository{db: db}
+}
+
+// stockLevelQuery reads a product's stock and what active reservations hold
+// of it, locking the product row.
+const stockLevelQuery = `
+	SELECT p.qty, COALESCE((
+		SELECT SUM(sr.quantity) FROM stock_reservations sr
+		WHERE sr.product_id = p.id AND sr.status = 'active' AND sr.expires_at > now()
+	), 0) AS reserved
+	FROM products p
+	WHERE p.id = $1`
+
+// LockStock reads the stock of a product that is not deleted and locks the
+// row until the surrounding transaction ends, so concurrent movements and
+// reservations of the product are applied one after another.
+func (r *inventoryRepository) LockStock(ctx context.Context, productID uuid.UUID) (*domain.StockLevel, error) {
+	return r.lockStock(ctx, stockLevelQuery+` AND p.deleted_at IS NULL FOR UPDATE OF p`, productID)
+}
+
+// LockStockIncludingDeleted is LockStock for goods coming back from a
+// cancelled or refunded order, whose product may have been deleted since.
+func (r *inventoryRepository) LockStockIncludingDeleted(ctx context.Context, productID uuid.UUID) (*domain.StockLevel, error) {
+	return r.lockStock(ctx, stockLevelQuery+` FOR UPDATE OF p`, productID)
+}
+
+func (r *inventoryRepository) lockStock(ctx context.Context, query string, productID uuid.UUID) (*domain.StockLevel, error) {
+	var level domain.StockLevel
+	err := conn(ctx, r.db).GetContext(ctx, &level, query, productID)
+	if err != nil {
+		return nil, translateError(err, domain.ErrProductNotFound)
+	}
+	return &level, nil
+}
+
+// RecordMovement appends movement to the ledger and adds its Quantity to
+// products.qty in the same statement. QtyAfter is computed by the database
+// in NUMERIC, so the ledger and products.qty never drift apart by rounding.
+func (r *inventoryRepository) RecordMovement(ctx context.Context, movement *domain.InventoryMovement) (*domain.InventoryMovement, error) {
+	query := `
+		WITH updated AS (
+			UPDATE products SET qty = qty + $3, updated_at = $6
+			WHERE id = $1
+			RETURNING id, qty
+		)
+		INSERT INTO inventory_movements (product_id, movement_type, quantity, qty_after, reason, actor, created_at)
+		SELECT id, $2, $3, qty, $4, $5, $6 FROM updated
+		RETURNING id, product_id, movement_type, quantity, qty_after, reason, actor, created_at`
+
+	var recorded domain.InventoryMovement
+	err := conn(ctx, r.db).QueryRowxContext(ctx, query,
+		movement.ProductID, movement.MovementType, movement.Quantity,
+		movement.Reason, movement.Actor, time.Now(),
+	).StructScan(&recorded)
+	if err != nil {
+		return nil, translateError(err, domain.ErrProductNotFound)
+	}
+
+	return &recorded, nil
+}
+
+func (r *inventoryRepository) ListMovements(ctx context.Context, productID uuid.UUID, limit, offset int) ([]domain.InventoryMovement, int, error) {
+	var total int
+	countQuery := `SELECT COUNT(*) FROM inventory_movements WHERE product_id = $1`
+	if err := conn(ctx, r.db).GetContext(ctx, &total, countQuery, productID); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT id, product_id, movement_type, quantity, qty_after, reason, actor, created_at
+		FROM inventory_movements
+		WHERE product_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3`
+
+	var movements []domain.InventoryMovement
+	err := conn(ctx, r.db).SelectContext(ctx, &movements, query, productID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return movements, total, nil
+}
+
+// Reconcile lists the products whose qty differs from the sum of their movements.
+func (r *inventoryRepository) Reconcile(ctx context.Context) ([]domain.StockDiscrepancy, error) {
+	query := `
+		SELECT p.id AS product_id, p.product_name, p.qty, COALESCE(SUM(m.quantity), 0) AS ledger_qty
+		FROM products p
+		LEFT JOIN inventory_movements m ON m.product_id = p.id
+		GROUP BY p.id, p.product_name, p.qty
+		HAVING p.qty <> COALESCE(SUM(m.quantity), 0)
+		ORDER BY p.product_name ASC`
+
+	discrepancies := []domain.StockDiscrepancy{}
+	err := conn(ctx, r.db).SelectContext(ctx, &discrepancies, query)
+	return discrepancies, err
+}
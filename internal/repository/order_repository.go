@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/rezajo220/ecommerce/internal/domain"
+)
+
+type OrderRepository interface {
+	Create(ctx context.Context, order *domain.Order) (*domain.Order, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Order, error)
+	LockByID(ctx context.Context, id uuid.UUID) (*domain.Order, error)
+	UpdateStatus(ctx context.Context, id uuid.UUID, status domain.OrderStatus) (*domain.Order, error)
+	List(ctx context.Context, status domain.OrderStatus, limit, offset int) ([]domain.Order, int, error)
+	ListItems(ctx context.Context, orderID uuid.UUID) ([]domain.OrderItem, error)
+	ListPendingBefore(ctx context.Context, before time.Time) ([]uuid.UUID, error)
+}
+
+type orderRepository struct {
+	db *sqlx.DB
+}
+
+func NewOrderRepository(db *sqlx.DB) OrderRepository {
+	return &orderRepository{db: db}
+}
+
+// Create stores the order and its items. Call it inside a transaction so a
+// failing item does not leave a partial order behind.
+func (r *orderRepository) Create(ctx context.Context, order *domain.Order) (*domain.Order, error) {
+	query := `
+		INSERT INTO orders (cart_id, holder_id, status, total, currency, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, cart_id, holder_id, status, total, currency, created_at, updated_at`
+
+	now := time.Now()
+	var created domain.Order
+
+	err := conn(ctx, r.db).QueryRowxContext(ctx, query, order.CartID, order.HolderID, order.Status, order.Total, order.Currency, now, now).StructScan(&created)
+	if err != nil {
+		return nil, translateError(err, domain.ErrOrderNotFound)
+	}
+
+	itemQuery := `
+		INSERT INTO order_items (order_id, product_id, product_name, unit_price, currency, qty, line_total)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, order_id, product_id, product_name, unit_price, currency, qty, line_total`
+
+	for _, item := range order.Items {
+		var createdItem domain.OrderItem
+		err := conn(ctx, r.db).QueryRowxContext(ctx, itemQuery,
+			created.ID, item.ProductID, item.ProductName, item.UnitPrice, item.Currency, item.Qty, item.LineTotal,
+		).StructScan(&createdItem)
+		if err != nil {
+			return nil, translateError(err, domain.ErrProductNotFound)
+		}
+		created.Items = append(created.Items, createdItem)
+	}
+
+	return &created, nil
+}
+
+func (r *orderRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Order, error) {
+	query := `
+		SELECT id, cart_id, holder_id, status, total, currency, created_at, updated_at
+		FROM orders
+		WHERE id = $1`
+
+	var order domain.Order
+	err := conn(ctx, r.db).GetContext(ctx, &order, query, id)
+	if err != nil {
+		return nil, translateError(err, domain.ErrOrderNotFound)
+	}
+
+	return &order, nil
+}
+
+// LockByID is GetByID that also locks the order until the surrounding
+// transaction ends, so concurrent status changes are applied in turn.
+func (r *orderRepository) LockByID(ctx context.Context, id uuid.UUID) (*domain.Order, error) {
+	query := `
+		SELECT id, cart_id, holder_id, status, total, currency, created_at, updated_at
+		FROM orders
+		WHERE id = $1
+		FOR UPDATE`
+
+	var order domain.Order
+	err := conn(ctx, r.db).GetContext(ctx, &order, query, id)
+	if err != nil {
+		return nil, translateError(err, domain.ErrOrderNotFound)
+	}
+
+	return &order, nil
+}
+
+func (r *orderRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status domain.OrderStatus) (*domain.Order, error) {
+	query := `
+		UPDATE orders
+		SET status = $1, updated_at = $2
+		WHERE id = $3
+		RETURNING id, cart_id, holder_id, status, total, currency, created_at, updated_at`
+
+	var order domain.Order
+	err := conn(ctx, r.db).QueryRowxContext(ctx, query, status, time.Now(), id).StructScan(&order)
+	if err != nil {
+		return nil, translateError(err, domain.ErrOrderNotFound)
+	}
+
+	return &order, nil
+}
+
+// List returns orders newest first, optionally only those in status.
+func (r *orderRepository) List(ctx context.Context, status domain.OrderStatus, limit, offset int) ([]domain.Order, int, error) {
+	var total int
+	countQuery := `SELECT COUNT(*) FROM orders WHERE $1 = '' OR status = $1`
+	if err := conn(ctx, r.db).GetContext(ctx, &total, countQuery, status); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT id, cart_id, holder_id, status, total, currency, created_at, updated_at
+		FROM orders
+		WHERE $1 = '' OR status = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3`
+
+	orders := []domain.Order{}
+	if err := conn(ctx, r.db).SelectContext(ctx, &orders, query, status, limit, offset); err != nil {
+		return nil, 0, err
+	}
+
+	return orders, total, nil
+}
+
+func (r *orderRepository) ListItems(ctx context.Context, orderID uuid.UUID) ([]domain.OrderItem, error) {
+	query := `
+		SELECT id, order_id, product_id, product_name, unit_price, currency, qty, line_total
+		FROM order_items
+		WHERE order_id = $1
+		ORDER BY product_name ASC, id ASC`
+
+	var items []domain.OrderItem
+	err := conn(ctx, r.db).SelectContext(ctx, &items, query, orderID)
+	return items, err
+}
+
+// ListPendingBefore lists the orders still pending that were placed before
+// the given time, oldest first.
+func (r *orderRepository) ListPendingBefore(ctx context.Context, before time.Time) ([]uuid.UUID, error) {
+	query := `
+		SELECT id FROM orders
+		WHERE status = 'pending' AND created_at < $1
+		ORDER BY created_at ASC`
+
+	var ids []uuid.UUID
+	err := conn(ctx, r.db).SelectContext(ctx, &ids, query, before)
+	return ids, err
+}
@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/rezajo220/ecommerce/internal/domain"
+)
+
+type PaymentRepository interface {
+	Create(ctx context.Context, payment *domain.Payment) (*domain.Payment, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Payment, error)
+	LockByID(ctx context.Context, id uuid.UUID) (*domain.Payment, error)
+	LockByProviderRef(ctx context.Context, provider, providerRef string) (*domain.Payment, error)
+	UpdateStatus(ctx context.Context, id uuid.UUID, status domain.PaymentStatus) (*domain.Payment, error)
+	RecordAuthorization(ctx context.Context, id uuid.UUID, providerRef string, status domain.PaymentStatus) (*domain.Payment, error)
+	HasOpenPayment(ctx context.Context, orderID uuid.UUID) (bool, error)
+	ListUnsettledBefore(ctx context.Context, before time.Time) ([]domain.Payment, error)
+	ListByOrder(ctx context.Context, orderID uuid.UUID) ([]domain.Payment, error)
+	RecordEvent(ctx context.Context, eventID string, paymentID uuid.UUID, eventType domain.PaymentEventType, payload []byte) (bool, error)
+}
+
+type paymentRepository struct {
+	db *sqlx.DB
+}
+
+func NewPaymentRepository(db *sqlx.DB) PaymentRepository {
+	return &paymentRepository{db: db}
+}
+
+// paymentColumns reads a processing payment's missing provider reference as
+// an empty string.
+const paymentColumns = `id, order_id, provider, COALESCE(provider_ref, '') AS provider_ref, amount, status, created_at, updated_at`
+
+// Create stores a payment. A payment without a ProviderRef is stored with
+// none, as processing payments are.
+func (r *paymentRepository) Create(ctx context.Context, payment *domain.Payment) (*domain.Payment, error) {
+	query := `
+		INSERT INTO payments (order_id, provider, provider_ref, amount, status, created_at, updated_at)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7)
+		RETURNING ` + paymentColumns
+
+	now := time.Now()
+	var created domain.Payment
+
+	err := conn(ctx, r.db).QueryRowxContext(ctx, query,
+		payment.OrderID, payment.Provider, payment.ProviderRef, payment.Amount, payment.Status, now, now,
+	).StructScan(&created)
+	if err != nil {
+		return nil, translateError(err, domain.ErrOrderNotFound)
+	}
+
+	return &created, nil
+}
+
+func (r *paymentRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Payment, error) {
+	query := `
+		SELECT ` + paymentColumns + `
+		FROM payments
+		WHERE id = $1`
+
+	var payment domain.Payment
+	err := conn(ctx, r.db).GetContext(ctx, &payment, query, id)
+	if err != nil {
+		return nil, translateError(err, domain.ErrPaymentNotFound)
+	}
+
+	return &payment, nil
+}
+
+// LockByID is GetByID that also locks the payment until the surrounding
+// transaction ends.
+func (r *paymentRepository) LockByID(ctx context.Context, id uuid.UUID) (*domain.Payment, error) {
+	query := `
+		SELECT ` + paymentColumns + `
+		FROM payments
+		WHERE id = $1
+		FOR UPDATE`
+
+	var payment domain.Payment
+	err := conn(ctx, r.db).GetContext(ctx, &payment, query, id)
+	if err != nil {
+		return nil, translateError(err, domain.ErrPaymentNotFound)
+	}
+
+	return &payment, nil
+}
+
+// LockByProviderRef finds the payment a provider knows as providerRef and
+// locks it until the surrounding transaction ends.
+func (r *paymentRepository) LockByProviderRef(ctx context.Context, provider, providerRef string) (*domain.Payment, error) {
+	query := `
+		SELECT ` + paymentColumns + `
+		FROM payments
+		WHERE provider = $1 AND provider_ref = $2
+		FOR UPDATE`
+
+	var payment domain.Payment
+	err := conn(ctx, r.db).GetContext(ctx, &payment, query, provider, providerRef)
+	if err != nil {
+		return nil, translateError(err, domain.ErrPaymentNotFound)
+	}
+
+	return &payment, nil
+}
+
+func (r *paymentRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status domain.PaymentStatus) (*domain.Payment, error) {
+	query := `
+		UPDATE payments
+		SET status = $1, updated_at = $2
+		WHERE id = $3
+		RETURNING ` + paymentColumns
+
+	var payment domain.Payment
+	err := conn(ctx, r.db).QueryRowxContext(ctx, query, status, time.Now(), id).StructScan(&payment)
+	if err != nil {
+		return nil, translateError(err, domain.ErrPaymentNotFound)
+	}
+
+	return &payment, nil
+}
+
+// RecordAuthorization stores the gateway's reference and answer on a
+// processing payment. A payment that is no longer processing, because it was
+// given up as stale meanwhile, is not changed and reported as not found.
+func (r *paymentRepository) RecordAuthorization(ctx context.Context, id uuid.UUID, providerRef string, status domain.PaymentStatus) (*domain.Payment, error) {
+	query := `
+		UPDATE payments
+		SET provider_ref = $1, status = $2, updated_at = $3
+		WHERE id = $4 AND status = 'processing'
+		RETURNING ` + paymentColumns
+
+	var payment domain.Payment
+	err := conn(ctx, r.db).QueryRowxContext(ctx, query, providerRef, status, time.Now(), id).StructScan(&payment)
+	if err != nil {
+		return nil, translateError(err, domain.ErrPaymentNotFound)
+	}
+
+	return &payment, nil
+}
+
+// HasOpenPayment reports whether the order has a payment that is in flight
+// or has taken the money.
+func (r *paymentRepository) HasOpenPayment(ctx context.Context, orderID uuid.UUID) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM payments WHERE order_id = $1 AND status IN ('processing', 'authorized', 'captured'))`
+	err := conn(ctx, r.db).GetContext(ctx, &exists, query, orderID)
+	return exists, err
+}
+
+// ListUnsettledBefore lists payments left processing or authorized since
+// before, oldest first.
+func (r *paymentRepository) ListUnsettledBefore(ctx context.Context, before time.Time) ([]domain.Payment, error) {
+	query := `
+		SELECT ` + paymentColumns + `
+		FROM payments
+		WHERE status IN ('processing', 'authorized') AND updated_at < $1
+		ORDER BY updated_at ASC, id ASC`
+
+	payments := []domain.Payment{}
+	err := conn(ctx, r.db).SelectContext(ctx, &payments, query, before)
+	return payments, err
+}
+
+func (r *paymentRepository) ListByOrder(ctx context.Context, orderID uuid.UUID) ([]domain.Payment, error) {
+	query := `
+		SELECT ` + paymentColumns + `
+		FROM payments
+		WHERE order_id = $1
+		ORDER BY created_at ASC, id ASC`
+
+	payments := []domain.Payment{}
+	err := conn(ctx, r.db).SelectContext(ctx, &payments, query, orderID)
+	return payments, err
+}
+
+// RecordEvent stores a webhook event and reports whether it is new; false
+// means the event was processed before.
+func (r *paymentRepository) RecordEvent(ctx context.Context, eventID string, paymentID uuid.UUID, eventType domain.PaymentEventType, payload []byte) (bool, error) {
+	query := `
+		INSERT INTO payment_events (event_id, payment_id, event_type, payload, received_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (event_id) DO NOTHING`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, eventID, paymentID, eventType, string(payload), time.Now())
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected == 1, nil
+}
@@ -0,0 +1,282 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/rezajo220/ecommerce/internal/domain"
+)
+
+// ProductPriceRepository keeps the price history of products. Callers
+// writing to a product's history hold the product's row lock, so the
+// entries of one product are never rearranged concurrently.
+type ProductPriceRepository interface {
+	Record(ctx context.Context, price *domain.ProductPrice) (*domain.ProductPrice, error)
+	GetByID(ctx context.Context, productID, id uuid.UUID) (*domain.ProductPrice, error)
+	At(ctx context.Context, productID uuid.UUID, at time.Time) (*domain.ProductPrice, error)
+	ListByProduct(ctx context.Context, productID uuid.UUID, limit, offset int) ([]domain.ProductPrice, int, error)
+	HasHistory(ctx context.Context, productID uuid.UUID) (bool, error)
+	DeleteScheduled(ctx context.Context, productID, id uuid.UUID) error
+	CancelScheduledInOtherCurrencies(ctx context.Context, productID uuid.UUID, currency string) error
+	DueProductIDs(ctx context.Context, now time.Time) ([]uuid.UUID, error)
+	LockDue(ctx context.Context, productID uuid.UUID, now time.Time) ([]domain.ProductPrice, error)
+	ApplyDue(ctx context.Context, productID, id uuid.UUID, appliedAt time.Time) error
+}
+
+type productPriceRepository struct {
+	db *sqlx.DB
+}
+
+func NewProductPriceRepository(db *sqlx.DB) ProductPriceRepository {
+	return &productPriceRepository{db: db}
+}
+
+const productPriceColumns = `id, product_id, price, currency, effective_from, effective_to, applied_at, actor, created_at`
+
+// Record inserts price into the product's timeline at price.EffectiveFrom.
+// The entry in effect at that moment now ends there, and the new one lasts
+// until the next entry starts, so a price recorded before a scheduled one
+// gives way to it. An entry starting at the same instant as an existing one
+// replaces it. An applied entry also drops scheduled prices that were due
+// before it but not yet applied: they were never charged, and the scheduler
+// cannot bring them back.
+func (r *productPriceRepository) Record(ctx context.Context, price *domain.ProductPrice) (*domain.ProductPrice, error) {
+	db := conn(ctx, r.db)
+
+	superseded := int64(0)
+	if price.AppliedAt != nil {
+		query := `DELETE FROM product_prices WHERE product_id = $1 AND applied_at IS NULL AND effective_from < $2`
+		result, err := db.ExecContext(ctx, query, price.ProductID, price.EffectiveFrom)
+		if err != nil {
+			return nil, err
+		}
+		if superseded, err = result.RowsAffected(); err != nil {
+			return nil, err
+		}
+	}
+
+	var next *time.Time
+	nextQuery := `SELECT MIN(effective_from) FROM product_prices WHERE product_id = $1 AND effective_from > $2`
+	if err := db.GetContext(ctx, &next, nextQuery, price.ProductID, price.EffectiveFrom); err != nil {
+		return nil, err
+	}
+
+	closeQuery := `
+		UPDATE product_prices SET effective_to = $1
+		WHERE product_id = $2 AND effective_from < $1 AND (effective_to IS NULL OR effective_to > $1)`
+	if _, err := db.ExecContext(ctx, closeQuery, price.EffectiveFrom, price.ProductID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO product_prices (product_id, price, currency, effective_from, effective_to, applied_at, actor, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (product_id, effective_from)
+		DO UPDATE SET price = EXCLUDED.price, currency = EXCLUDED.currency, applied_at = EXCLUDED.applied_at,
+			actor = EXCLUDED.actor, created_at = EXCLUDED.created_at
+		RETURNING ` + productPriceColumns
+
+	var stored domain.ProductPrice
+	err := db.QueryRowxContext(ctx, query, price.ProductID, price.Price, price.Currency, price.EffectiveFrom, next,
+		price.AppliedAt, price.Actor, time.Now()).StructScan(&stored)
+	if err != nil {
+		return nil, translateError(err, domain.ErrProductNotFound)
+	}
+
+	if superseded > 0 {
+		if err := r.relink(ctx, price.ProductID); err != nil {
+			return nil, err
+		}
+	}
+
+	return &stored, nil
+}
+
+func (r *productPriceRepository) GetByID(ctx context.Context, productID, id uuid.UUID) (*domain.ProductPrice, error) {
+	query := `SELECT ` + productPriceColumns + ` FROM product_prices WHERE id = $1 AND product_id = $2`
+
+	var price domain.ProductPrice
+	err := conn(ctx, r.db).GetContext(ctx, &price, query, id, productID)
+	if err != nil {
+		return nil, translateError(err, domain.ErrProductPriceNotFound)
+	}
+
+	return &price, nil
+}
+
+// At returns the entry in effect at the given time. Scheduled prices count
+// from their effective_from while it lies ahead; once it has passed, the
+// product still sells at the price before until the scheduler applies them,
+// so the entry before is returned instead.
+func (r *productPriceRepository) At(ctx context.Context, productID uuid.UUID, at time.Time) (*domain.ProductPrice, error) {
+	query := `
+		SELECT ` + productPriceColumns + `
+		FROM product_prices
+		WHERE product_id = $1 AND effective_from <= $2 AND (applied_at IS NOT NULL OR effective_from > $3)
+		ORDER BY effective_from DESC
+		LIMIT 1`
+
+	var price domain.ProductPrice
+	err := conn(ctx, r.db).GetContext(ctx, &price, query, productID, at, time.Now())
+	if err != nil {
+		return nil, translateError(err, domain.ErrNoPriceAtTime)
+	}
+
+	return &price, nil
+}
+
+// ListByProduct lists the product's history newest first, scheduled prices
+// included.
+func (r *productPriceRepository) ListByProduct(ctx context.Context, productID uuid.UUID, limit, offset int) ([]domain.ProductPrice, int, error) {
+	var total int
+	countQuery := `SELECT COUNT(*) FROM product_prices WHERE product_id = $1`
+	if err := conn(ctx, r.db).GetContext(ctx, &total, countQuery, productID); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT ` + productPriceColumns + `
+		FROM product_prices
+		WHERE product_id = $1
+		ORDER BY effective_from DESC
+		LIMIT $2 OFFSET $3`
+
+	prices := []domain.ProductPrice{}
+	if err := conn(ctx, r.db).SelectContext(ctx, &prices, query, productID, limit, offset); err != nil {
+		return nil, 0, err
+	}
+
+	return prices, total, nil
+}
+
+// HasHistory reports whether any price was recorded for the product. The
+// history is kept after the product is purged, so it may exist without the
+// product.
+func (r *productPriceRepository) HasHistory(ctx context.Context, productID uuid.UUID) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM product_prices WHERE product_id = $1)`
+	err := conn(ctx, r.db).GetContext(ctx, &exists, query, productID)
+	return exists, err
+}
+
+// DeleteScheduled cancels a price that has not been applied yet; the entry
+// before it is extended over its period.
+func (r *productPriceRepository) DeleteScheduled(ctx context.Context, productID, id uuid.UUID) error {
+	query := `
+		DELETE FROM product_prices
+		WHERE id = $1 AND product_id = $2 AND applied_at IS NULL
+		RETURNING effective_from, effective_to`
+
+	var deleted struct {
+		EffectiveFrom time.Time  `db:"effective_from"`
+		EffectiveTo   *time.Time `db:"effective_to"`
+	}
+	err := conn(ctx, r.db).QueryRowxContext(ctx, query, id, productID).StructScan(&deleted)
+	if errors.Is(err, sql.ErrNoRows) {
+		if _, getErr := r.GetByID(ctx, productID, id); getErr == nil {
+			return domain.ErrPriceAlreadyApplied
+		}
+		return domain.ErrProductPriceNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	extendQuery := `UPDATE product_prices SET effective_to = $1 WHERE product_id = $2 AND effective_to = $3`
+	_, err = conn(ctx, r.db).ExecContext(ctx, extendQuery, deleted.EffectiveTo, productID, deleted.EffectiveFrom)
+	return err
+}
+
+// CancelScheduledInOtherCurrencies deletes the product's prices that have not
+// been applied and are not in currency, then closes the gaps they leave: each
+// remaining entry lasts until the next one starts.
+func (r *productPriceRepository) CancelScheduledInOtherCurrencies(ctx context.Context, productID uuid.UUID, currency string) error {
+	query := `DELETE FROM product_prices WHERE product_id = $1 AND applied_at IS NULL AND currency <> $2`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, productID, currency)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected == 0 {
+		return err
+	}
+
+	return r.relink(ctx, productID)
+}
+
+// relink closes the gaps left in the product's timeline by removed or moved
+// entries: each entry lasts until the next one starts.
+func (r *productPriceRepository) relink(ctx context.Context, productID uuid.UUID) error {
+	query := `
+		UPDATE product_prices pp SET effective_to = timeline.next_from
+		FROM (
+			SELECT id, LEAD(effective_from) OVER (ORDER BY effective_from) AS next_from
+			FROM product_prices
+			WHERE product_id = $1
+		) timeline
+		WHERE pp.id = timeline.id AND pp.effective_to IS DISTINCT FROM timeline.next_from`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, productID)
+	return err
+}
+
+// DueProductIDs lists the undeleted products with scheduled prices that
+// should have taken effect by now. Prices of deleted products wait until the
+// product is restored.
+func (r *productPriceRepository) DueProductIDs(ctx context.Context, now time.Time) ([]uuid.UUID, error) {
+	query := `
+		SELECT DISTINCT pp.product_id
+		FROM product_prices pp
+		JOIN products p ON p.id = pp.product_id
+		WHERE pp.applied_at IS NULL AND pp.effective_from <= $1 AND p.deleted_at IS NULL`
+
+	var ids []uuid.UUID
+	err := conn(ctx, r.db).SelectContext(ctx, &ids, query, now)
+	return ids, err
+}
+
+// LockDue reads and locks the product's due scheduled prices, oldest first.
+func (r *productPriceRepository) LockDue(ctx context.Context, productID uuid.UUID, now time.Time) ([]domain.ProductPrice, error) {
+	query := `
+		SELECT ` + productPriceColumns + `
+		FROM product_prices
+		WHERE product_id = $1 AND applied_at IS NULL AND effective_from <= $2
+		ORDER BY effective_from ASC
+		FOR UPDATE`
+
+	var prices []domain.ProductPrice
+	err := conn(ctx, r.db).SelectContext(ctx, &prices, query, productID, now)
+	return prices, err
+}
+
+// ApplyDue records that the due scheduled price id was written to the
+// product at appliedAt. The history only shows prices that were charged, so
+// the entry now starts at appliedAt, and the other due prices, which it
+// superseded before they were ever applied, are dropped.
+func (r *productPriceRepository) ApplyDue(ctx context.Context, productID, id uuid.UUID, appliedAt time.Time) error {
+	db := conn(ctx, r.db)
+
+	deleteQuery := `
+		DELETE FROM product_prices
+		WHERE product_id = $1 AND id <> $2 AND applied_at IS NULL AND effective_from <= $3`
+	if _, err := db.ExecContext(ctx, deleteQuery, productID, id, appliedAt); err != nil {
+		return err
+	}
+
+	query := `UPDATE product_prices SET effective_from = $1, applied_at = $1 WHERE id = $2 AND product_id = $3`
+	result, err := db.ExecContext(ctx, query, appliedAt, id, productID)
+	if err != nil {
+		return err
+	}
+	if rowsAffected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if rowsAffected == 0 {
+		return domain.ErrProductPriceNotFound
+	}
+
+	return r.relink(ctx, productID)
+}
@@ -3,9 +3,11 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
@@ -15,9 +17,18 @@ import (
 type ProductRepository interface {
 	Create(ctx context.Context, product *domain.CreateProductRequest) (*domain.Product, error)
 	GetByID(ctx context.Context, id uuid.UUID) (*domain.Product, error)
-	Update(ctx context.Context, id uuid.UUID, product *domain.UpdateProductRequest) (*domain.Product, error)
-	Delete(ctx context.Context, id uuid.UUID) error
-	List(ctx context.Context, limit, offset int) ([]domain.Product, int, error)
+	GetByIDIncludingDeleted(ctx context.Context, id uuid.UUID) (*domain.Product, error)
+	LockByID(ctx context.Context, id uuid.UUID) (*domain.Product, error)
+	Update(ctx context.Context, id uuid.UUID, product *domain.UpdateProductRequest, version *int) (*domain.Product, error)
+	Replace(ctx context.Context, id uuid.UUID, product *domain.ReplaceProductRequest, version *int) (*domain.Product, error)
+	Delete(ctx context.Context, id uuid.UUID, version *int) error
+	SetPrice(ctx context.Context, id uuid.UUID, price domain.Decimal, currency string) (*domain.Product, error)
+	Restore(ctx context.Context, id uuid.UUID) (*domain.Product, error)
+	PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int, error)
+	List(ctx context.Context, filter *domain.ProductFilter, limit, offset int) ([]domain.Product, int, error)
+	ListByCursor(ctx context.Context, filter *domain.ProductFilter, cursor *domain.ProductCursor, limit int) ([]domain.Product, error)
+	Count(ctx context.Context, filter *domain.ProductFilter) (int, error)
+	Search(ctx context.Context, query string, limit, offset int) ([]domain.ProductSearchResult, int, error)
 }
 
 type productRepository struct {
@@ -28,18 +39,30 @@ func NewProductRepository(db *sqlx.DB) ProductRepository {
 	return &productRepository{db: db}
 }
 
+// availableQtyColumn is the product's stock minus its unexpired active
+// reservations; queries using it must alias products as p.
+const availableQtyColumn = `p.qty - COALESCE((
+			SELECT SUM(sr.quantity) FROM stock_reservations sr
+			WHERE sr.product_id = p.id AND sr.status = 'active' AND sr.expires_at > now()
+		), 0) AS available_qty`
+
+// productReturning is the RETURNING clause of writes to products aliased as p.
+const productReturning = `
+		RETURNING p.id, p.product_name, p.price, p.currency, p.qty, p.brand_id, p.created_at, p.updated_at, p.version, p.deleted_at, ` + availableQtyColumn
+
+// Create, Update and Replace never write qty: stock only changes through
+// InventoryRepository.RecordMovement, so a new product starts at zero.
 func (r *productRepository) Create(ctx context.Context, req *domain.CreateProductRequest) (*domain.Product, error) {
 	query := `
-		INSERT INTO products (product_name, price, qty, brand_id, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING id, product_name, price, qty, brand_id, created_at, updated_at`
+		INSERT INTO products AS p (product_name, price, currency, qty, brand_id, created_at, updated_at)
+		VALUES ($1, $2, $3, 0, $4, $5, $6)` + productReturning
 
 	now := time.Now()
 	var product domain.Product
 
-	err := r.db.QueryRowxContext(ctx, query, req.ProductName, req.Price, req.Qty, req.BrandID, now, now).StructScan(&product)
+	err := conn(ctx, r.db).QueryRowxContext(ctx, query, req.ProductName, req.Price, req.Currency, req.BrandID, now, now).StructScan(&product)
 	if err != nil {
-		return nil, err
+		return nil, translateError(err, domain.ErrProductNotFound)
 	}
 
 	return &product, nil
@@ -47,86 +70,134 @@ func (r *productRepository) Create(ctx context.Context, req *domain.CreateProduc
 
 func (r *productRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Product, error) {
 	query := `
-		SELECT p.id, p.product_name, p.price, p.qty, p.brand_id, p.created_at, p.updated_at, b.brand_name
+		SELECT p.id, p.product_name, p.price, p.currency, p.qty, p.brand_id, p.created_at, p.updated_at, p.version, p.deleted_at, b.brand_name,
+			` + availableQtyColumn + `
 		FROM products p
 		LEFT JOIN brands b ON p.brand_id = b.id
-		WHERE p.id = $1`
+		WHERE p.id = $1 AND p.deleted_at IS NULL`
 
 	var product domain.Product
-	err := r.db.GetContext(ctx, &product, query, id)
+	err := conn(ctx, r.db).GetContext(ctx, &product, query, id)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil
-		}
-		return nil, err
+		return nil, translateError(err, domain.ErrProductNotFound)
 	}
 
 	return &product, nil
 }
 
-func (r *productRepository) Update(ctx context.Context, id uuid.UUID, req *domain.UpdateProductRequest) (*domain.Product, error) {
-	current, err := r.GetByID(ctx, id)
+// GetByIDIncludingDeleted is GetByID for admin views and restores, which
+// also need soft-deleted products.
+func (r *productRepository) GetByIDIncludingDeleted(ctx context.Context, id uuid.UUID) (*domain.Product, error) {
+	query := `
+		SELECT p.id, p.product_name, p.price, p.currency, p.qty, p.brand_id, p.created_at, p.updated_at, p.version, p.deleted_at, b.brand_name,
+			` + availableQtyColumn + `
+		FROM products p
+		LEFT JOIN brands b ON p.brand_id = b.id
+		WHERE p.id = $1`
+
+	var product domain.Product
+	err := conn(ctx, r.db).GetContext(ctx, &product, query, id)
 	if err != nil {
-		return nil, err
+		return nil, translateError(err, domain.ErrProductNotFound)
 	}
-	if current == nil {
-		return nil, sql.ErrNoRows
+
+	return &product, nil
+}
+
+// LockByID reads a product and locks its row until the surrounding
+// transaction ends, so the value seen is the one being changed.
+func (r *productRepository) LockByID(ctx context.Context, id uuid.UUID) (*domain.Product, error) {
+	query := `
+		SELECT p.id, p.product_name, p.price, p.currency, p.qty, p.brand_id, p.created_at, p.updated_at, p.version, p.deleted_at, b.brand_name,
+			` + availableQtyColumn + `
+		FROM products p
+		LEFT JOIN brands b ON p.brand_id = b.id
+		WHERE p.id = $1 AND p.deleted_at IS NULL
+		FOR UPDATE OF p`
+
+	var product domain.Product
+	err := conn(ctx, r.db).GetContext(ctx, &product, query, id)
+	if err != nil {
+		return nil, translateError(err, domain.ErrProductNotFound)
 	}
-	setParts := []string{}
+
+	return &product, nil
+}
+
+// Update, Replace and Delete bump the product's version. When version is
+// set they only apply while the stored version still equals it, and fail
+// with domain.ErrVersionMismatch otherwise.
+func (r *productRepository) Update(ctx context.Context, id uuid.UUID, req *domain.UpdateProductRequest, version *int) (*domain.Product, error) {
+	setParts := []string{"version = version + 1"}
 	args := []interface{}{}
 	argIndex := 1
 
-	if req.ProductName != "" {
+	if req.ProductName != nil {
 		setParts = append(setParts, fmt.Sprintf("product_name = $%d", argIndex))
-		args = append(args, req.ProductName)
+		args = append(args, *req.ProductName)
 		argIndex++
 	}
-	if req.Price > 0 {
+	if req.Price != nil {
 		setParts = append(setParts, fmt.Sprintf("price = $%d", argIndex))
-		args = append(args, req.Price)
+		args = append(args, *req.Price)
 		argIndex++
 	}
-	if req.Qty >= 0 {
-		setParts = append(setParts, fmt.Sprintf("qty = $%d", argIndex))
-		args = append(args, req.Qty)
+	if req.Currency != nil {
+		setParts = append(setParts, fmt.Sprintf("currency = $%d", argIndex))
+		args = append(args, *req.Currency)
 		argIndex++
 	}
-	if req.BrandID != uuid.Nil {
+	if req.BrandID != nil {
 		setParts = append(setParts, fmt.Sprintf("brand_id = $%d", argIndex))
-		args = append(args, req.BrandID)
+		args = append(args, *req.BrandID)
 		argIndex++
 	}
 
-	if len(setParts) == 0 {
-		return current, nil
-	}
-
 	setParts = append(setParts, fmt.Sprintf("updated_at = $%d", argIndex))
 	args = append(args, time.Now())
 	argIndex++
 
-	args = append(args, id)
+	args = append(args, id, version)
 	setClause := strings.Join(setParts, ", ")
 	query := fmt.Sprintf(`
-    UPDATE products
+    UPDATE products p
     SET %s
-    WHERE id = $%d
-    RETURNING id, product_name, price, qty, brand_id, created_at, updated_at`, setClause, argIndex)
+    WHERE id = $%d AND deleted_at IS NULL AND ($%d::int IS NULL OR version = $%d)`+productReturning, setClause, argIndex, argIndex+1, argIndex+1)
 
 	var product domain.Product
-	err = r.db.QueryRowxContext(ctx, query, args...).StructScan(&product)
+	err := conn(ctx, r.db).QueryRowxContext(ctx, query, args...).StructScan(&product)
 	if err != nil {
-		return nil, err
+		return nil, r.writeError(ctx, err, id, version)
 	}
 
 	return &product, nil
 }
 
-func (r *productRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `DELETE FROM products WHERE id = $1`
-	result, err := r.db.ExecContext(ctx, query, id)
+func (r *productRepository) Replace(ctx context.Context, id uuid.UUID, req *domain.ReplaceProductRequest, version *int) (*domain.Product, error) {
+	query := `
+		UPDATE products p
+		SET product_name = $1, price = $2, currency = COALESCE(NULLIF($3, ''), currency), brand_id = $4, updated_at = $5, version = version + 1
+		WHERE id = $6 AND deleted_at IS NULL AND ($7::int IS NULL OR version = $7)` + productReturning
+
+	var product domain.Product
+	err := conn(ctx, r.db).QueryRowxContext(ctx, query, req.ProductName, *req.Price, req.Currency, req.BrandID, time.Now(), id, version).StructScan(&product)
 	if err != nil {
-		return err
+		return nil, r.writeError(ctx, err, id, version)
+	}
+
+	return &product, nil
+}
+
+// Delete soft deletes the product; its stock ledger, variants and order
+// references stay intact until PurgeDeleted removes it.
+func (r *productRepository) Delete(ctx context.Context, id uuid.UUID, version *int) error {
+	query := `
+		UPDATE products
+		SET deleted_at = now(), updated_at = $1, version = version + 1
+		WHERE id = $2 AND deleted_at IS NULL AND ($3::int IS NULL OR version = $3)`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, time.Now(), id, version)
+	if err != nil {
+		return translateError(err, domain.ErrProductNotFound)
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -135,31 +206,284 @@ func (r *productRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	}
 
 	if rowsAffected == 0 {
-		return sql.ErrNoRows
+		return r.writeError(ctx, sql.ErrNoRows, id, version)
 	}
 
 	return nil
 }
 
-func (r *productRepository) List(ctx context.Context, limit, offset int) ([]domain.Product, int, error) {
-	var total int
-	countQuery := `SELECT COUNT(*) FROM products`
-	err := r.db.GetContext(ctx, &total, countQuery)
+// writeError explains a versioned write that failed with err: when no row
+// matched, the product is either gone or at another version.
+func (r *productRepository) writeError(ctx context.Context, err error, id uuid.UUID, version *int) error {
+	if errors.Is(err, sql.ErrNoRows) && version != nil {
+		return staleOrNotFound(ctx, r.db, "products", id, domain.ErrProductNotFound)
+	}
+	return translateError(err, domain.ErrProductNotFound)
+}
+
+// SetPrice writes a scheduled price that has taken effect; like other
+// writes it bumps the version, so clients holding the old one must reload.
+func (r *productRepository) SetPrice(ctx context.Context, id uuid.UUID, price domain.Decimal, currency string) (*domain.Product, error) {
+	query := `
+		UPDATE products p
+		SET price = $1, currency = $2, updated_at = $3, version = version + 1
+		WHERE id = $4 AND deleted_at IS NULL` + productReturning
+
+	var product domain.Product
+	err := conn(ctx, r.db).QueryRowxContext(ctx, query, price, currency, time.Now(), id).StructScan(&product)
 	if err != nil {
-		return nil, 0, err
+		return nil, translateError(err, domain.ErrProductNotFound)
 	}
+
+	return &product, nil
+}
+
+func (r *productRepository) Restore(ctx context.Context, id uuid.UUID) (*domain.Product, error) {
 	query := `
-		SELECT p.id, p.product_name, p.price, p.qty, p.brand_id, p.created_at, p.updated_at, b.brand_name
+		UPDATE products p
+		SET deleted_at = NULL, updated_at = $1, version = version + 1
+		WHERE id = $2 AND deleted_at IS NOT NULL` + productReturning
+
+	var product domain.Product
+	err := conn(ctx, r.db).QueryRowxContext(ctx, query, time.Now(), id).StructScan(&product)
+	if err != nil {
+		return nil, translateError(err, domain.ErrProductNotFound)
+	}
+
+	return &product, nil
+}
+
+// PurgeDeleted permanently removes products soft deleted before
+// deletedBefore, with their variants, stock ledger and reservations.
+func (r *productRepository) PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int, error) {
+	query := `DELETE FROM products WHERE deleted_at < $1`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+
+	purged, err := result.RowsAffected()
+	return int(purged), err
+}
+
+func (r *productRepository) List(ctx context.Context, filter *domain.ProductFilter, limit, offset int) ([]domain.Product, int, error) {
+	total, err := r.Count(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	where, args := buildProductFilter(filter)
+	args = append(args, limit, offset)
+	query := fmt.Sprintf(`
+		SELECT p.id, p.product_name, p.price, p.currency, p.qty, p.brand_id, p.created_at, p.updated_at, p.version, p.deleted_at, b.brand_name,
+			`+availableQtyColumn+`
 		FROM products p
-		LEFT JOIN brands b ON p.brand_id = b.id
-		ORDER BY p.created_at DESC
-		LIMIT $1 OFFSET $2`
+		LEFT JOIN brands b ON p.brand_id = b.id%s
+		ORDER BY %s
+		LIMIT $%d OFFSET $%d`, where, buildProductOrder(filter), len(args)-1, len(args))
 
 	var products []domain.Product
-	err = r.db.SelectContext(ctx, &products, query, limit, offset)
+	err = conn(ctx, r.db).SelectContext(ctx, &products, query, args...)
 	if err != nil {
 		return nil, 0, err
 	}
 
 	return products, total, nil
 }
+
+// ListByCursor seeks past cursor in (created_at, id) order instead of using
+// OFFSET. Products come back newest first; a backward cursor selects the rows
+// just before it, which are queried ascending and reversed here.
+func (r *productRepository) ListByCursor(ctx context.Context, filter *domain.ProductFilter, cursor *domain.ProductCursor, limit int) ([]domain.Product, error) {
+	where, args := buildProductFilter(filter)
+
+	order := "p.created_at DESC, p.id DESC"
+	if cursor != nil {
+		comparison := "<"
+		if cursor.Backward {
+			comparison = ">"
+			order = "p.created_at ASC, p.id ASC"
+		}
+		args = append(args, cursor.CreatedAt, cursor.ID)
+		seek := fmt.Sprintf("(p.created_at, p.id) %s ($%d, $%d)", comparison, len(args)-1, len(args))
+		if where == "" {
+			where = "\n\t\tWHERE " + seek
+		} else {
+			where += " AND " + seek
+		}
+	}
+
+	args = append(args, limit)
+	query := fmt.Sprintf(`
+		SELECT p.id, p.product_name, p.price, p.currency, p.qty, p.brand_id, p.created_at, p.updated_at, p.version, p.deleted_at, b.brand_name,
+			`+availableQtyColumn+`
+		FROM products p
+		LEFT JOIN brands b ON p.brand_id = b.id%s
+		ORDER BY %s
+		LIMIT $%d`, where, order, len(args))
+
+	var products []domain.Product
+	if err := conn(ctx, r.db).SelectContext(ctx, &products, query, args...); err != nil {
+		return nil, err
+	}
+
+	if cursor != nil && cursor.Backward {
+		for i, j := 0, len(products)-1; i < j; i, j = i+1, j-1 {
+			products[i], products[j] = products[j], products[i]
+		}
+	}
+
+	return products, nil
+}
+
+func (r *productRepository) Count(ctx context.Context, filter *domain.ProductFilter) (int, error) {
+	where, args := buildProductFilter(filter)
+
+	var total int
+	query := `SELECT COUNT(*) FROM products p` + where
+	err := conn(ctx, r.db).GetContext(ctx, &total, query, args...)
+	return total, err
+}
+
+// Search runs a ranked full-text query against products.search_vector. Every
+// word of query is matched as a prefix so partial input works for typeahead.
+func (r *productRepository) Search(ctx context.Context, query string, limit, offset int) ([]domain.ProductSearchResult, int, error) {
+	tsquery := prefixTSQuery(query)
+	if tsquery == "" {
+		return []domain.ProductSearchResult{}, 0, nil
+	}
+
+	var total int
+	countQuery := `SELECT COUNT(*) FROM products p WHERE p.search_vector @@ to_tsquery('simple', $1) AND p.deleted_at IS NULL`
+	if err := conn(ctx, r.db).GetContext(ctx, &total, countQuery, tsquery); err != nil {
+		return nil, 0, err
+	}
+
+	searchQuery := `
+		SELECT p.id, p.product_name, p.price, p.currency, p.qty, p.brand_id, p.created_at, p.updated_at, p.version, p.deleted_at, b.brand_name,
+			` + availableQtyColumn + `,
+			ts_rank(p.search_vector, q) AS rank,
+			ts_headline('simple', p.product_name || ' ' || coalesce(b.brand_name, ''), q,
+				'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') AS snippet
+		FROM products p
+		LEFT JOIN brands b ON p.brand_id = b.id
+		CROSS JOIN to_tsquery('simple', $1) q
+		WHERE p.search_vector @@ q AND p.deleted_at IS NULL
+		ORDER BY rank DESC, p.created_at DESC, p.id DESC
+		LIMIT $2 OFFSET $3`
+
+	var results []domain.ProductSearchResult
+	if err := conn(ctx, r.db).SelectContext(ctx, &results, searchQuery, tsquery, limit, offset); err != nil {
+		return nil, 0, err
+	}
+
+	return results, total, nil
+}
+
+// prefixTSQuery turns free text into a tsquery string such as "gala:* & s24:*".
+// Only letters and digits survive, so the result is always a valid query.
+func prefixTSQuery(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		terms = append(terms, word+":*")
+	}
+	return strings.Join(terms, " & ")
+}
+
+// productSortColumns maps the whitelisted domain.ProductSortFields to SQL columns.
+var productSortColumns = map[string]string{
+	"product_name": "p.product_name",
+	"price":        "p.price",
+	"qty":          "p.qty",
+	"created_at":   "p.created_at",
+	"updated_at":   "p.updated_at",
+}
+
+// buildProductFilter renders the filter as a WHERE clause over the products
+// table aliased as p. Values are always bound as arguments. Soft-deleted
+// products are left out unless the filter includes them.
+func buildProductFilter(filter *domain.ProductFilter) (string, []interface{}) {
+	if filter == nil {
+		return "\n\t\tWHERE p.deleted_at IS NULL", nil
+	}
+
+	conditions := []string{}
+	args := []interface{}{}
+	if !filter.IncludeDeleted {
+		conditions = append(conditions, "p.deleted_at IS NULL")
+	}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if len(filter.BrandIDs) > 0 {
+		conditions = append(conditions, fmt.Sprintf("p.brand_id = ANY(%s::uuid[])", arg(uuidArray(filter.BrandIDs))))
+	}
+	if filter.CategoryID != nil {
+		conditions = append(conditions, fmt.Sprintf(`p.id IN (
+			WITH RECURSIVE tree AS (
+				SELECT id FROM categories WHERE id = %s
+				UNION ALL
+				SELECT c.id FROM categories c JOIN tree t ON c.parent_id = t.id
+			)
+			SELECT pc.product_id FROM product_categories pc JOIN tree t ON pc.category_id = t.id
+		)`, arg(*filter.CategoryID)))
+	}
+	if filter.PriceMin != nil {
+		conditions = append(conditions, fmt.Sprintf("p.price >= %s", arg(*filter.PriceMin)))
+	}
+	if filter.PriceMax != nil {
+		conditions = append(conditions, fmt.Sprintf("p.price <= %s", arg(*filter.PriceMax)))
+	}
+	if filter.InStock != nil {
+		if *filter.InStock {
+			conditions = append(conditions, "p.qty > 0")
+		} else {
+			conditions = append(conditions, "p.qty <= 0")
+		}
+	}
+	if filter.Name != "" {
+		conditions = append(conditions, fmt.Sprintf(`p.product_name ILIKE %s ESCAPE '\'`, arg("%"+escapeLike(filter.Name)+"%")))
+	}
+	if filter.CreatedAfter != nil {
+		conditions = append(conditions, fmt.Sprintf("p.created_at >= %s", arg(*filter.CreatedAfter)))
+	}
+	if filter.CreatedBefore != nil {
+		conditions = append(conditions, fmt.Sprintf("p.created_at < %s", arg(*filter.CreatedBefore)))
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return "\n\t\tWHERE " + strings.Join(conditions, " AND "), args
+}
+
+func buildProductOrder(filter *domain.ProductFilter) string {
+	if filter == nil || len(filter.Sort) == 0 {
+		return "p.created_at DESC, p.id DESC"
+	}
+
+	parts := make([]string, 0, len(filter.Sort)+1)
+	for _, sort := range filter.Sort {
+		column, ok := productSortColumns[sort.Field]
+		if !ok {
+			continue
+		}
+		direction := "ASC"
+		if sort.Desc {
+			direction = "DESC"
+		}
+		parts = append(parts, column+" "+direction)
+	}
+	parts = append(parts, "p.id ASC")
+	return strings.Join(parts, ", ")
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
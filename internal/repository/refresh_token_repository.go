@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/rezajo220/ecommerce/internal/domain"
+)
+
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token *domain.RefreshToken) error
+	LockByHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error)
+	Revoke(ctx context.Context, id uuid.UUID) error
+	RevokeFamily(ctx context.Context, familyID uuid.UUID) error
+}
+
+type refreshTokenRepository struct {
+	db *sqlx.DB
+}
+
+func NewRefreshTokenRepository(db *sqlx.DB) RefreshTokenRepository {
+	return &refreshTokenRepository{db: db}
+}
+
+func (r *refreshTokenRepository) Create(ctx context.Context, token *domain.RefreshToken) error {
+	query := `
+		INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5)`
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, token.UserID, token.FamilyID, token.TokenHash, token.ExpiresAt, time.Now())
+	return translateError(err, domain.ErrUserNotFound)
+}
+
+// LockByHash finds a refresh token by its hash and locks it until the
+// surrounding transaction ends, so a token cannot be rotated twice.
+func (r *refreshTokenRepository) LockByHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	query := `
+		SELECT id, user_id, family_id, token_hash, expires_at, revoked_at, created_at
+		FROM refresh_tokens
+		WHERE token_hash = $1
+		FOR UPDATE`
+
+	var token domain.RefreshToken
+	err := conn(ctx, r.db).GetContext(ctx, &token, query, tokenHash)
+	if err != nil {
+		return nil, translateError(err, domain.ErrInvalidToken)
+	}
+
+	return &token, nil
+}
+
+func (r *refreshTokenRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE refresh_tokens SET revoked_at = now() WHERE id = $1 AND revoked_at IS NULL`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, id)
+	return err
+}
+
+func (r *refreshTokenRepository) RevokeFamily(ctx context.Context, familyID uuid.UUID) error {
+	query := `UPDATE refresh_tokens SET revoked_at = now() WHERE family_id = $1 AND revoked_at IS NULL`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, familyID)
+	return err
+}
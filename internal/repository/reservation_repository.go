@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/rezajo220/ecommerce/internal/domain"
+)
+
+type ReservationRepository interface {
+	Create(ctx context.Context, reservation *domain.CreateReservationRequest) (*domain.StockReservation, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.StockReservation, error)
+	LockByID(ctx context.Context, id uuid.UUID) (*domain.StockReservation, error)
+	UpdateStatus(ctx context.Context, id uuid.UUID, status domain.ReservationStatus) (*domain.StockReservation, error)
+	ExpireStale(ctx context.Context) (int64, error)
+}
+
+type reservationRepository struct {
+	db *sqlx.DB
+}
+
+func NewReservationRepository(db *sqlx.DB) ReservationRepository {
+	return &reservationRepository{db: db}
+}
+
+// Create stores an active reservation. The expiry is computed by the database
+// so it compares consistently against now() in availability queries.
+func (r *reservationRepository) Create(ctx context.Context, req *domain.CreateReservationRequest) (*domain.StockReservation, error) {
+	query := `
+		INSERT INTO stock_reservations (product_id, holder, quantity, status, expires_at, created_at, updated_at)
+		VALUES ($1, $2, $3, 'active', now() + make_interval(secs => $4), $5, $6)
+		RETURNING id, product_id, holder, quantity, status, expires_at, created_at, updated_at`
+
+	now := time.Now()
+	var reservation domain.StockReservation
+
+	err := conn(ctx, r.db).QueryRowxContext(ctx, query, req.ProductID, req.Holder, req.Quantity, req.TTL().Seconds(), now, now).StructScan(&reservation)
+	if err != nil {
+		return nil, translateError(err, domain.ErrProductNotFound)
+	}
+
+	return &reservation, nil
+}
+
+func (r *reservationRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.StockReservation, error) {
+	query := `
+		SELECT id, product_id, holder, quantity, status, expires_at, created_at, updated_at
+		FROM stock_reservations
+		WHERE id = $1`
+
+	var reservation domain.StockReservation
+	err := conn(ctx, r.db).GetContext(ctx, &reservation, query, id)
+	if err != nil {
+		return nil, translateError(err, domain.ErrReservationNotFound)
+	}
+
+	return &reservation, nil
+}
+
+// LockByID is GetByID that also locks the reservation until the surrounding
+// transaction ends.
+func (r *reservationRepository) LockByID(ctx context.Context, id uuid.UUID) (*domain.StockReservation, error) {
+	query := `
+		SELECT id, product_id, holder, quantity, status, expires_at, created_at, updated_at
+		FROM stock_reservations
+		WHERE id = $1
+		FOR UPDATE`
+
+	var reservation domain.StockReservation
+	err := conn(ctx, r.db).GetContext(ctx, &reservation, query, id)
+	if err != nil {
+		return nil, translateError(err, domain.ErrReservationNotFound)
+	}
+
+	return &reservation, nil
+}
+
+func (r *reservationRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status domain.ReservationStatus) (*domain.StockReservation, error) {
+	query := `
+		UPDATE stock_reservations
+		SET status = $1, updated_at = $2
+		WHERE id = $3
+		RETURNING id, product_id, holder, quantity, status, expires_at, created_at, updated_at`
+
+	var reservation domain.StockReservation
+	err := conn(ctx, r.db).QueryRowxContext(ctx, query, status, time.Now(), id).StructScan(&reservation)
+	if err != nil {
+		return nil, translateError(err, domain.ErrReservationNotFound)
+	}
+
+	return &reservation, nil
+}
+
+// ExpireStale marks active reservations past their expiry as expired and
+// returns how many there were. Availability already ignores them, so this
+// only keeps the status column truthful.
+func (r *reservationRepository) ExpireStale(ctx context.Context) (int64, error) {
+	query := `
+		UPDATE stock_reservations
+		SET status = 'expired', updated_at = $1
+		WHERE status = 'active' AND expires_at <= now()`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,269 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/rezajo220/ecommerce/internal/domain"
+)
+
+type RoleRepository interface {
+	Create(ctx context.Context, req *domain.CreateRoleRequest) (*domain.Role, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Role, error)
+	GetByName(ctx context.Context, name string) (*domain.Role, error)
+	List(ctx context.Context) ([]domain.Role, error)
+	Replace(ctx context.Context, id uuid.UUID, req *domain.ReplaceRoleRequest) (*domain.Role, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	CountExisting(ctx context.Context, ids []uuid.UUID) (int, error)
+	ListUserRoles(ctx context.Context, userID uuid.UUID) ([]domain.Role, error)
+	SetUserRoles(ctx context.Context, userID uuid.UUID, roleIDs []uuid.UUID) error
+	AddUserRole(ctx context.Context, userID, roleID uuid.UUID) error
+	ListUserPermissions(ctx context.Context, userID uuid.UUID) ([]string, error)
+}
+
+type roleRepository struct {
+	db *sqlx.DB
+}
+
+func NewRoleRepository(db *sqlx.DB) RoleRepository {
+	return &roleRepository{db: db}
+}
+
+func (r *roleRepository) Create(ctx context.Context, req *domain.CreateRoleRequest) (*domain.Role, error) {
+	query := `
+		INSERT INTO roles (name, description, created_at, updated_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, name, description, created_at, updated_at`
+
+	now := time.Now()
+	var role domain.Role
+
+	err := conn(ctx, r.db).QueryRowxContext(ctx, query, req.Name, req.Description, now, now).StructScan(&role)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, domain.ErrRoleExists
+		}
+		return nil, translateError(err, domain.ErrRoleNotFound)
+	}
+
+	if err := r.setPermissions(ctx, role.ID, req.Permissions); err != nil {
+		return nil, err
+	}
+	role.Permissions = req.Permissions
+
+	return &role, nil
+}
+
+func (r *roleRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Role, error) {
+	query := `
+		SELECT id, name, description, created_at, updated_at
+		FROM roles
+		WHERE id = $1`
+
+	var role domain.Role
+	err := conn(ctx, r.db).GetContext(ctx, &role, query, id)
+	if err != nil {
+		return nil, translateError(err, domain.ErrRoleNotFound)
+	}
+
+	return r.withPermissions(ctx, &role)
+}
+
+func (r *roleRepository) GetByName(ctx context.Context, name string) (*domain.Role, error) {
+	query := `
+		SELECT id, name, description, created_at, updated_at
+		FROM roles
+		WHERE name = $1`
+
+	var role domain.Role
+	err := conn(ctx, r.db).GetContext(ctx, &role, query, name)
+	if err != nil {
+		return nil, translateError(err, domain.ErrRoleNotFound)
+	}
+
+	return r.withPermissions(ctx, &role)
+}
+
+func (r *roleRepository) List(ctx context.Context) ([]domain.Role, error) {
+	query := `
+		SELECT id, name, description, created_at, updated_at
+		FROM roles
+		ORDER BY name ASC`
+
+	var roles []domain.Role
+	if err := conn(ctx, r.db).SelectContext(ctx, &roles, query); err != nil {
+		return nil, err
+	}
+
+	return r.attachPermissions(ctx, roles)
+}
+
+func (r *roleRepository) Replace(ctx context.Context, id uuid.UUID, req *domain.ReplaceRoleRequest) (*domain.Role, error) {
+	query := `
+		UPDATE roles
+		SET description = $1, updated_at = $2
+		WHERE id = $3
+		RETURNING id, name, description, created_at, updated_at`
+
+	var role domain.Role
+	err := conn(ctx, r.db).QueryRowxContext(ctx, query, req.Description, time.Now(), id).StructScan(&role)
+	if err != nil {
+		return nil, translateError(err, domain.ErrRoleNotFound)
+	}
+
+	if err := r.setPermissions(ctx, role.ID, req.Permissions); err != nil {
+		return nil, err
+	}
+	role.Permissions = req.Permissions
+
+	return &role, nil
+}
+
+func (r *roleRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM roles WHERE id = $1`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, id)
+	if err != nil {
+		return translateError(err, domain.ErrRoleNotFound)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrRoleNotFound
+	}
+
+	return nil
+}
+
+func (r *roleRepository) CountExisting(ctx context.Context, ids []uuid.UUID) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM roles WHERE id = ANY($1::uuid[])`
+	err := conn(ctx, r.db).GetContext(ctx, &count, query, uuidArray(ids))
+	return count, err
+}
+
+func (r *roleRepository) ListUserRoles(ctx context.Context, userID uuid.UUID) ([]domain.Role, error) {
+	query := `
+		SELECT r.id, r.name, r.description, r.created_at, r.updated_at
+		FROM roles r
+		JOIN user_roles ur ON ur.role_id = r.id
+		WHERE ur.user_id = $1
+		ORDER BY r.name ASC`
+
+	var roles []domain.Role
+	if err := conn(ctx, r.db).SelectContext(ctx, &roles, query, userID); err != nil {
+		return nil, err
+	}
+
+	return r.attachPermissions(ctx, roles)
+}
+
+// SetUserRoles replaces the user's role assignments in a single statement,
+// so the set is never observed half-updated.
+func (r *roleRepository) SetUserRoles(ctx context.Context, userID uuid.UUID, roleIDs []uuid.UUID) error {
+	query := `
+		WITH removed AS (
+			DELETE FROM user_roles
+			WHERE user_id = $1 AND NOT (role_id = ANY($2::uuid[]))
+		)
+		INSERT INTO user_roles (user_id, role_id)
+		SELECT $1, unnest($2::uuid[])
+		ON CONFLICT DO NOTHING`
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, userID, uuidArray(roleIDs))
+	return translateError(err, domain.ErrUserNotFound)
+}
+
+func (r *roleRepository) AddUserRole(ctx context.Context, userID, roleID uuid.UUID) error {
+	query := `
+		INSERT INTO user_roles (user_id, role_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING`
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, userID, roleID)
+	return translateError(err, domain.ErrUserNotFound)
+}
+
+// ListUserPermissions returns the union of the permissions of every role
+// assigned to the user.
+func (r *roleRepository) ListUserPermissions(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	query := `
+		SELECT DISTINCT rp.permission
+		FROM role_permissions rp
+		JOIN user_roles ur ON ur.role_id = rp.role_id
+		WHERE ur.user_id = $1
+		ORDER BY rp.permission`
+
+	var permissions []string
+	err := conn(ctx, r.db).SelectContext(ctx, &permissions, query, userID)
+	return permissions, err
+}
+
+func (r *roleRepository) setPermissions(ctx context.Context, roleID uuid.UUID, permissions []string) error {
+	query := `
+		WITH removed AS (
+			DELETE FROM role_permissions
+			WHERE role_id = $1 AND NOT (permission = ANY($2::text[]))
+		)
+		INSERT INTO role_permissions (role_id, permission)
+		SELECT $1, unnest($2::text[])
+		ON CONFLICT DO NOTHING`
+
+	// A nil array would be sent as NULL and the DELETE would match nothing.
+	array := append(pq.StringArray{}, permissions...)
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, roleID, array)
+	return translateError(err, domain.ErrRoleNotFound)
+}
+
+func (r *roleRepository) withPermissions(ctx context.Context, role *domain.Role) (*domain.Role, error) {
+	roles, err := r.attachPermissions(ctx, []domain.Role{*role})
+	if err != nil {
+		return nil, err
+	}
+	return &roles[0], nil
+}
+
+// attachPermissions loads the permissions of all roles in one query.
+func (r *roleRepository) attachPermissions(ctx context.Context, roles []domain.Role) ([]domain.Role, error) {
+	if len(roles) == 0 {
+		return roles, nil
+	}
+
+	ids := make([]uuid.UUID, len(roles))
+	for i, role := range roles {
+		ids[i] = role.ID
+	}
+
+	query := `
+		SELECT role_id, permission
+		FROM role_permissions
+		WHERE role_id = ANY($1::uuid[])
+		ORDER BY permission`
+
+	var rows []struct {
+		RoleID     uuid.UUID `db:"role_id"`
+		Permission string    `db:"permission"`
+	}
+	if err := conn(ctx, r.db).SelectContext(ctx, &rows, query, uuidArray(ids)); err != nil {
+		return nil, err
+	}
+
+	byRole := make(map[uuid.UUID][]string, len(roles))
+	for _, row := range rows {
+		byRole[row.RoleID] = append(byRole[row.RoleID], row.Permission)
+	}
+	for i := range roles {
+		roles[i].Permissions = byRole[roles[i].ID]
+		if roles[i].Permissions == nil {
+			roles[i].Permissions = []string{}
+		}
+	}
+
+	return roles, nil
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Transactor runs a function inside a database transaction. Repositories pick
+// the transaction up from the context, so services can group calls across
+// several repositories without passing *sqlx.Tx around.
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type txKey struct{}
+
+type transactor struct {
+	db *sqlx.DB
+}
+
+func NewTransactor(db *sqlx.DB) Transactor {
+	return &transactor{db: db}
+}
+
+// WithinTransaction commits when fn returns nil and rolls back otherwise.
+// A nested call joins the transaction already in ctx.
+func (t *transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := t.db.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// queryer is the part of sqlx shared by *sqlx.DB and *sqlx.Tx.
+type queryer interface {
+	sqlx.ExtContext
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+}
+
+// conn returns the transaction stored in ctx, or db when there is none.
+func conn(ctx context.Context, db *sqlx.DB) queryer {
+	if tx, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return tx
+	}
+	return db
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/rezajo220/ecommerce/internal/domain"
+)
+
+type UserRepository interface {
+	Create(ctx context.Context, email, passwordHash string) (*domain.User, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.User, error)
+	GetByEmail(ctx context.Context, email string) (*domain.User, error)
+}
+
+type userRepository struct {
+	db *sqlx.DB
+}
+
+func NewUserRepository(db *sqlx.DB) UserRepository {
+	return &userRepository{db: db}
+}
+
+func (r *userRepository) Create(ctx context.Context, email, passwordHash string) (*domain.User, error) {
+	query := `
+		INSERT INTO users (email, password_hash, created_at, updated_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, email, password_hash, created_at, updated_at`
+
+	now := time.Now()
+	var user domain.User
+
+	err := conn(ctx, r.db).QueryRowxContext(ctx, query, email, passwordHash, now, now).StructScan(&user)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, domain.ErrUserExists
+		}
+		return nil, translateError(err, domain.ErrUserNotFound)
+	}
+
+	return &user, nil
+}
+
+func (r *userRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	query := `
+		SELECT id, email, password_hash, created_at, updated_at
+		FROM users
+		WHERE id = $1`
+
+	var user domain.User
+	err := conn(ctx, r.db).GetContext(ctx, &user, query, id)
+	if err != nil {
+		return nil, translateError(err, domain.ErrUserNotFound)
+	}
+
+	return &user, nil
+}
+
+func (r *userRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	query := `
+		SELECT id, email, password_hash, created_at, updated_at
+		FROM users
+		WHERE lower(email) = lower($1)`
+
+	var user domain.User
+	err := conn(ctx, r.db).GetContext(ctx, &user, query, email)
+	if err != nil {
+		return nil, translateError(err, domain.ErrUserNotFound)
+	}
+
+	return &user, nil
+}
@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/rezajo220/ecommerce/internal/domain"
+)
+
+type VariantRepository interface {
+	Create(ctx context.Context, productID uuid.UUID, variant *domain.CreateVariantRequest) (*domain.ProductVariant, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.ProductVariant, error)
+	GetBySKU(ctx context.Context, sku string) (*domain.ProductVariant, error)
+	Update(ctx context.Context, id uuid.UUID, variant *domain.UpdateVariantRequest) (*domain.ProductVariant, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	ListByProduct(ctx context.Context, productID uuid.UUID) ([]domain.ProductVariant, error)
+	ListByProducts(ctx context.Context, productIDs []uuid.UUID) (map[uuid.UUID][]domain.ProductVariant, error)
+}
+
+type variantRepository struct {
+	db *sqlx.DB
+}
+
+func NewVariantRepository(db *sqlx.DB) VariantRepository {
+	return &variantRepository{db: db}
+}
+
+func (r *variantRepository) Create(ctx context.Context, productID uuid.UUID, req *domain.CreateVariantRequest) (*domain.ProductVariant, error) {
+	query := `
+		INSERT INTO product_variants (product_id, sku, options, price, qty, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, product_id, sku, options, price, qty, created_at, updated_at`
+
+	now := time.Now()
+	var variant domain.ProductVariant
+
+	err := conn(ctx, r.db).QueryRowxContext(ctx, query, productID, req.SKU, req.Options, req.Price, req.Qty, now, now).StructScan(&variant)
+	if err != nil {
+		return nil, variantError(err)
+	}
+
+	return &variant, nil
+}
+
+func (r *variantRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.ProductVariant, error) {
+	query := `
+		SELECT id, product_id, sku, options, price, qty, created_at, updated_at
+		FROM product_variants
+		WHERE id = $1`
+
+	var variant domain.ProductVariant
+	err := conn(ctx, r.db).GetContext(ctx, &variant, query, id)
+	if err != nil {
+		return nil, variantError(err)
+	}
+
+	return &variant, nil
+}
+
+func (r *variantRepository) GetBySKU(ctx context.Context, sku string) (*domain.ProductVariant, error) {
+	query := `
+		SELECT id, product_id, sku, options, price, qty, created_at, updated_at
+		FROM product_variants
+		WHERE lower(sku) = lower($1)`
+
+	var variant domain.ProductVariant
+	err := conn(ctx, r.db).GetContext(ctx, &variant, query, sku)
+	if err != nil {
+		return nil, variantError(err)
+	}
+
+	return &variant, nil
+}
+
+func (r *variantRepository) Update(ctx context.Context, id uuid.UUID, req *domain.UpdateVariantRequest) (*domain.ProductVariant, error) {
+	setParts := []string{}
+	args := []interface{}{}
+	argIndex := 1
+
+	if req.SKU != nil {
+		setParts = append(setParts, fmt.Sprintf("sku = $%d", argIndex))
+		args = append(args, *req.SKU)
+		argIndex++
+	}
+	if req.Options != nil {
+		setParts = append(setParts, fmt.Sprintf("options = $%d", argIndex))
+		args = append(args, *req.Options)
+		argIndex++
+	}
+	if req.Price != nil {
+		setParts = append(setParts, fmt.Sprintf("price = $%d", argIndex))
+		args = append(args, *req.Price)
+		argIndex++
+	}
+	if req.Qty != nil {
+		setParts = append(setParts, fmt.Sprintf("qty = $%d", argIndex))
+		args = append(args, *req.Qty)
+		argIndex++
+	}
+
+	if len(setParts) == 0 {
+		return r.GetByID(ctx, id)
+	}
+
+	setParts = append(setParts, fmt.Sprintf("updated_at = $%d", argIndex))
+	args = append(args, time.Now())
+	argIndex++
+
+	args = append(args, id)
+	query := fmt.Sprintf(`
+		UPDATE product_variants
+		SET %s
+		WHERE id = $%d
+		RETURNING id, product_id, sku, options, price, qty, created_at, updated_at`, strings.Join(setParts, ", "), argIndex)
+
+	var variant domain.ProductVariant
+	err := conn(ctx, r.db).QueryRowxContext(ctx, query, args...).StructScan(&variant)
+	if err != nil {
+		return nil, variantError(err)
+	}
+
+	return &variant, nil
+}
+
+func (r *variantRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM product_variants WHERE id = $1`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, id)
+	if err != nil {
+		return variantError(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrVariantNotFound
+	}
+
+	return nil
+}
+
+func (r *variantRepository) ListByProduct(ctx context.Context, productID uuid.UUID) ([]domain.ProductVariant, error) {
+	query := `
+		SELECT id, product_id, sku, options, price, qty, created_at, updated_at
+		FROM product_variants
+		WHERE product_id = $1
+		ORDER BY sku ASC`
+
+	var variants []domain.ProductVariant
+	err := conn(ctx, r.db).SelectContext(ctx, &variants, query, productID)
+	return variants, err
+}
+
+func (r *variantRepository) ListByProducts(ctx context.Context, productIDs []uuid.UUID) (map[uuid.UUID][]domain.ProductVariant, error) {
+	byProduct := map[uuid.UUID][]domain.ProductVariant{}
+	if len(productIDs) == 0 {
+		return byProduct, nil
+	}
+
+	query := `
+		SELECT id, product_id, sku, options, price, qty, created_at, updated_at
+		FROM product_variants
+		WHERE product_id = ANY($1::uuid[])
+		ORDER BY product_id, sku ASC`
+
+	var variants []domain.ProductVariant
+	if err := conn(ctx, r.db).SelectContext(ctx, &variants, query, uuidArray(productIDs)); err != nil {
+		return nil, err
+	}
+
+	for _, variant := range variants {
+		byProduct[variant.ProductID] = append(byProduct[variant.ProductID], variant)
+	}
+	return byProduct, nil
+}
+
+func variantError(err error) error {
+	if isUniqueViolation(err) {
+		return domain.ErrSKUExists
+	}
+	return translateError(err, domain.ErrVariantNotFound)
+}
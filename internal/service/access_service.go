@@ -0,0 +1,283 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rezajo220/ecommerce/internal/domain"
+	"github.com/rezajo220/ecommerce/internal/repository"
+)
+
+// permissionCacheTTL bounds how long a cached permission set is trusted.
+// Changes made through this service invalidate the cache immediately; the TTL
+// only matters for changes made by another instance or directly in the
+// database.
+const permissionCacheTTL = time.Minute
+
+type AccessService interface {
+	HasPermissions(ctx context.Context, userID uuid.UUID, permissions ...string) (bool, error)
+	CallerHasPermissions(ctx context.Context, permissions ...string) (bool, error)
+	ListPermissions(ctx context.Context) []string
+	ListRoles(ctx context.Context) ([]domain.Role, error)
+	GetRole(ctx context.Context, id uuid.UUID) (*domain.Role, error)
+	CreateRole(ctx context.Context, req *domain.CreateRoleRequest) (*domain.Role, error)
+	ReplaceRole(ctx context.Context, id uuid.UUID, req *domain.ReplaceRoleRequest) (*domain.Role, error)
+	DeleteRole(ctx context.Context, id uuid.UUID) error
+	GetUserRoles(ctx context.Context, userID uuid.UUID) ([]domain.Role, error)
+	SetUserRoles(ctx context.Context, userID uuid.UUID, req *domain.SetUserRolesRequest) ([]domain.Role, error)
+	GrantRole(ctx context.Context, email, roleName string) error
+}
+
+type cachedPermissions struct {
+	permissions map[string]bool
+	loadedAt    time.Time
+}
+
+type accessService struct {
+	transactor repository.Transactor
+	roleRepo   repository.RoleRepository
+	userRepo   repository.UserRepository
+
+	mu    sync.RWMutex
+	cache map[uuid.UUID]cachedPermissions
+	// generation counts invalidations. A load that began before the latest
+	// one may have read the old roles, so its result is not cached.
+	generation uint64
+}
+
+func NewAccessService(transactor repository.Transactor, roleRepo repository.RoleRepository, userRepo repository.UserRepository) AccessService {
+	return &accessService{
+		transactor: transactor,
+		roleRepo:   roleRepo,
+		userRepo:   userRepo,
+		cache:      make(map[uuid.UUID]cachedPermissions),
+	}
+}
+
+// HasPermissions reports whether the user holds every one of permissions
+// through at least one of their roles.
+func (s *accessService) HasPermissions(ctx context.Context, userID uuid.UUID, permissions ...string) (bool, error) {
+	granted, err := s.userPermissions(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, permission := range permissions {
+		if !granted[permission] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// CallerHasPermissions reports whether the authenticated caller holds every
+// one of permissions: through its scopes for an API key, through its roles
+// for a user. Anonymous callers hold none.
+func (s *accessService) CallerHasPermissions(ctx context.Context, permissions ...string) (bool, error) {
+	caller := domain.AuthUserFromContext(ctx)
+	if caller == nil {
+		return false, nil
+	}
+	if caller.APIKey != nil {
+		return caller.APIKey.HasScopes(permissions...), nil
+	}
+	return s.HasPermissions(ctx, caller.ID, permissions...)
+}
+
+func (s *accessService) userPermissions(ctx context.Context, userID uuid.UUID) (map[string]bool, error) {
+	s.mu.RLock()
+	entry, ok := s.cache[userID]
+	generation := s.generation
+	s.mu.RUnlock()
+	if ok && time.Since(entry.loadedAt) < permissionCacheTTL {
+		return entry.permissions, nil
+	}
+
+	permissions, err := s.roleRepo.ListUserPermissions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	granted := make(map[string]bool, len(permissions))
+	for _, permission := range permissions {
+		granted[permission] = true
+	}
+
+	s.mu.Lock()
+	if s.generation == generation {
+		s.cache[userID] = cachedPermissions{permissions: granted, loadedAt: time.Now()}
+	}
+	s.mu.Unlock()
+
+	return granted, nil
+}
+
+// invalidate drops every cached permission set. Role changes can affect any
+// number of users, so the whole cache is cleared rather than tracking which
+// users hold which role.
+func (s *accessService) invalidate() {
+	s.mu.Lock()
+	s.cache = make(map[uuid.UUID]cachedPermissions)
+	s.generation++
+	s.mu.Unlock()
+}
+
+func (s *accessService) ListPermissions(ctx context.Context) []string {
+	return domain.AllPermissions
+}
+
+func (s *accessService) ListRoles(ctx context.Context) ([]domain.Role, error) {
+	return s.roleRepo.List(ctx)
+}
+
+func (s *accessService) GetRole(ctx context.Context, id uuid.UUID) (*domain.Role, error) {
+	return s.roleRepo.GetByID(ctx, id)
+}
+
+func (s *accessService) CreateRole(ctx context.Context, req *domain.CreateRoleRequest) (*domain.Role, error) {
+	if err := domain.ValidatePermissions(req.Permissions); err != nil {
+		return nil, err
+	}
+	req.Permissions = normalizePermissions(req.Permissions)
+
+	var role *domain.Role
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		role, err = s.roleRepo.Create(ctx, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return role, nil
+}
+
+func (s *accessService) ReplaceRole(ctx context.Context, id uuid.UUID, req *domain.ReplaceRoleRequest) (*domain.Role, error) {
+	if err := domain.ValidatePermissions(req.Permissions); err != nil {
+		return nil, err
+	}
+	req.Permissions = normalizePermissions(req.Permissions)
+
+	var role *domain.Role
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		existing, err := s.roleRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if existing.Name == domain.AdminRole {
+			return domain.ErrAdminRoleFixed
+		}
+
+		role, err = s.roleRepo.Replace(ctx, id, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidate()
+	return role, nil
+}
+
+func (s *accessService) DeleteRole(ctx context.Context, id uuid.UUID) error {
+	role, err := s.roleRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if role.Name == domain.AdminRole {
+		return domain.ErrAdminRoleFixed
+	}
+
+	if err := s.roleRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.invalidate()
+	return nil
+}
+
+func (s *accessService) GetUserRoles(ctx context.Context, userID uuid.UUID) ([]domain.Role, error) {
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return s.roleRepo.ListUserRoles(ctx, userID)
+}
+
+func (s *accessService) SetUserRoles(ctx context.Context, userID uuid.UUID, req *domain.SetUserRolesRequest) ([]domain.Role, error) {
+	seen := map[uuid.UUID]bool{}
+	roleIDs := make([]uuid.UUID, 0, len(req.RoleIDs))
+	for _, roleID := range req.RoleIDs {
+		if !seen[roleID] {
+			seen[roleID] = true
+			roleIDs = append(roleIDs, roleID)
+		}
+	}
+
+	var roles []domain.Role
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+			return err
+		}
+
+		count, err := s.roleRepo.CountExisting(ctx, roleIDs)
+		if err != nil {
+			return err
+		}
+		if count != len(roleIDs) {
+			return domain.ErrRoleNotFound
+		}
+
+		if err := s.roleRepo.SetUserRoles(ctx, userID, roleIDs); err != nil {
+			return err
+		}
+
+		roles, err = s.roleRepo.ListUserRoles(ctx, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidate()
+	return roles, nil
+}
+
+// GrantRole adds a role to a user by name. It backs the "user grant" CLI
+// command, which is how the first admin gets access to the admin API.
+func (s *accessService) GrantRole(ctx context.Context, email, roleName string) error {
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+
+	role, err := s.roleRepo.GetByName(ctx, roleName)
+	if err != nil {
+		return err
+	}
+
+	if err := s.roleRepo.AddUserRole(ctx, user.ID, role.ID); err != nil {
+		return err
+	}
+
+	s.invalidate()
+	return nil
+}
+
+// normalizePermissions sorts permissions and drops duplicates.
+func normalizePermissions(permissions []string) []string {
+	seen := make(map[string]bool, len(permissions))
+	result := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		if !seen[permission] {
+			seen[permission] = true
+			result = append(result, permission)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rezajo220/ecommerce/internal/domain"
+	"github.com/rezajo220/ecommerce/internal/repository"
+)
+
+const (
+	apiKeyPrefixBytes = 6
+	apiKeySecretBytes = 32
+)
+
+type APIKeyService interface {
+	CreateKey(ctx context.Context, req *domain.CreateAPIKeyRequest) (*domain.IssuedAPIKey, error)
+	ListKeys(ctx context.Context) ([]domain.APIKey, error)
+	RotateKey(ctx context.Context, id uuid.UUID) (*domain.IssuedAPIKey, error)
+	RevokeKey(ctx context.Context, id uuid.UUID) (*domain.APIKey, error)
+	Authenticate(ctx context.Context, rawKey, clientIP string) (*domain.AuthUser, error)
+}
+
+type apiKeyService struct {
+	transactor    repository.Transactor
+	apiKeyRepo    repository.APIKeyRepository
+	accessService AccessService
+}
+
+func NewAPIKeyService(transactor repository.Transactor, apiKeyRepo repository.APIKeyRepository, accessService AccessService) APIKeyService {
+	return &apiKeyService{
+		transactor:    transactor,
+		apiKeyRepo:    apiKeyRepo,
+		accessService: accessService,
+	}
+}
+
+// CreateKey issues a new key. Callers can only grant scopes they hold
+// themselves, so managing keys does not lead to more access than that.
+func (s *apiKeyService) CreateKey(ctx context.Context, req *domain.CreateAPIKeyRequest) (*domain.IssuedAPIKey, error) {
+	if err := domain.ValidatePermissions(req.Scopes); err != nil {
+		return nil, err
+	}
+	scopes := normalizePermissions(req.Scopes)
+
+	allowedIPs, err := domain.NormalizeAllowedIPs(req.AllowedIPs)
+	if err != nil {
+		return nil, err
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, domain.NewValidationError("expires_at must be in the future")
+	}
+
+	if err := s.checkCallerHolds(ctx, scopes, domain.ErrScopeNotGranted); err != nil {
+		return nil, err
+	}
+
+	prefix, secret, err := generateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := s.apiKeyRepo.Create(ctx, &domain.APIKey{
+		Name:       req.Name,
+		Prefix:     prefix,
+		SecretHash: hashToken(secret),
+		Scopes:     scopes,
+		AllowedIPs: allowedIPs,
+		ExpiresAt:  req.ExpiresAt,
+		CreatedBy:  domain.ActorFromContext(ctx),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.IssuedAPIKey{APIKey: *key, Key: prefix + "_" + secret}, nil
+}
+
+// checkCallerHolds returns denied unless the caller holds every one of
+// scopes, through their roles or, for an API key, its own scopes.
+func (s *apiKeyService) checkCallerHolds(ctx context.Context, scopes []string, denied error) error {
+	caller := domain.AuthUserFromContext(ctx)
+	if caller == nil {
+		return nil
+	}
+
+	if caller.APIKey != nil {
+		if !caller.APIKey.HasScopes(scopes...) {
+			return denied
+		}
+		return nil
+	}
+
+	allowed, err := s.accessService.HasPermissions(ctx, caller.ID, scopes...)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return denied
+	}
+	return nil
+}
+
+func (s *apiKeyService) ListKeys(ctx context.Context) ([]domain.APIKey, error) {
+	return s.apiKeyRepo.List(ctx)
+}
+
+// RotateKey replaces the key's prefix and secret, keeping its name, scopes
+// and restrictions. The old key stops working immediately. Like CreateKey,
+// it is refused unless the caller holds every scope of the key, since the
+// caller gets the new secret.
+func (s *apiKeyService) RotateKey(ctx context.Context, id uuid.UUID) (*domain.IssuedAPIKey, error) {
+	var issued *domain.IssuedAPIKey
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		key, err := s.apiKeyRepo.LockByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if key.RevokedAt != nil {
+			return domain.ErrAPIKeyRevoked
+		}
+		if err := s.checkCallerHolds(ctx, key.Scopes, domain.ErrAPIKeyScopesNotHeld); err != nil {
+			return err
+		}
+
+		prefix, secret, err := generateAPIKey()
+		if err != nil {
+			return err
+		}
+
+		key, err = s.apiKeyRepo.UpdateSecret(ctx, id, prefix, hashToken(secret))
+		if err != nil {
+			return err
+		}
+
+		issued = &domain.IssuedAPIKey{APIKey: *key, Key: prefix + "_" + secret}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return issued, nil
+}
+
+// RevokeKey revokes the key, which needs the caller to hold every scope of
+// it, as for RotateKey.
+func (s *apiKeyService) RevokeKey(ctx context.Context, id uuid.UUID) (*domain.APIKey, error) {
+	var revoked *domain.APIKey
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		key, err := s.apiKeyRepo.LockByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := s.checkCallerHolds(ctx, key.Scopes, domain.ErrAPIKeyScopesNotHeld); err != nil {
+			return err
+		}
+
+		revoked, err = s.apiKeyRepo.Revoke(ctx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return revoked, nil
+}
+
+// Authenticate resolves an X-API-Key header value to its caller. The secret
+// is compared in constant time; last_used_at is updated at most once a
+// minute per key.
+func (s *apiKeyService) Authenticate(ctx context.Context, rawKey, clientIP string) (*domain.AuthUser, error) {
+	separator := strings.LastIndexByte(rawKey, '_')
+	if !strings.HasPrefix(rawKey, domain.APIKeyPrefix) || separator <= len(domain.APIKeyPrefix) {
+		return nil, domain.ErrInvalidAPIKey
+	}
+	prefix, secret := rawKey[:separator], rawKey[separator+1:]
+
+	key, err := s.apiKeyRepo.GetByPrefix(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashToken(secret)), []byte(key.SecretHash)) != 1 {
+		return nil, domain.ErrInvalidAPIKey
+	}
+	if !key.Usable(time.Now()) {
+		return nil, domain.ErrInvalidAPIKey
+	}
+	if !key.AllowsIP(clientIP) {
+		return nil, domain.ErrAPIKeyIPDenied
+	}
+
+	if err := s.apiKeyRepo.TouchLastUsed(ctx, key.ID); err != nil {
+		return nil, err
+	}
+
+	return &domain.AuthUser{ID: key.ID, APIKey: key}, nil
+}
+
+// generateAPIKey returns a new random prefix, including domain.APIKeyPrefix,
+// and secret. Both are hex, so the "_" separating them is unambiguous.
+func generateAPIKey() (string, string, error) {
+	raw := make([]byte, apiKeyPrefixBytes+apiKeySecretBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", err
+	}
+
+	prefix := domain.APIKeyPrefix + hex.EncodeToString(raw[:apiKeyPrefixBytes])
+	secret := hex.EncodeToString(raw[apiKeyPrefixBytes:])
+	return prefix, secret, nil
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rezajo220/ecommerce/internal/domain"
+	"github.com/rezajo220/ecommerce/internal/repository"
+)
+
+// fakeTransactor runs fn without a transaction.
+type fakeTransactor struct{}
+
+func (fakeTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// fakeAPIKeyRepository keeps keys in memory. Only the methods rotating and
+// revoking reach are implemented.
+type fakeAPIKeyRepository struct {
+	repository.APIKeyRepository
+	keys map[uuid.UUID]*domain.APIKey
+}
+
+func (r *fakeAPIKeyRepository) LockByID(ctx context.Context, id uuid.UUID) (*domain.APIKey, error) {
+	key, ok := r.keys[id]
+	if !ok {
+		return nil, domain.ErrAPIKeyNotFound
+	}
+	copied := *key
+	return &copied, nil
+}
+
+func (r *fakeAPIKeyRepository) UpdateSecret(ctx context.Context, id uuid.UUID, prefix, secretHash string) (*domain.APIKey, error) {
+	r.keys[id].Prefix, r.keys[id].SecretHash = prefix, secretHash
+	return r.LockByID(ctx, id)
+}
+
+func (r *fakeAPIKeyRepository) Revoke(ctx context.Context, id uuid.UUID) (*domain.APIKey, error) {
+	now := time.Now()
+	r.keys[id].RevokedAt = &now
+	return r.LockByID(ctx, id)
+}
+
+// fakeAccessService grants each user the listed permissions.
+type fakeAccessService struct {
+	AccessService
+	granted map[uuid.UUID][]string
+}
+
+func (s *fakeAccessService) HasPermissions(ctx context.Context, userID uuid.UUID, permissions ...string) (bool, error) {
+	key := domain.APIKey{Scopes: s.granted[userID]}
+	return key.HasScopes(permissions...), nil
+}
+
+func TestAPIKeyServiceRequiresCallerToHoldKeyScopes(t *testing.T) {
+	keyID := uuid.New()
+	manager := &domain.AuthUser{ID: uuid.New()}
+	admin := &domain.AuthUser{ID: uuid.New()}
+	managingKey := &domain.AuthUser{ID: uuid.New(), APIKey: &domain.APIKey{Scopes: []string{domain.PermAPIKeysManage}}}
+	access := &fakeAccessService{granted: map[uuid.UUID][]string{
+		manager.ID: {domain.PermAPIKeysManage},
+		admin.ID:   {domain.PermAPIKeysManage, domain.PermProductsWrite},
+	}}
+
+	tests := []struct {
+		name    string
+		caller  *domain.AuthUser
+		wantErr error
+	}{
+		{name: "user lacking a scope", caller: manager, wantErr: domain.ErrAPIKeyScopesNotHeld},
+		{name: "api key lacking a scope", caller: managingKey, wantErr: domain.ErrAPIKeyScopesNotHeld},
+		{name: "user holding every scope", caller: admin},
+	}
+
+	operations := map[string]func(s APIKeyService, ctx context.Context) error{
+		"rotate": func(s APIKeyService, ctx context.Context) error {
+			_, err := s.RotateKey(ctx, keyID)
+			return err
+		},
+		"revoke": func(s APIKeyService, ctx context.Context) error {
+			_, err := s.RevokeKey(ctx, keyID)
+			return err
+		},
+	}
+
+	for operation, run := range operations {
+		for _, tt := range tests {
+			t.Run(operation+"/"+tt.name, func(t *testing.T) {
+				repo := &fakeAPIKeyRepository{keys: map[uuid.UUID]*domain.APIKey{
+					keyID: {ID: keyID, Prefix: "old", Scopes: []string{domain.PermProductsWrite}},
+				}}
+				service := NewAPIKeyService(fakeTransactor{}, repo, access)
+
+				err := run(service, domain.WithAuthUser(context.Background(), tt.caller))
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+
+				key := repo.keys[keyID]
+				changed := key.Prefix != "old" || key.RevokedAt != nil
+				if changed != (tt.wantErr == nil) {
+					t.Errorf("key changed = %v, want %v", changed, tt.wantErr == nil)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"context"
+	"math"
+
+	"github.com/google/uuid"
+	"github.com/rezajo220/ecommerce/internal/domain"
+	"github.com/rezajo220/ecommerce/internal/repository"
+)
+
+type AuditService interface {
+	ListAuditLog(ctx context.Context, filter *domain.AuditFilter, page, limit int) (*domain.AuditListResponse, error)
+}
+
+type auditService struct {
+	auditRepo repository.AuditRepository
+}
+
+func NewAuditService(auditRepo repository.AuditRepository) AuditService {
+	return &auditService{auditRepo: auditRepo}
+}
+
+func (s *auditService) ListAuditLog(ctx context.Context, filter *domain.AuditFilter, page, limit int) (*domain.AuditListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	offset := (page - 1) * limit
+	entries, total, err := s.auditRepo.List(ctx, filter, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+
+	return &domain.AuditListResponse{
+		Entries:    entries,
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// recordAudit writes an audit entry for a change to an entity, taking the
+// actor and request ID from ctx. before is nil for creates and after is nil
+// for deletes; updates that change nothing are not recorded. Call it inside
+// the transaction making the change.
+func recordAudit(ctx context.Context, repo repository.AuditRepository, action domain.AuditAction, entityType string, entityID uuid.UUID, before, after interface{}) error {
+	changes, err := domain.AuditDiff(before, after)
+	if err != nil {
+		return err
+	}
+	if action == domain.AuditUpdate && len(changes) == 0 {
+		return nil
+	}
+
+	return repo.Record(ctx, &domain.AuditEntry{
+		Actor:      domain.ActorFromContext(ctx),
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Changes:    changes,
+		RequestID:  domain.RequestIDFromContext(ctx),
+	})
+}
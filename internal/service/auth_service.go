@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/rezajo220/ecommerce/internal/domain"
+	"github.com/rezajo220/ecommerce/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const minPasswordLength = 8
+
+type AuthService interface {
+	CreateUser(ctx context.Context, email, password string) (*domain.User, error)
+	Login(ctx context.Context, req *domain.LoginRequest) (*domain.TokenPair, error)
+	Refresh(ctx context.Context, refreshToken string) (*domain.TokenPair, error)
+	Logout(ctx context.Context, refreshToken string) error
+	Authenticate(ctx context.Context, accessToken string) (*domain.AuthUser, error)
+}
+
+// AuthConfig configures token issuing. SigningKey signs access tokens with
+// HS256.
+type AuthConfig struct {
+	SigningKey      []byte
+	Issuer          string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+}
+
+type authService struct {
+	transactor       repository.Transactor
+	userRepo         repository.UserRepository
+	refreshTokenRepo repository.RefreshTokenRepository
+	config           AuthConfig
+}
+
+func NewAuthService(transactor repository.Transactor, userRepo repository.UserRepository, refreshTokenRepo repository.RefreshTokenRepository, config AuthConfig) AuthService {
+	return &authService{
+		transactor:       transactor,
+		userRepo:         userRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		config:           config,
+	}
+}
+
+// accessClaims are the claims of an access token; the subject is the user ID.
+type accessClaims struct {
+	Email string `json:"email"`
+	jwt.RegisteredClaims
+}
+
+// dummyHash is compared against when the email is unknown, so a failed login
+// takes as long whether or not the user exists.
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("not a real password"), bcrypt.DefaultCost)
+
+func (s *authService) CreateUser(ctx context.Context, email, password string) (*domain.User, error) {
+	email = strings.TrimSpace(email)
+	if !strings.Contains(email, "@") {
+		return nil, domain.NewValidationError("email must be a valid email address")
+	}
+	if len(password) < minPasswordLength {
+		return nil, domain.NewValidationError("password must be at least %d characters", minPasswordLength)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.userRepo.Create(ctx, email, string(hash))
+}
+
+func (s *authService) Login(ctx context.Context, req *domain.LoginRequest) (*domain.TokenPair, error) {
+	user, err := s.userRepo.GetByEmail(ctx, req.Email)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(req.Password))
+		return nil, domain.ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
+		return nil, domain.ErrInvalidCredentials
+	}
+
+	return s.issueTokens(ctx, user, uuid.New())
+}
+
+// Refresh exchanges a refresh token for a new token pair. The presented token
+// is revoked; presenting a revoked token again means it leaked, so its whole
+// family is revoked and the caller has to log in again.
+func (s *authService) Refresh(ctx context.Context, refreshToken string) (*domain.TokenPair, error) {
+	var tokens *domain.TokenPair
+	reused := false
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		stored, err := s.refreshTokenRepo.LockByHash(ctx, hashToken(refreshToken))
+		if err != nil {
+			return err
+		}
+
+		if stored.RevokedAt != nil {
+			reused = true
+			return s.refreshTokenRepo.RevokeFamily(ctx, stored.FamilyID)
+		}
+		if !stored.ExpiresAt.After(time.Now()) {
+			return domain.ErrInvalidToken
+		}
+
+		if err := s.refreshTokenRepo.Revoke(ctx, stored.ID); err != nil {
+			return err
+		}
+
+		user, err := s.userRepo.GetByID(ctx, stored.UserID)
+		if err != nil {
+			return err
+		}
+
+		tokens, err = s.issueTokens(ctx, user, stored.FamilyID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if reused {
+		return nil, domain.ErrInvalidToken
+	}
+
+	return tokens, nil
+}
+
+// Logout revokes the refresh token and every token rotated from the same
+// login. Access tokens already issued stay valid until they expire.
+func (s *authService) Logout(ctx context.Context, refreshToken string) error {
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		stored, err := s.refreshTokenRepo.LockByHash(ctx, hashToken(refreshToken))
+		if err != nil {
+			return err
+		}
+
+		return s.refreshTokenRepo.RevokeFamily(ctx, stored.FamilyID)
+	})
+}
+
+// Authenticate validates an access token and returns the user it was issued to.
+func (s *authService) Authenticate(ctx context.Context, accessToken string) (*domain.AuthUser, error) {
+	var claims accessClaims
+	_, err := jwt.ParseWithClaims(accessToken, &claims, func(*jwt.Token) (interface{}, error) {
+		return s.config.SigningKey, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(s.config.Issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, domain.ErrInvalidToken
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, domain.ErrInvalidToken
+	}
+
+	return &domain.AuthUser{ID: userID, Email: claims.Email}, nil
+}
+
+func (s *authService) issueTokens(ctx context.Context, user *domain.User, familyID uuid.UUID) (*domain.TokenPair, error) {
+	now := time.Now()
+	claims := accessClaims{
+		Email: user.Email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   user.ID.String(),
+			Issuer:    s.config.Issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.config.AccessTokenTTL)),
+			ID:        uuid.NewString(),
+		},
+	}
+	accessToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.config.SigningKey)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+	refreshToken := base64.RawURLEncoding.EncodeToString(raw)
+
+	err = s.refreshTokenRepo.Create(ctx, &domain.RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(refreshToken),
+		ExpiresAt: now.Add(s.config.RefreshTokenTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(s.config.AccessTokenTTL.Seconds()),
+	}, nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
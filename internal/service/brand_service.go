@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/rezajo220/ecommerce/internal/domain"
@@ -11,46 +12,145 @@ import (
 
 type BrandService interface {
 	CreateBrand(ctx context.Context, req *domain.CreateBrandRequest) (*domain.Brand, error)
-	DeleteBrand(ctx context.Context, id uuid.UUID) error
-	ListBrands(ctx context.Context) ([]domain.Brand, error)
+	GetBrand(ctx context.Context, id uuid.UUID, includeDeleted bool) (*domain.Brand, error)
+	UpdateBrand(ctx context.Context, id uuid.UUID, req *domain.UpdateBrandRequest, version *int) (*domain.Brand, error)
+	ReplaceBrand(ctx context.Context, id uuid.UUID, req *domain.ReplaceBrandRequest, version *int) (*domain.Brand, error)
+	DeleteBrand(ctx context.Context, id uuid.UUID, version *int) error
+	RestoreBrand(ctx context.Context, id uuid.UUID) (*domain.Brand, error)
+	PurgeDeletedBrands(ctx context.Context, deletedBefore time.Time) (int, error)
+	ListBrands(ctx context.Context, includeDeleted bool) ([]domain.Brand, error)
 }
 
 type brandService struct {
+	transactor  repository.Transactor
 	brandRepo   repository.BrandRepository
 	productRepo repository.ProductRepository
+	auditRepo   repository.AuditRepository
 }
 
-func NewBrandService(brandRepo repository.BrandRepository, productRepo repository.ProductRepository) BrandService {
+func NewBrandService(transactor repository.Transactor, brandRepo repository.BrandRepository, productRepo repository.ProductRepository, auditRepo repository.AuditRepository) BrandService {
 	return &brandService{
+		transactor:  transactor,
 		brandRepo:   brandRepo,
 		productRepo: productRepo,
+		auditRepo:   auditRepo,
 	}
 }
 
 func (s *brandService) CreateBrand(ctx context.Context, req *domain.CreateBrandRequest) (*domain.Brand, error) {
-	return s.brandRepo.Create(ctx, req)
-}
-
-func (s *brandService) DeleteBrand(ctx context.Context, id uuid.UUID) error {
-	brand, err := s.brandRepo.GetByID(ctx, id)
+	var brand *domain.Brand
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		brand, err = s.brandRepo.Create(ctx, req)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditRepo, domain.AuditCreate, domain.AuditEntityBrand, brand.ID, nil, brand)
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if brand == nil {
-		return errors.New("brand not found")
+
+	return brand, nil
+}
+
+func (s *brandService) GetBrand(ctx context.Context, id uuid.UUID, includeDeleted bool) (*domain.Brand, error) {
+	if includeDeleted {
+		return s.brandRepo.GetByIDIncludingDeleted(ctx, id)
 	}
+	return s.brandRepo.GetByID(ctx, id)
+}
+
+// UpdateBrand, ReplaceBrand and DeleteBrand take the version the caller last
+// read, if it sent one; the write fails with domain.ErrVersionMismatch when
+// the brand has changed since.
+func (s *brandService) UpdateBrand(ctx context.Context, id uuid.UUID, req *domain.UpdateBrandRequest, version *int) (*domain.Brand, error) {
+	var brand *domain.Brand
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		before, err := s.brandRepo.LockByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if version != nil && *version != before.Version {
+			return domain.ErrVersionMismatch
+		}
+		if req.ChangesNothing(before) {
+			brand = before
+			return nil
+		}
 
-	isUsed, err := s.brandRepo.IsUsedByProducts(ctx, id)
+		brand, err = s.brandRepo.Update(ctx, id, req, version)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditRepo, domain.AuditUpdate, domain.AuditEntityBrand, id, before, brand)
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if isUsed {
-		return errors.New("cannot delete brand: it is being used by products")
+
+	return brand, nil
+}
+
+func (s *brandService) ReplaceBrand(ctx context.Context, id uuid.UUID, req *domain.ReplaceBrandRequest, version *int) (*domain.Brand, error) {
+	return s.UpdateBrand(ctx, id, &domain.UpdateBrandRequest{BrandName: &req.BrandName}, version)
+}
+
+func (s *brandService) DeleteBrand(ctx context.Context, id uuid.UUID, version *int) error {
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		before, err := s.brandRepo.LockByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		isUsed, err := s.brandRepo.IsUsedByProducts(ctx, id)
+		if err != nil {
+			return err
+		}
+		if isUsed {
+			return domain.ErrBrandInUse
+		}
+
+		if err := s.brandRepo.Delete(ctx, id, version); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditRepo, domain.AuditDelete, domain.AuditEntityBrand, id, before, nil)
+	})
+}
+
+func (s *brandService) RestoreBrand(ctx context.Context, id uuid.UUID) (*domain.Brand, error) {
+	var brand *domain.Brand
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		before, err := s.brandRepo.GetByIDIncludingDeleted(ctx, id)
+		if err != nil {
+			return err
+		}
+		if before.DeletedAt == nil {
+			return domain.ErrBrandNotDeleted
+		}
+
+		brand, err = s.brandRepo.Restore(ctx, id)
+		if err != nil {
+			if errors.Is(err, domain.ErrBrandNotFound) {
+				return domain.ErrBrandNotDeleted
+			}
+			return err
+		}
+		return recordAudit(ctx, s.auditRepo, domain.AuditRestore, domain.AuditEntityBrand, id, before, brand)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return s.brandRepo.Delete(ctx, id)
+	return brand, nil
+}
+
+// PurgeDeletedBrands only removes brands no product refers to any more, so
+// purge products first.
+func (s *brandService) PurgeDeletedBrands(ctx context.Context, deletedBefore time.Time) (int, error) {
+	return s.brandRepo.PurgeDeleted(ctx, deletedBefore)
 }
 
-func (s *brandService) ListBrands(ctx context.Context) ([]domain.Brand, error) {
-	return s.brandRepo.List(ctx)
+func (s *brandService) ListBrands(ctx context.Context, includeDeleted bool) ([]domain.Brand, error) {
+	return s.brandRepo.List(ctx, includeDeleted)
 }
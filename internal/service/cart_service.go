@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rezajo220/ecommerce/internal/domain"
+	"github.com/rezajo220/ecommerce/internal/repository"
+)
+
+type CartService interface {
+	CreateCart(ctx context.Context) (*domain.Cart, error)
+	GetCart(ctx context.Context, id uuid.UUID) (*domain.Cart, error)
+	AddItem(ctx context.Context, cartID uuid.UUID, req *domain.AddCartItemRequest) (*domain.Cart, error)
+	UpdateItem(ctx context.Context, cartID, itemID uuid.UUID, req *domain.UpdateCartItemRequest) (*domain.Cart, error)
+	RemoveItem(ctx context.Context, cartID, itemID uuid.UUID) (*domain.Cart, error)
+}
+
+type cartService struct {
+	cartRepo    repository.CartRepository
+	productRepo repository.ProductRepository
+}
+
+func NewCartService(cartRepo repository.CartRepository, productRepo repository.ProductRepository) CartService {
+	return &cartService{
+		cartRepo:    cartRepo,
+		productRepo: productRepo,
+	}
+}
+
+// CreateCart creates an empty cart held by the caller.
+func (s *cartService) CreateCart(ctx context.Context) (*domain.Cart, error) {
+	user := domain.AuthUserFromContext(ctx)
+	if user == nil {
+		return nil, domain.ErrMissingToken
+	}
+
+	cart, err := s.cartRepo.Create(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	cart.Items = []domain.CartItem{}
+	return cart, nil
+}
+
+// GetCart reprices every line of an open cart from the current product
+// price, flags lines that ask for more stock than is available and computes
+// the totals.
+func (s *cartService) GetCart(ctx context.Context, id uuid.UUID) (*domain.Cart, error) {
+	cart, err := s.getHeldCart(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	cart.Items, err = s.cartRepo.ListItems(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range cart.Items {
+		item := &cart.Items[i]
+		if cart.CheckedOutAt == nil && item.Reprice() {
+			if err := s.cartRepo.UpdateItemPrice(ctx, item.ID, item.UnitPrice, item.Currency); err != nil {
+				return nil, err
+			}
+		}
+		item.CheckStock()
+	}
+	cart.Total()
+
+	return cart, nil
+}
+
+// AddItem adds the product to the cart. A cart holds products of one
+// currency only, so a product priced in another currency than the cart's
+// other products is rejected with domain.ErrMixedCurrencies.
+func (s *cartService) AddItem(ctx context.Context, cartID uuid.UUID, req *domain.AddCartItemRequest) (*domain.Cart, error) {
+	if _, err := s.getOpenCart(ctx, cartID); err != nil {
+		return nil, err
+	}
+
+	product, err := s.productRepo.GetByID(ctx, req.ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.cartRepo.ListItems(ctx, cartID)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		if item.ProductID != product.ID && item.CurrentCurrency != product.Currency {
+			return nil, domain.ErrMixedCurrencies
+		}
+	}
+
+	if err := s.cartRepo.AddItem(ctx, cartID, req, product.Price, product.Currency); err != nil {
+		return nil, err
+	}
+
+	return s.GetCart(ctx, cartID)
+}
+
+func (s *cartService) UpdateItem(ctx context.Context, cartID, itemID uuid.UUID, req *domain.UpdateCartItemRequest) (*domain.Cart, error) {
+	if _, err := s.getOpenCart(ctx, cartID); err != nil {
+		return nil, err
+	}
+
+	if err := s.cartRepo.UpdateItemQty(ctx, cartID, itemID, req.Qty); err != nil {
+		return nil, err
+	}
+
+	return s.GetCart(ctx, cartID)
+}
+
+func (s *cartService) RemoveItem(ctx context.Context, cartID, itemID uuid.UUID) (*domain.Cart, error) {
+	if _, err := s.getOpenCart(ctx, cartID); err != nil {
+		return nil, err
+	}
+
+	if err := s.cartRepo.DeleteItem(ctx, cartID, itemID); err != nil {
+		return nil, err
+	}
+
+	return s.GetCart(ctx, cartID)
+}
+
+// getHeldCart returns the cart if the caller holds it; other callers' carts
+// are reported as not found.
+func (s *cartService) getHeldCart(ctx context.Context, id uuid.UUID) (*domain.Cart, error) {
+	cart, err := s.cartRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !cart.HeldBy(domain.AuthUserFromContext(ctx)) {
+		return nil, domain.ErrCartNotFound
+	}
+	return cart, nil
+}
+
+// getOpenCart returns the caller's cart unless it has already been checked
+// out.
+func (s *cartService) getOpenCart(ctx context.Context, id uuid.UUID) (*domain.Cart, error) {
+	cart, err := s.getHeldCart(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if cart.CheckedOutAt != nil {
+		return nil, domain.ErrCartCheckedOut
+	}
+	return cart, nil
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"math"
+
+	"github.com/google/uuid"
+	"github.com/rezajo220/ecommerce/internal/domain"
+	"github.com/rezajo220/ecommerce/internal/repository"
+)
+
+type CategoryService interface {
+	CreateCategory(ctx context.Context, req *domain.CreateCategoryRequest) (*domain.Category, error)
+	GetCategory(ctx context.Context, id uuid.UUID) (*domain.Category, error)
+	ReplaceCategory(ctx context.Context, id uuid.UUID, req *domain.ReplaceCategoryRequest) (*domain.Category, error)
+	DeleteCategory(ctx context.Context, id uuid.UUID) error
+	ListCategories(ctx context.Context) ([]domain.Category, error)
+	ListCategoryProducts(ctx context.Context, id uuid.UUID, page, limit int) (*domain.ProductListResponse, error)
+}
+
+type categoryService struct {
+	transactor   repository.Transactor
+	categoryRepo repository.CategoryRepository
+	productRepo  repository.ProductRepository
+}
+
+func NewCategoryService(transactor repository.Transactor, categoryRepo repository.CategoryRepository, productRepo repository.ProductRepository) CategoryService {
+	return &categoryService{
+		transactor:   transactor,
+		categoryRepo: categoryRepo,
+		productRepo:  productRepo,
+	}
+}
+
+func (s *categoryService) CreateCategory(ctx context.Context, req *domain.CreateCategoryRequest) (*domain.Category, error) {
+	if req.ParentID != nil {
+		if _, err := s.categoryRepo.GetByID(ctx, *req.ParentID); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.categoryRepo.Create(ctx, req)
+}
+
+func (s *categoryService) GetCategory(ctx context.Context, id uuid.UUID) (*domain.Category, error) {
+	category, err := s.categoryRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	category.Path, err = s.categoryRepo.Path(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return category, nil
+}
+
+// ReplaceCategory holds the hierarchy lock from the cycle check to the
+// write, so concurrent moves are checked against each other's result.
+func (s *categoryService) ReplaceCategory(ctx context.Context, id uuid.UUID, req *domain.ReplaceCategoryRequest) (*domain.Category, error) {
+	var category *domain.Category
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.categoryRepo.LockTree(ctx); err != nil {
+			return err
+		}
+		if _, err := s.categoryRepo.GetByID(ctx, id); err != nil {
+			return err
+		}
+
+		if req.ParentID != nil {
+			if _, err := s.categoryRepo.GetByID(ctx, *req.ParentID); err != nil {
+				return err
+			}
+			isDescendant, err := s.categoryRepo.IsDescendant(ctx, id, *req.ParentID)
+			if err != nil {
+				return err
+			}
+			if isDescendant {
+				return domain.ErrCategoryCycle
+			}
+		}
+
+		var err error
+		category, err = s.categoryRepo.Replace(ctx, id, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return category, nil
+}
+
+func (s *categoryService) DeleteCategory(ctx context.Context, id uuid.UUID) error {
+	if _, err := s.categoryRepo.GetByID(ctx, id); err != nil {
+		return err
+	}
+
+	hasChildren, err := s.categoryRepo.HasChildren(ctx, id)
+	if err != nil {
+		return err
+	}
+	if hasChildren {
+		return domain.ErrCategoryHasChildren
+	}
+
+	isUsed, err := s.categoryRepo.IsUsedByProducts(ctx, id)
+	if err != nil {
+		return err
+	}
+	if isUsed {
+		return domain.ErrCategoryInUse
+	}
+
+	return s.categoryRepo.Delete(ctx, id)
+}
+
+func (s *categoryService) ListCategories(ctx context.Context) ([]domain.Category, error) {
+	return s.categoryRepo.List(ctx)
+}
+
+func (s *categoryService) ListCategoryProducts(ctx context.Context, id uuid.UUID, page, limit int) (*domain.ProductListResponse, error) {
+	if _, err := s.categoryRepo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	offset := (page - 1) * limit
+	filter := &domain.ProductFilter{CategoryID: &id}
+	products, total, err := s.productRepo.List(ctx, filter, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := attachCategoryPaths(ctx, s.categoryRepo, products); err != nil {
+		return nil, err
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+
+	return &domain.ProductListResponse{
+		Products:   products,
+		Total:      &total,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// attachCategoryPaths fills in the category breadcrumbs of each product.
+func attachCategoryPaths(ctx context.Context, categoryRepo repository.CategoryRepository, products []domain.Product) error {
+	ids := make([]uuid.UUID, len(products))
+	for i := range products {
+		ids[i] = products[i].ID
+	}
+
+	paths, err := categoryRepo.PathsForProducts(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	for i := range products {
+		products[i].Categories = paths[products[i].ID]
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/rezajo220/ecommerce/internal/domain"
+	"github.com/rezajo220/ecommerce/internal/repository"
+)
+
+type CurrencyService interface {
+	ListExchangeRates(ctx context.Context) ([]domain.ExchangeRate, error)
+	SetExchangeRate(ctx context.Context, base, quote string, req *domain.SetExchangeRateRequest) (*domain.ExchangeRate, error)
+	DeleteExchangeRate(ctx context.Context, base, quote string) error
+	ImportExchangeRates(ctx context.Context, csv io.Reader) (*domain.ImportExchangeRatesResponse, error)
+	ListProductPrices(ctx context.Context, productID uuid.UUID) ([]domain.ProductCurrencyPrice, error)
+	SetProductPrice(ctx context.Context, productID uuid.UUID, currency string, req *domain.SetProductCurrencyPriceRequest) (*domain.ProductCurrencyPrice, error)
+	DeleteProductPrice(ctx context.Context, productID uuid.UUID, currency string) error
+	ConvertProducts(ctx context.Context, products []domain.Product, currency domain.Currency) error
+}
+
+type currencyService struct {
+	transactor   repository.Transactor
+	currencyRepo repository.CurrencyRepository
+	productRepo  repository.ProductRepository
+	auditRepo    repository.AuditRepository
+}
+
+func NewCurrencyService(transactor repository.Transactor, currencyRepo repository.CurrencyRepository, productRepo repository.ProductRepository, auditRepo repository.AuditRepository) CurrencyService {
+	return &currencyService{
+		transactor:   transactor,
+		currencyRepo: currencyRepo,
+		productRepo:  productRepo,
+		auditRepo:    auditRepo,
+	}
+}
+
+func (s *currencyService) ListExchangeRates(ctx context.Context) ([]domain.ExchangeRate, error) {
+	return s.currencyRepo.ListRates(ctx)
+}
+
+func (s *currencyService) SetExchangeRate(ctx context.Context, base, quote string, req *domain.SetExchangeRateRequest) (*domain.ExchangeRate, error) {
+	baseCurrency, quoteCurrency, err := currencyPair(base, quote)
+	if err != nil {
+		return nil, err
+	}
+	return s.currencyRepo.UpsertRate(ctx, baseCurrency.Code, quoteCurrency.Code, req.Rate)
+}
+
+func (s *currencyService) DeleteExchangeRate(ctx context.Context, base, quote string) error {
+	baseCurrency, quoteCurrency, err := currencyPair(base, quote)
+	if err != nil {
+		return err
+	}
+	return s.currencyRepo.DeleteRate(ctx, baseCurrency.Code, quoteCurrency.Code)
+}
+
+// ImportExchangeRates sets every rate in the CSV in one transaction; rates
+// not mentioned in it are left as they are.
+func (s *currencyService) ImportExchangeRates(ctx context.Context, csv io.Reader) (*domain.ImportExchangeRatesResponse, error) {
+	rates, err := domain.ParseExchangeRatesCSV(csv)
+	if err != nil {
+		return nil, err
+	}
+
+	stored := make([]domain.ExchangeRate, 0, len(rates))
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		for _, rate := range rates {
+			rate, err := s.currencyRepo.UpsertRate(ctx, rate.BaseCurrency, rate.QuoteCurrency, rate.Rate)
+			if err != nil {
+				return err
+			}
+			stored = append(stored, *rate)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.ImportExchangeRatesResponse{Imported: len(stored), Rates: stored}, nil
+}
+
+func (s *currencyService) ListProductPrices(ctx context.Context, productID uuid.UUID) ([]domain.ProductCurrencyPrice, error) {
+	if _, err := s.productRepo.GetByID(ctx, productID); err != nil {
+		return nil, err
+	}
+	return s.currencyRepo.ListPrices(ctx, productID)
+}
+
+// SetProductPrice fixes the product's price in currency instead of
+// converting it. The price must fit the currency's minor units and cannot be
+// set in the product's own currency.
+func (s *currencyService) SetProductPrice(ctx context.Context, productID uuid.UUID, code string, req *domain.SetProductCurrencyPriceRequest) (*domain.ProductCurrencyPrice, error) {
+	currency, err := domain.LookupCurrency(code)
+	if err != nil {
+		return nil, err
+	}
+	if currency.Round(req.Price).Cmp(req.Price) != 0 {
+		return nil, domain.NewValidationError("%s prices have at most %d decimal places", currency.Code, currency.Decimals)
+	}
+
+	var price *domain.ProductCurrencyPrice
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		product, err := s.productRepo.LockByID(ctx, productID)
+		if err != nil {
+			return err
+		}
+		if product.Currency == currency.Code {
+			return domain.NewValidationError("the product is priced in %s; change its price instead", currency.Code)
+		}
+
+		before, err := s.currencyRepo.GetPrice(ctx, productID, currency.Code)
+		if err != nil && !errors.Is(err, domain.ErrCurrencyPriceNotFound) {
+			return err
+		}
+
+		price, err = s.currencyRepo.UpsertPrice(ctx, productID, currency.Code, req.Price)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditRepo, domain.AuditUpdate, domain.AuditEntityProduct, productID, overrideAuditFields(before), overrideAuditFields(price))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return price, nil
+}
+
+func (s *currencyService) DeleteProductPrice(ctx context.Context, productID uuid.UUID, code string) error {
+	currency, err := domain.LookupCurrency(code)
+	if err != nil {
+		return err
+	}
+
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if _, err := s.productRepo.LockByID(ctx, productID); err != nil {
+			return err
+		}
+
+		before, err := s.currencyRepo.GetPrice(ctx, productID, currency.Code)
+		if err != nil {
+			return err
+		}
+		if err := s.currencyRepo.DeletePrice(ctx, productID, currency.Code); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditRepo, domain.AuditUpdate, domain.AuditEntityProduct, productID, overrideAuditFields(before), nil)
+	})
+}
+
+// overrideAuditFields records an override on the product's audit trail as a
+// price_<currency> field, e.g. price_usd.
+func overrideAuditFields(price *domain.ProductCurrencyPrice) map[string]domain.Decimal {
+	if price == nil {
+		return nil
+	}
+	return map[string]domain.Decimal{"price_" + strings.ToLower(price.Currency): price.Price}
+}
+
+// ConvertProducts rewrites the prices of products in another base currency
+// into currency. A product's override in currency is used as is; otherwise
+// its price, and those of its variants, are multiplied by the exchange rate
+// and rounded to the currency's minor units. Products missing both fail the
+// whole conversion rather than being returned in a mix of currencies.
+func (s *currencyService) ConvertProducts(ctx context.Context, products []domain.Product, currency domain.Currency) error {
+	var ids []uuid.UUID
+	for i := range products {
+		if products[i].Currency != currency.Code {
+			ids = append(ids, products[i].ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	overrides, err := s.currencyRepo.PricesForProducts(ctx, ids, currency.Code)
+	if err != nil {
+		return err
+	}
+	rates, err := s.currencyRepo.ListRates(ctx)
+	if err != nil {
+		return err
+	}
+	rateFor := map[string]domain.Decimal{}
+	for _, rate := range rates {
+		if rate.QuoteCurrency == currency.Code {
+			rateFor[rate.BaseCurrency] = rate.Rate
+		}
+	}
+
+	for i := range products {
+		product := &products[i]
+		if product.Currency == currency.Code {
+			continue
+		}
+
+		rate, hasRate := rateFor[product.Currency]
+		override, hasOverride := overrides[product.ID]
+		if !hasOverride && !hasRate {
+			return domain.NewValidationError("no exchange rate from %s to %s", product.Currency, currency.Code)
+		}
+		if len(product.Variants) > 0 && !hasRate {
+			return domain.NewValidationError("no exchange rate from %s to %s for the variant prices", product.Currency, currency.Code)
+		}
+
+		basePrice := product.Price
+		product.BasePrice = &basePrice
+		product.BaseCurrency = product.Currency
+		product.Currency = currency.Code
+		if hasOverride {
+			product.Price = override
+		} else {
+			product.Price = currency.Round(basePrice.Mul(rate))
+		}
+
+		for j := range product.Variants {
+			product.Variants[j].Price = currency.Round(product.Variants[j].Price.Mul(rate))
+		}
+		if product.PriceRange != nil {
+			product.PriceRange = domain.NewPriceRange(product.Price, product.Variants)
+		}
+	}
+	return nil
+}
+
+func currencyPair(base, quote string) (domain.Currency, domain.Currency, error) {
+	baseCurrency, err := domain.LookupCurrency(base)
+	if err != nil {
+		return domain.Currency{}, domain.Currency{}, err
+	}
+	quoteCurrency, err := domain.LookupCurrency(quote)
+	if err != nil {
+		return domain.Currency{}, domain.Currency{}, err
+	}
+	if baseCurrency.Code == quoteCurrency.Code {
+		return domain.Currency{}, domain.Currency{}, domain.ErrSameCurrency
+	}
+	return baseCurrency, quoteCurrency, nil
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"math"
+
+	"github.com/google/uuid"
+	"github.com/rezajo220/ecommerce/internal/domain"
+	"github.com/rezajo220/ecommerce/internal/repository"
+)
+
+type InventoryService interface {
+	AdjustStock(ctx context.Context, productID uuid.UUID, req *domain.StockAdjustmentRequest) (*domain.InventoryMovement, error)
+	GetStockHistory(ctx context.Context, productID uuid.UUID, page, limit int) (*domain.StockHistoryResponse, error)
+	Reconcile(ctx context.Context) ([]domain.StockDiscrepancy, error)
+}
+
+type inventoryService struct {
+	transactor    repository.Transactor
+	inventoryRepo repository.InventoryRepository
+	productRepo   repository.ProductRepository
+}
+
+func NewInventoryService(transactor repository.Transactor, inventoryRepo repository.InventoryRepository, productRepo repository.ProductRepository) InventoryService {
+	return &inventoryService{
+		transactor:    transactor,
+		inventoryRepo: inventoryRepo,
+		productRepo:   productRepo,
+	}
+}
+
+func (s *inventoryService) AdjustStock(ctx context.Context, productID uuid.UUID, req *domain.StockAdjustmentRequest) (*domain.InventoryMovement, error) {
+	delta, err := req.Delta()
+	if err != nil {
+		return nil, err
+	}
+
+	var movement *domain.InventoryMovement
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		movement, err = applyMovement(ctx, s.inventoryRepo, productID, req.MovementType, delta, req.Reason, domain.ActorFromContext(ctx))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return movement, nil
+}
+
+func (s *inventoryService) GetStockHistory(ctx context.Context, productID uuid.UUID, page, limit int) (*domain.StockHistoryResponse, error) {
+	if _, err := s.productRepo.GetByID(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	offset := (page - 1) * limit
+	movements, total, err := s.inventoryRepo.ListMovements(ctx, productID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+
+	return &domain.StockHistoryResponse{
+		Movements:  movements,
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: totalPages,
+	}, nil
+}
+
+func (s *inventoryService) Reconcile(ctx context.Context) ([]domain.StockDiscrepancy, error) {
+	return s.inventoryRepo.Reconcile(ctx)
+}
+
+// applyMovement changes the stock of a product that is not deleted by delta
+// and records why. It must run inside a transaction: the product row stays
+// locked until it commits. Stock held by active reservations cannot be taken
+// away, and a zero delta records nothing and returns a nil movement.
+func applyMovement(ctx context.Context, repo repository.InventoryRepository, productID uuid.UUID, movementType domain.MovementType, delta float64, reason, actor string) (*domain.InventoryMovement, error) {
+	level, err := repo.LockStock(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	return recordMovement(ctx, repo, level, productID, movementType, delta, reason, actor)
+}
+
+// returnStock is applyMovement for goods coming back from an order, which go
+// back into stock even if their product has been deleted meanwhile.
+func returnStock(ctx context.Context, repo repository.InventoryRepository, productID uuid.UUID, qty float64, reason, actor string) error {
+	level, err := repo.LockStockIncludingDeleted(ctx, productID)
+	if err != nil {
+		return err
+	}
+	_, err = recordMovement(ctx, repo, level, productID, domain.MovementReturn, qty, reason, actor)
+	return err
+}
+
+// recordMovement records a movement of the product whose level was locked.
+func recordMovement(ctx context.Context, repo repository.InventoryRepository, level *domain.StockLevel, productID uuid.UUID, movementType domain.MovementType, delta float64, reason, actor string) (*domain.InventoryMovement, error) {
+	if delta == 0 {
+		return nil, nil
+	}
+
+	if delta < 0 && -delta > level.Available() {
+		return nil, domain.ErrInsufficientStock
+	}
+
+	return repo.RecordMovement(ctx, &domain.InventoryMovement{
+		ProductID:    productID,
+		MovementType: movementType,
+		Quantity:     delta,
+		Reason:       reason,
+		Actor:        actor,
+	})
+}
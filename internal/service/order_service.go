@@ -0,0 +1,329 @@
+package services
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rezajo220/ecommerce/internal/domain"
+	"github.com/rezajo220/ecommerce/internal/repository"
+)
+
+type OrderService interface {
+	Checkout(ctx context.Context, req *domain.CheckoutRequest) (*domain.Order, error)
+	GetOrder(ctx context.Context, id uuid.UUID) (*domain.Order, error)
+	ListOrders(ctx context.Context, status domain.OrderStatus, page, limit int) (*domain.OrderListResponse, error)
+	UpdateOrderStatus(ctx context.Context, id uuid.UUID, req *domain.UpdateOrderStatusRequest) (*domain.Order, error)
+	ExpirePendingOrders(ctx context.Context, ttl time.Duration) (int, error)
+}
+
+type orderService struct {
+	transactor    repository.Transactor
+	orderRepo     repository.OrderRepository
+	cartRepo      repository.CartRepository
+	productRepo   repository.ProductRepository
+	inventoryRepo repository.InventoryRepository
+	paymentRepo   repository.PaymentRepository
+	accessService AccessService
+}
+
+func NewOrderService(transactor repository.Transactor, orderRepo repository.OrderRepository, cartRepo repository.CartRepository, productRepo repository.ProductRepository, inventoryRepo repository.InventoryRepository, paymentRepo repository.PaymentRepository, accessService AccessService) OrderService {
+	return &orderService{
+		transactor:    transactor,
+		orderRepo:     orderRepo,
+		cartRepo:      cartRepo,
+		productRepo:   productRepo,
+		inventoryRepo: inventoryRepo,
+		paymentRepo:   paymentRepo,
+		accessService: accessService,
+	}
+}
+
+// Checkout turns a cart or an explicit list of lines into a pending order.
+// Everything happens in one transaction: the products are locked, their
+// current name, price and currency are copied onto the order and the ordered
+// qty is taken out of stock as sale movements. All products must be priced
+// in the same currency, which becomes the order's. The order is held by the
+// caller, who must also hold the cart.
+func (s *orderService) Checkout(ctx context.Context, req *domain.CheckoutRequest) (*domain.Order, error) {
+	if (req.CartID == nil) == (len(req.Items) == 0) {
+		return nil, domain.NewValidationError("either cart_id or items is required, but not both")
+	}
+	user := domain.AuthUserFromContext(ctx)
+	if user == nil {
+		return nil, domain.ErrMissingToken
+	}
+
+	var order *domain.Order
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		lines, err := s.checkoutLines(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		pending := &domain.Order{CartID: req.CartID, HolderID: &user.ID, Status: domain.OrderPending}
+		for _, line := range lines {
+			// Lock before reading the price so the snapshot matches the
+			// stock the sale is taken from.
+			if _, err := s.inventoryRepo.LockStock(ctx, line.ProductID); err != nil {
+				return err
+			}
+			product, err := s.productRepo.GetByID(ctx, line.ProductID)
+			if err != nil {
+				return err
+			}
+
+			if pending.Currency == "" {
+				pending.Currency = product.Currency
+			}
+			if product.Currency != pending.Currency {
+				return domain.ErrMixedCurrencies
+			}
+
+			productID := product.ID
+			item := domain.OrderItem{
+				ProductID:   &productID,
+				ProductName: product.ProductName,
+				UnitPrice:   product.Price,
+				Currency:    product.Currency,
+				Qty:         line.Qty,
+				LineTotal:   product.Price.Mul(domain.DecimalFromFloat(line.Qty)),
+			}
+			pending.Items = append(pending.Items, item)
+			pending.Total = pending.Total.Add(item.LineTotal)
+		}
+
+		order, err = s.orderRepo.Create(ctx, pending)
+		if err != nil {
+			return err
+		}
+
+		for _, line := range lines {
+			_, err := applyMovement(ctx, s.inventoryRepo, line.ProductID, domain.MovementSale, -line.Qty, "order "+order.ID.String(), domain.ActorFromContext(ctx))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// checkoutLines returns the lines to order, one per product and sorted by
+// product ID so concurrent checkouts lock products in the same order. A cart
+// is marked checked out on the way.
+func (s *orderService) checkoutLines(ctx context.Context, req *domain.CheckoutRequest) ([]domain.CheckoutItem, error) {
+	var lines []domain.CheckoutItem
+	if req.CartID != nil {
+		cart, err := s.cartRepo.LockByID(ctx, *req.CartID)
+		if err != nil {
+			return nil, err
+		}
+		if !cart.HeldBy(domain.AuthUserFromContext(ctx)) {
+			return nil, domain.ErrCartNotFound
+		}
+		if cart.CheckedOutAt != nil {
+			return nil, domain.ErrCartCheckedOut
+		}
+
+		items, err := s.cartRepo.ListItems(ctx, cart.ID)
+		if err != nil {
+			return nil, err
+		}
+		if len(items) == 0 {
+			return nil, domain.ErrCartEmpty
+		}
+		for _, item := range items {
+			lines = append(lines, domain.CheckoutItem{ProductID: item.ProductID, Qty: item.Qty})
+		}
+
+		if err := s.cartRepo.MarkCheckedOut(ctx, cart.ID); err != nil {
+			return nil, err
+		}
+	} else {
+		byProduct := map[uuid.UUID]int{}
+		for _, item := range req.Items {
+			if i, ok := byProduct[item.ProductID]; ok {
+				lines[i].Qty += item.Qty
+				continue
+			}
+			byProduct[item.ProductID] = len(lines)
+			lines = append(lines, item)
+		}
+	}
+
+	sort.Slice(lines, func(i, j int) bool {
+		return lines[i].ProductID.String() < lines[j].ProductID.String()
+	})
+	return lines, nil
+}
+
+// GetOrder returns the order with its items to the caller who placed it or
+// to a caller with orders:read; anyone else gets domain.ErrOrderNotFound.
+func (s *orderService) GetOrder(ctx context.Context, id uuid.UUID) (*domain.Order, error) {
+	order, err := getReadableOrder(ctx, s.orderRepo, s.accessService, id)
+	if err != nil {
+		return nil, err
+	}
+
+	order.Items, err = s.orderRepo.ListItems(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+func (s *orderService) ListOrders(ctx context.Context, status domain.OrderStatus, page, limit int) (*domain.OrderListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	offset := (page - 1) * limit
+	orders, total, err := s.orderRepo.List(ctx, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+
+	return &domain.OrderListResponse{
+		Orders:     orders,
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// UpdateOrderStatus moves the order along its state machine. Transitions
+// that put the items back in stock record a return movement per item.
+func (s *orderService) UpdateOrderStatus(ctx context.Context, id uuid.UUID, req *domain.UpdateOrderStatusRequest) (*domain.Order, error) {
+	var order *domain.Order
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		current, err := s.orderRepo.LockByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		order, err = s.transition(ctx, current, req.Status)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// ExpirePendingOrders cancels orders left unpaid for longer than ttl, which
+// returns their stock, and reports how many it cancelled. Each order is
+// cancelled in its own transaction; one paid in the meantime, or with a
+// payment under way, is skipped.
+func (s *orderService) ExpirePendingOrders(ctx context.Context, ttl time.Duration) (int, error) {
+	cutoff := time.Now().Add(-ttl)
+	ids, err := s.orderRepo.ListPendingBefore(ctx, cutoff)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, id := range ids {
+		cancelled := false
+		err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			current, err := s.orderRepo.LockByID(ctx, id)
+			if err != nil {
+				return err
+			}
+			if current.Status != domain.OrderPending || current.CreatedAt.After(cutoff) {
+				return nil
+			}
+			paying, err := s.paymentRepo.HasOpenPayment(ctx, id)
+			if err != nil || paying {
+				return err
+			}
+
+			_, err = s.transition(ctx, current, domain.OrderCancelled)
+			cancelled = err == nil
+			return err
+		})
+		if err != nil {
+			return expired, err
+		}
+		if cancelled {
+			expired++
+		}
+	}
+	return expired, nil
+}
+
+// getReadableOrder returns the order if the caller placed it or holds
+// orders:read. Other callers are told it does not exist, so order IDs cannot
+// be probed.
+func getReadableOrder(ctx context.Context, orderRepo repository.OrderRepository, accessService AccessService, id uuid.UUID) (*domain.Order, error) {
+	order, err := orderRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if order.HeldBy(domain.AuthUserFromContext(ctx)) {
+		return order, nil
+	}
+
+	allowed, err := accessService.CallerHasPermissions(ctx, domain.PermOrdersRead)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, domain.ErrOrderNotFound
+	}
+	return order, nil
+}
+
+// transition moves the locked order current to next, restocking its items
+// when next puts them back.
+func (s *orderService) transition(ctx context.Context, current *domain.Order, next domain.OrderStatus) (*domain.Order, error) {
+	if !current.Status.CanTransitionTo(next) {
+		return nil, domain.NewConflictError("cannot change order status from %s to %s", current.Status, next)
+	}
+
+	items, err := s.orderRepo.ListItems(ctx, current.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if current.Status.RestocksOn(next) {
+		restock := make([]domain.OrderItem, 0, len(items))
+		for _, item := range items {
+			if item.ProductID != nil {
+				restock = append(restock, item)
+			}
+		}
+		// Same lock order as Checkout.
+		sort.Slice(restock, func(i, j int) bool {
+			return restock[i].ProductID.String() < restock[j].ProductID.String()
+		})
+
+		for _, item := range restock {
+			err := returnStock(ctx, s.inventoryRepo, *item.ProductID, item.Qty, "order "+current.ID.String()+" "+string(next), domain.ActorFromContext(ctx))
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	order, err := s.orderRepo.UpdateStatus(ctx, current.ID, next)
+	if err != nil {
+		return nil, err
+	}
+	order.Items = items
+	return order, nil
+}
@@ -0,0 +1,314 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rezajo220/ecommerce/internal/domain"
+	"github.com/rezajo220/ecommerce/internal/payment"
+	"github.com/rezajo220/ecommerce/internal/repository"
+)
+
+type PaymentService interface {
+	PayOrder(ctx context.Context, orderID uuid.UUID, req *domain.PayOrderRequest) (*domain.Payment, error)
+	ListOrderPayments(ctx context.Context, orderID uuid.UUID) ([]domain.Payment, error)
+	RefundPayment(ctx context.Context, id uuid.UUID) (*domain.Payment, error)
+	HandleWebhook(ctx context.Context, payload []byte, signature string) (*domain.Payment, error)
+	ExpireStalePayments(ctx context.Context, ttl time.Duration) (int, error)
+}
+
+type paymentService struct {
+	transactor    repository.Transactor
+	gateway       payment.PaymentGateway
+	paymentRepo   repository.PaymentRepository
+	orderRepo     repository.OrderRepository
+	orderService  OrderService
+	accessService AccessService
+}
+
+func NewPaymentService(transactor repository.Transactor, gateway payment.PaymentGateway, paymentRepo repository.PaymentRepository, orderRepo repository.OrderRepository, orderService OrderService, accessService AccessService) PaymentService {
+	return &paymentService{
+		transactor:    transactor,
+		gateway:       gateway,
+		paymentRepo:   paymentRepo,
+		orderRepo:     orderRepo,
+		orderService:  orderService,
+		accessService: accessService,
+	}
+}
+
+// paymentTransitions lists the statuses a payment may move to from each
+// status. Anything else, such as a late "failed" event for a captured
+// payment, is ignored.
+var paymentTransitions = map[domain.PaymentStatus][]domain.PaymentStatus{
+	domain.PaymentAuthorized: {domain.PaymentCaptured, domain.PaymentFailed},
+	domain.PaymentCaptured:   {domain.PaymentRefunded},
+}
+
+// orderStatusForPayment is the order status a payment status moves its order to.
+var orderStatusForPayment = map[domain.PaymentStatus]domain.OrderStatus{
+	domain.PaymentCaptured: domain.OrderPaid,
+	domain.PaymentRefunded: domain.OrderRefunded,
+}
+
+// PayOrder authorizes and captures the order total with the gateway. Gateway
+// calls happen outside database transactions; each answer is recorded as it
+// comes back. The payment is first stored as processing while the order is
+// locked, so a concurrent attempt to pay the same order fails with
+// domain.ErrPaymentInProgress instead of charging twice. Only the caller who
+// placed the order can pay it.
+func (s *paymentService) PayOrder(ctx context.Context, orderID uuid.UUID, req *domain.PayOrderRequest) (*domain.Payment, error) {
+	var processing *domain.Payment
+	var currency string
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		order, err := s.orderRepo.LockByID(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		if !order.HeldBy(domain.AuthUserFromContext(ctx)) {
+			return domain.ErrOrderNotFound
+		}
+		if order.Status != domain.OrderPending {
+			return domain.NewConflictError("order is %s; only pending orders can be paid", order.Status)
+		}
+
+		open, err := s.paymentRepo.HasOpenPayment(ctx, order.ID)
+		if err != nil {
+			return err
+		}
+		if open {
+			return domain.ErrPaymentInProgress
+		}
+
+		currency = order.Currency
+		processing, err = s.paymentRepo.Create(ctx, &domain.Payment{
+			OrderID:  order.ID,
+			Provider: s.gateway.Name(),
+			Amount:   order.Total,
+			Status:   domain.PaymentProcessing,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	authorization, err := s.gateway.Authorize(ctx, &payment.AuthorizeRequest{
+		PaymentID:     processing.ID,
+		OrderID:       processing.OrderID,
+		Amount:        processing.Amount,
+		Currency:      currency,
+		PaymentMethod: req.PaymentMethod,
+	})
+	if err != nil {
+		if _, failErr := s.paymentRepo.UpdateStatus(ctx, processing.ID, domain.PaymentFailed); failErr != nil {
+			return nil, failErr
+		}
+		return nil, err
+	}
+
+	authorized, err := s.paymentRepo.RecordAuthorization(ctx, processing.ID, authorization.ProviderRef, authorization.Status)
+	if err != nil {
+		// The payment was given up as stale meanwhile; releasing the money
+		// is best effort, the authorization also lapses at the provider.
+		s.gateway.Void(ctx, authorization.ProviderRef)
+		return nil, err
+	}
+
+	capture, err := s.gateway.Capture(ctx, authorized.ProviderRef, authorized.Amount)
+	if err != nil {
+		if abandonErr := s.abandon(ctx, authorized); abandonErr != nil {
+			return nil, abandonErr
+		}
+		return nil, err
+	}
+
+	return s.applyGatewayStatus(ctx, authorized.ProviderRef, capture.Status)
+}
+
+// ExpireStalePayments gives up payments left processing or authorized for
+// longer than ttl, after a failed capture or a crash mid-payment, and
+// reports how many it gave up. Authorizations are voided with the gateway
+// and the payments marked failed, so their orders can be paid again or
+// expire. A payment that moved on in the meantime is left alone.
+func (s *paymentService) ExpireStalePayments(ctx context.Context, ttl time.Duration) (int, error) {
+	stale, err := s.paymentRepo.ListUnsettledBefore(ctx, time.Now().Add(-ttl))
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, unsettled := range stale {
+		// Void before marking the payment failed: a capture still under way
+		// then fails at the provider rather than taking money for a
+		// payment recorded as failed.
+		if unsettled.ProviderRef != "" {
+			if _, err := s.gateway.Void(ctx, unsettled.ProviderRef); err != nil {
+				return expired, err
+			}
+		}
+
+		failed := false
+		err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			current, err := s.paymentRepo.LockByID(ctx, unsettled.ID)
+			if err != nil {
+				return err
+			}
+			if current.Status != unsettled.Status || !current.UpdatedAt.Equal(unsettled.UpdatedAt) {
+				return nil
+			}
+
+			_, err = s.paymentRepo.UpdateStatus(ctx, current.ID, domain.PaymentFailed)
+			failed = err == nil
+			return err
+		})
+		if err != nil {
+			return expired, err
+		}
+		if failed {
+			expired++
+		}
+	}
+	return expired, nil
+}
+
+// abandon voids the payment's authorization with the gateway and marks the
+// payment failed, so its order can be paid again.
+func (s *paymentService) abandon(ctx context.Context, authorized *domain.Payment) error {
+	if _, err := s.gateway.Void(ctx, authorized.ProviderRef); err != nil {
+		return err
+	}
+	_, err := s.paymentRepo.UpdateStatus(ctx, authorized.ID, domain.PaymentFailed)
+	return err
+}
+
+// ListOrderPayments lists the payments of an order the caller may read, see
+// OrderService.GetOrder.
+func (s *paymentService) ListOrderPayments(ctx context.Context, orderID uuid.UUID) ([]domain.Payment, error) {
+	if _, err := getReadableOrder(ctx, s.orderRepo, s.accessService, orderID); err != nil {
+		return nil, err
+	}
+
+	return s.paymentRepo.ListByOrder(ctx, orderID)
+}
+
+func (s *paymentService) RefundPayment(ctx context.Context, id uuid.UUID) (*domain.Payment, error) {
+	current, err := s.paymentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if current.Status != domain.PaymentCaptured {
+		return nil, domain.NewConflictError("payment is %s; only captured payments can be refunded", current.Status)
+	}
+
+	refund, err := s.gateway.Refund(ctx, current.ProviderRef, current.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.applyGatewayStatus(ctx, current.ProviderRef, refund.Status)
+}
+
+// HandleWebhook verifies and applies a provider event. Every event is
+// recorded by ID, so a redelivered event changes nothing and returns the
+// payment as it is.
+func (s *paymentService) HandleWebhook(ctx context.Context, payload []byte, signature string) (*domain.Payment, error) {
+	if err := s.gateway.VerifyWebhookSignature(payload, signature); err != nil {
+		return nil, err
+	}
+
+	var event domain.PaymentEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, domain.NewBadRequestError("Invalid webhook payload")
+	}
+	if event.ID == "" || event.ProviderRef == "" {
+		return nil, domain.NewValidationError("webhook event must have an id and a provider_ref")
+	}
+	switch event.Type {
+	case domain.PaymentEventCaptured, domain.PaymentEventFailed, domain.PaymentEventRefunded:
+	default:
+		return nil, domain.NewValidationError("unknown webhook event type %q", event.Type)
+	}
+
+	var result *domain.Payment
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		current, err := s.paymentRepo.LockByProviderRef(ctx, s.gateway.Name(), event.ProviderRef)
+		if err != nil {
+			return err
+		}
+
+		isNew, err := s.paymentRepo.RecordEvent(ctx, event.ID, current.ID, event.Type, payload)
+		if err != nil {
+			return err
+		}
+		if !isNew {
+			result = current
+			return nil
+		}
+
+		result, err = s.applyStatus(ctx, current, event.PaymentStatus())
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *paymentService) applyGatewayStatus(ctx context.Context, providerRef string, status domain.PaymentStatus) (*domain.Payment, error) {
+	var result *domain.Payment
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		current, err := s.paymentRepo.LockByProviderRef(ctx, s.gateway.Name(), providerRef)
+		if err != nil {
+			return err
+		}
+
+		result, err = s.applyStatus(ctx, current, status)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// applyStatus moves a locked payment to status and its order along with it.
+// It is a no-op when the payment is already there or cannot get there, so the
+// same news arriving from the gateway response and from a webhook is applied
+// once.
+func (s *paymentService) applyStatus(ctx context.Context, current *domain.Payment, status domain.PaymentStatus) (*domain.Payment, error) {
+	allowed := false
+	for _, next := range paymentTransitions[current.Status] {
+		allowed = allowed || next == status
+	}
+	if !allowed {
+		return current, nil
+	}
+
+	updated, err := s.paymentRepo.UpdateStatus(ctx, current.ID, status)
+	if err != nil {
+		return nil, err
+	}
+
+	orderStatus, ok := orderStatusForPayment[status]
+	if !ok {
+		return updated, nil
+	}
+
+	order, err := s.orderRepo.GetByID(ctx, current.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status.CanTransitionTo(orderStatus) {
+		req := &domain.UpdateOrderStatusRequest{Status: orderStatus}
+		if _, err := s.orderService.UpdateOrderStatus(ctx, order.ID, req); err != nil {
+			return nil, err
+		}
+	}
+
+	return updated, nil
+}
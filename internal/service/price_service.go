@@ -0,0 +1,222 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rezajo220/ecommerce/internal/domain"
+	"github.com/rezajo220/ecommerce/internal/repository"
+)
+
+type PriceService interface {
+	GetPriceHistory(ctx context.Context, productID uuid.UUID, page, limit int) (*domain.PriceHistoryResponse, error)
+	GetPriceAt(ctx context.Context, productID uuid.UUID, at time.Time) (*domain.ProductPrice, error)
+	SchedulePrice(ctx context.Context, productID uuid.UUID, req *domain.SchedulePriceRequest) (*domain.ProductPrice, error)
+	CancelScheduledPrice(ctx context.Context, productID, id uuid.UUID) error
+	ApplyScheduledPrices(ctx context.Context) (int, error)
+}
+
+type priceService struct {
+	transactor  repository.Transactor
+	priceRepo   repository.ProductPriceRepository
+	productRepo repository.ProductRepository
+	auditRepo   repository.AuditRepository
+}
+
+func NewPriceService(transactor repository.Transactor, priceRepo repository.ProductPriceRepository, productRepo repository.ProductRepository, auditRepo repository.AuditRepository) PriceService {
+	return &priceService{
+		transactor:  transactor,
+		priceRepo:   priceRepo,
+		productRepo: productRepo,
+		auditRepo:   auditRepo,
+	}
+}
+
+// GetPriceHistory lists the product's prices. Deleted and purged products
+// are included since their invoices still exist.
+func (s *priceService) GetPriceHistory(ctx context.Context, productID uuid.UUID, page, limit int) (*domain.PriceHistoryResponse, error) {
+	if err := s.checkHistoryExists(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	offset := (page - 1) * limit
+	prices, total, err := s.priceRepo.ListByProduct(ctx, productID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+
+	return &domain.PriceHistoryResponse{
+		Prices:     prices,
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// GetPriceAt answers what the product cost at a point in time, e.g. when an
+// old invoice is reconciled. Deleted and purged products are included since
+// their invoices still exist.
+func (s *priceService) GetPriceAt(ctx context.Context, productID uuid.UUID, at time.Time) (*domain.ProductPrice, error) {
+	if err := s.checkHistoryExists(ctx, productID); err != nil {
+		return nil, err
+	}
+	return s.priceRepo.At(ctx, productID, at)
+}
+
+// checkHistoryExists returns domain.ErrProductNotFound unless the product
+// exists, deleted or not, or has a price history left from before it was
+// purged.
+func (s *priceService) checkHistoryExists(ctx context.Context, productID uuid.UUID) error {
+	_, err := s.productRepo.GetByIDIncludingDeleted(ctx, productID)
+	if !errors.Is(err, domain.ErrProductNotFound) {
+		return err
+	}
+
+	exists, err := s.priceRepo.HasHistory(ctx, productID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return domain.ErrProductNotFound
+	}
+	return nil
+}
+
+// SchedulePrice adds a price in the product's currency that the scheduler
+// applies once EffectiveFrom has passed. The price must fit the currency's
+// minor units. Scheduling another price for the same instant replaces it.
+func (s *priceService) SchedulePrice(ctx context.Context, productID uuid.UUID, req *domain.SchedulePriceRequest) (*domain.ProductPrice, error) {
+	if !req.EffectiveFrom.After(time.Now()) {
+		return nil, domain.ErrPriceNotInFuture
+	}
+
+	var price *domain.ProductPrice
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		product, err := s.productRepo.LockByID(ctx, productID)
+		if err != nil {
+			return err
+		}
+		currency, err := domain.LookupCurrency(product.Currency)
+		if err != nil {
+			return err
+		}
+		if currency.Round(*req.Price).Cmp(*req.Price) != 0 {
+			return domain.NewValidationError("%s prices have at most %d decimal places", currency.Code, currency.Decimals)
+		}
+
+		price, err = s.priceRepo.Record(ctx, &domain.ProductPrice{
+			ProductID:     productID,
+			Price:         *req.Price,
+			Currency:      product.Currency,
+			EffectiveFrom: req.EffectiveFrom,
+			Actor:         domain.ActorFromContext(ctx),
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return price, nil
+}
+
+func (s *priceService) CancelScheduledPrice(ctx context.Context, productID, id uuid.UUID) error {
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if _, err := s.productRepo.LockByID(ctx, productID); err != nil {
+			return err
+		}
+		return s.priceRepo.DeleteScheduled(ctx, productID, id)
+	})
+}
+
+// ApplyScheduledPrices writes due scheduled prices to their products and
+// returns how many products changed. A written price's history entry starts
+// when it was written rather than when it was scheduled, and when several
+// prices of a product fell due since the last run only the latest is written
+// and the others are dropped from the history, since they were never charged.
+// Prices scheduled in another currency than the product's current
+// one are cancelled rather than applied, so the scheduler never reverts a
+// currency change. Each product is updated in its own transaction, taking
+// the product's lock before the price rows like the product writes do.
+func (s *priceService) ApplyScheduledPrices(ctx context.Context) (int, error) {
+	now := time.Now()
+	productIDs, err := s.priceRepo.DueProductIDs(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	applied := 0
+	for _, productID := range productIDs {
+		changed := false
+		err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			before, err := s.productRepo.LockByID(ctx, productID)
+			if errors.Is(err, domain.ErrProductNotFound) {
+				return nil // deleted since; applied once it is restored
+			}
+			if err != nil {
+				return err
+			}
+
+			if err := s.priceRepo.CancelScheduledInOtherCurrencies(ctx, productID, before.Currency); err != nil {
+				return err
+			}
+			appliedAt := time.Now()
+			due, err := s.priceRepo.LockDue(ctx, productID, appliedAt)
+			if err != nil || len(due) == 0 {
+				return err
+			}
+
+			latest := due[len(due)-1]
+			after, err := s.productRepo.SetPrice(ctx, productID, latest.Price, latest.Currency)
+			if err != nil {
+				return err
+			}
+			if err := s.priceRepo.ApplyDue(ctx, productID, latest.ID, appliedAt); err != nil {
+				return err
+			}
+			changed = true
+			return recordAudit(ctx, s.auditRepo, domain.AuditUpdate, domain.AuditEntityProduct, productID, before, after)
+		})
+		if err != nil {
+			return applied, err
+		}
+		if changed {
+			applied++
+		}
+	}
+	return applied, nil
+}
+
+// recordPriceChange adds product's current price to its history when a
+// write set it, starting now; prices that were due but not yet applied are
+// dropped since they were never charged, and scheduled prices in a currency
+// the product is no longer priced in are cancelled.
+func recordPriceChange(ctx context.Context, priceRepo repository.ProductPriceRepository, product *domain.Product) error {
+	if err := priceRepo.CancelScheduledInOtherCurrencies(ctx, product.ID, product.Currency); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	_, err := priceRepo.Record(ctx, &domain.ProductPrice{
+		ProductID:     product.ID,
+		Price:         product.Price,
+		Currency:      product.Currency,
+		EffectiveFrom: product.UpdatedAt,
+		AppliedAt:     &now,
+		Actor:         domain.ActorFromContext(ctx),
+	})
+	return err
+}
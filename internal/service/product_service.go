@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"math"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/rezajo220/ecommerce/internal/domain"
@@ -12,74 +13,338 @@ import (
 
 type ProductService interface {
 	CreateProduct(ctx context.Context, req *domain.CreateProductRequest) (*domain.Product, error)
-	GetProduct(ctx context.Context, id uuid.UUID) (*domain.Product, error)
-	UpdateProduct(ctx context.Context, id uuid.UUID, req *domain.UpdateProductRequest) (*domain.Product, error)
-	DeleteProduct(ctx context.Context, id uuid.UUID) error
-	ListProducts(ctx context.Context, page, limit int) (*domain.ProductListResponse, error)
+	GetProduct(ctx context.Context, id uuid.UUID, includeDeleted bool) (*domain.Product, error)
+	UpdateProduct(ctx context.Context, id uuid.UUID, req *domain.UpdateProductRequest, version *int) (*domain.Product, error)
+	ReplaceProduct(ctx context.Context, id uuid.UUID, req *domain.ReplaceProductRequest, version *int) (*domain.Product, error)
+	DeleteProduct(ctx context.Context, id uuid.UUID, version *int) error
+	RestoreProduct(ctx context.Context, id uuid.UUID) (*domain.Product, error)
+	PurgeDeletedProducts(ctx context.Context, deletedBefore time.Time) (int, error)
+	SetProductCategories(ctx context.Context, id uuid.UUID, req *domain.SetProductCategoriesRequest) (*domain.Product, error)
+	ListProducts(ctx context.Context, filter *domain.ProductFilter, page, limit int) (*domain.ProductListResponse, error)
+	SearchProducts(ctx context.Context, query string, page, limit int) (*domain.ProductSearchResponse, error)
+	ListProductsByCursor(ctx context.Context, filter *domain.ProductFilter, cursor *domain.ProductCursor, limit int, includeTotal bool) (*domain.ProductListResponse, error)
 }
 
 type productService struct {
-	productRepo repository.ProductRepository
-	brandRepo   repository.BrandRepository
+	transactor    repository.Transactor
+	productRepo   repository.ProductRepository
+	brandRepo     repository.BrandRepository
+	categoryRepo  repository.CategoryRepository
+	variantRepo   repository.VariantRepository
+	inventoryRepo repository.InventoryRepository
+	auditRepo     repository.AuditRepository
+	priceRepo     repository.ProductPriceRepository
 }
 
-func NewProductService(productRepo repository.ProductRepository, brandRepo repository.BrandRepository) ProductService {
+func NewProductService(transactor repository.Transactor, productRepo repository.ProductRepository, brandRepo repository.BrandRepository, categoryRepo repository.CategoryRepository, variantRepo repository.VariantRepository, inventoryRepo repository.InventoryRepository, auditRepo repository.AuditRepository, priceRepo repository.ProductPriceRepository) ProductService {
 	return &productService{
-		productRepo: productRepo,
-		brandRepo:   brandRepo,
+		transactor:    transactor,
+		productRepo:   productRepo,
+		brandRepo:     brandRepo,
+		categoryRepo:  categoryRepo,
+		variantRepo:   variantRepo,
+		inventoryRepo: inventoryRepo,
+		auditRepo:     auditRepo,
+		priceRepo:     priceRepo,
 	}
 }
 
+// CreateProduct stores the product and books its initial qty as a receipt.
 func (s *productService) CreateProduct(ctx context.Context, req *domain.CreateProductRequest) (*domain.Product, error) {
-	brand, err := s.brandRepo.GetByID(ctx, req.BrandID)
-	if err != nil {
+	if req.Currency == "" {
+		req.Currency = domain.DefaultCurrency
+	}
+	if err := normalizeCurrency(&req.Currency); err != nil {
 		return nil, err
 	}
-	if brand == nil {
-		return nil, errors.New("brand not found")
+
+	var product *domain.Product
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if _, err := s.brandRepo.ShareLockByID(ctx, req.BrandID); err != nil {
+			return err
+		}
+
+		var err error
+		product, err = s.productRepo.Create(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		movement, err := applyMovement(ctx, s.inventoryRepo, product.ID, domain.MovementReceipt, req.Qty, "initial stock", domain.ActorFromContext(ctx))
+		if err != nil {
+			return err
+		}
+		if movement != nil {
+			product.Qty = movement.QtyAfter
+			product.AvailableQty += movement.Quantity
+		}
+		if err := recordPriceChange(ctx, s.priceRepo, product); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditRepo, domain.AuditCreate, domain.AuditEntityProduct, product.ID, nil, product)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return s.productRepo.Create(ctx, req)
+	return product, nil
 }
 
-func (s *productService) GetProduct(ctx context.Context, id uuid.UUID) (*domain.Product, error) {
-	product, err := s.productRepo.GetByID(ctx, id)
+func (s *productService) GetProduct(ctx context.Context, id uuid.UUID, includeDeleted bool) (*domain.Product, error) {
+	getByID := s.productRepo.GetByID
+	if includeDeleted {
+		getByID = s.productRepo.GetByIDIncludingDeleted
+	}
+
+	product, err := getByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
-	if product == nil {
-		return nil, errors.New("product not found")
+
+	paths, err := s.categoryRepo.PathsForProducts(ctx, []uuid.UUID{id})
+	if err != nil {
+		return nil, err
 	}
-	return product, nil
+	product.Categories = paths[id]
+
+	products := []domain.Product{*product}
+	if err := attachVariants(ctx, s.variantRepo, products); err != nil {
+		return nil, err
+	}
+
+	return &products[0], nil
 }
 
-func (s *productService) UpdateProduct(ctx context.Context, id uuid.UUID, req *domain.UpdateProductRequest) (*domain.Product, error) {
-	if req.BrandID != uuid.Nil {
-		brand, err := s.brandRepo.GetByID(ctx, req.BrandID)
+// UpdateProduct, ReplaceProduct and DeleteProduct take the version the
+// caller last read, if it sent one; the write fails with
+// domain.ErrVersionMismatch when the product has changed since.
+func (s *productService) UpdateProduct(ctx context.Context, id uuid.UUID, req *domain.UpdateProductRequest, version *int) (*domain.Product, error) {
+	if req.Currency != nil {
+		if err := normalizeCurrency(req.Currency); err != nil {
+			return nil, err
+		}
+	}
+
+	var product *domain.Product
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		before, err := s.productRepo.LockByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if req.BrandID != nil && *req.BrandID != before.BrandID {
+			if _, err := s.brandRepo.ShareLockByID(ctx, *req.BrandID); err != nil {
+				return err
+			}
+		}
+		if version != nil && *version != before.Version {
+			return domain.ErrVersionMismatch
+		}
+		if req.ChangesNothing(before) {
+			product = before
+			return nil
+		}
+
+		product, err = s.productRepo.Update(ctx, id, req, version)
 		if err != nil {
+			return err
+		}
+		if req.Qty != nil {
+			if err := s.setQty(ctx, product, *req.Qty); err != nil {
+				return err
+			}
+		}
+		if priceChanged(before, product) {
+			if err := recordPriceChange(ctx, s.priceRepo, product); err != nil {
+				return err
+			}
+		}
+		return recordAudit(ctx, s.auditRepo, domain.AuditUpdate, domain.AuditEntityProduct, id, before, product)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return product, nil
+}
+
+func (s *productService) ReplaceProduct(ctx context.Context, id uuid.UUID, req *domain.ReplaceProductRequest, version *int) (*domain.Product, error) {
+	if req.Currency != "" {
+		if err := normalizeCurrency(&req.Currency); err != nil {
 			return nil, err
 		}
-		if brand == nil {
-			return nil, errors.New("brand not found")
+	}
+
+	var product *domain.Product
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		before, err := s.productRepo.LockByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if req.BrandID != before.BrandID {
+			if _, err := s.brandRepo.ShareLockByID(ctx, req.BrandID); err != nil {
+				return err
+			}
+		}
+
+		product, err = s.productRepo.Replace(ctx, id, req, version)
+		if err != nil {
+			return err
+		}
+		if err := s.setQty(ctx, product, *req.Qty); err != nil {
+			return err
+		}
+		if priceChanged(before, product) {
+			if err := recordPriceChange(ctx, s.priceRepo, product); err != nil {
+				return err
+			}
 		}
+		return recordAudit(ctx, s.auditRepo, domain.AuditUpdate, domain.AuditEntityProduct, id, before, product)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return s.productRepo.Update(ctx, id, req)
+	return product, nil
+}
+
+// priceChanged reports whether a write changed the product's price, or
+// the currency it is in, so the new one belongs in the price history.
+func priceChanged(before, after *domain.Product) bool {
+	return before.Price.Cmp(after.Price) != 0 || before.Currency != after.Currency
+}
+
+// normalizeCurrency rejects unsupported currencies and rewrites code in the
+// upper case it is stored in.
+func normalizeCurrency(code *string) error {
+	currency, err := domain.LookupCurrency(*code)
+	if err != nil {
+		return err
+	}
+	*code = currency.Code
+	return nil
 }
 
-func (s *productService) DeleteProduct(ctx context.Context, id uuid.UUID) error {
-	product, err := s.productRepo.GetByID(ctx, id)
+// setQty records the difference between product's stock and qty as an
+// adjustment, so setting qty directly still leaves a trail in the ledger.
+func (s *productService) setQty(ctx context.Context, product *domain.Product, qty float64) error {
+	level, err := s.inventoryRepo.LockStock(ctx, product.ID)
+	if err != nil {
+		return err
+	}
+
+	movement, err := applyMovement(ctx, s.inventoryRepo, product.ID, domain.MovementAdjustment, qty-level.Qty, "product update", domain.ActorFromContext(ctx))
 	if err != nil {
 		return err
 	}
-	if product == nil {
-		return errors.New("product not found")
+	if movement != nil {
+		product.Qty = movement.QtyAfter
+		product.AvailableQty += movement.Quantity
+	}
+	return nil
+}
+
+func (s *productService) DeleteProduct(ctx context.Context, id uuid.UUID, version *int) error {
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		before, err := s.productRepo.LockByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := s.productRepo.Delete(ctx, id, version); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditRepo, domain.AuditDelete, domain.AuditEntityProduct, id, before, nil)
+	})
+}
+
+// RestoreProduct undoes a soft delete. A product whose brand is itself
+// deleted cannot be restored until the brand is.
+func (s *productService) RestoreProduct(ctx context.Context, id uuid.UUID) (*domain.Product, error) {
+	var product *domain.Product
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		before, err := s.productRepo.GetByIDIncludingDeleted(ctx, id)
+		if err != nil {
+			return err
+		}
+		if before.DeletedAt == nil {
+			return domain.ErrProductNotDeleted
+		}
+
+		if _, err := s.brandRepo.LockByID(ctx, before.BrandID); err != nil {
+			if errors.Is(err, domain.ErrBrandNotFound) {
+				return domain.ErrBrandDeleted
+			}
+			return err
+		}
+
+		product, err = s.productRepo.Restore(ctx, id)
+		if err != nil {
+			if errors.Is(err, domain.ErrProductNotFound) {
+				return domain.ErrProductNotDeleted
+			}
+			return err
+		}
+		return recordAudit(ctx, s.auditRepo, domain.AuditRestore, domain.AuditEntityProduct, id, before, product)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetProduct(ctx, product.ID, false)
+}
+
+func (s *productService) PurgeDeletedProducts(ctx context.Context, deletedBefore time.Time) (int, error) {
+	return s.productRepo.PurgeDeleted(ctx, deletedBefore)
+}
+
+func (s *productService) SetProductCategories(ctx context.Context, id uuid.UUID, req *domain.SetProductCategoriesRequest) (*domain.Product, error) {
+	seen := map[uuid.UUID]bool{}
+	categoryIDs := make([]uuid.UUID, 0, len(req.CategoryIDs))
+	for _, categoryID := range req.CategoryIDs {
+		if !seen[categoryID] {
+			seen[categoryID] = true
+			categoryIDs = append(categoryIDs, categoryID)
+		}
 	}
 
-	return s.productRepo.Delete(ctx, id)
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if _, err := s.productRepo.LockByID(ctx, id); err != nil {
+			return err
+		}
+
+		existing, err := s.categoryRepo.CountExisting(ctx, categoryIDs)
+		if err != nil {
+			return err
+		}
+		if existing != len(categoryIDs) {
+			return domain.ErrCategoryNotFound
+		}
+
+		before, err := s.categoryRepo.PathsForProducts(ctx, []uuid.UUID{id})
+		if err != nil {
+			return err
+		}
+		if err := s.categoryRepo.SetProductCategories(ctx, id, categoryIDs); err != nil {
+			return err
+		}
+		after, err := s.categoryRepo.PathsForProducts(ctx, []uuid.UUID{id})
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, s.auditRepo, domain.AuditUpdate, domain.AuditEntityProduct, id,
+			map[string]interface{}{"categories": before[id]},
+			map[string]interface{}{"categories": after[id]},
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetProduct(ctx, id, false)
 }
 
-func (s *productService) ListProducts(ctx context.Context, page, limit int) (*domain.ProductListResponse, error) {
+func (s *productService) ListProducts(ctx context.Context, filter *domain.ProductFilter, page, limit int) (*domain.ProductListResponse, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -88,15 +353,99 @@ func (s *productService) ListProducts(ctx context.Context, page, limit int) (*do
 	}
 
 	offset := (page - 1) * limit
-	products, total, err := s.productRepo.List(ctx, limit, offset)
+	products, total, err := s.productRepo.List(ctx, filter, limit, offset)
 	if err != nil {
 		return nil, err
 	}
 
+	if filter != nil && filter.IncludeVariants {
+		if err := attachVariants(ctx, s.variantRepo, products); err != nil {
+			return nil, err
+		}
+	}
+
 	totalPages := int(math.Ceil(float64(total) / float64(limit)))
 
 	return &domain.ProductListResponse{
 		Products:   products,
+		Total:      &total,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: totalPages,
+	}, nil
+}
+
+func (s *productService) ListProductsByCursor(ctx context.Context, filter *domain.ProductFilter, cursor *domain.ProductCursor, limit int, includeTotal bool) (*domain.ProductListResponse, error) {
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	// One extra row tells us whether another page exists in the paging direction.
+	products, err := s.productRepo.ListByCursor(ctx, filter, cursor, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	backward := cursor != nil && cursor.Backward
+	hasMore := len(products) > limit
+	if hasMore {
+		if backward {
+			products = products[1:]
+		} else {
+			products = products[:limit]
+		}
+	}
+
+	if filter != nil && filter.IncludeVariants {
+		if err := attachVariants(ctx, s.variantRepo, products); err != nil {
+			return nil, err
+		}
+	}
+
+	response := &domain.ProductListResponse{
+		Products: products,
+		Limit:    limit,
+	}
+
+	if len(products) > 0 {
+		first, last := products[0], products[len(products)-1]
+		if backward || hasMore {
+			response.NextCursor = domain.NewProductCursor(last, false).Encode()
+		}
+		if (backward && hasMore) || (!backward && cursor != nil) {
+			response.PrevCursor = domain.NewProductCursor(first, true).Encode()
+		}
+	}
+
+	if includeTotal {
+		total, err := s.productRepo.Count(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		response.Total = &total
+	}
+
+	return response, nil
+}
+
+func (s *productService) SearchProducts(ctx context.Context, query string, page, limit int) (*domain.ProductSearchResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	offset := (page - 1) * limit
+	results, total, err := s.productRepo.Search(ctx, query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+
+	return &domain.ProductSearchResponse{
+		Products:   results,
 		Total:      total,
 		Page:       page,
 		Limit:      limit,
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rezajo220/ecommerce/internal/domain"
+	"github.com/rezajo220/ecommerce/internal/repository"
+)
+
+type ReservationService interface {
+	Reserve(ctx context.Context, req *domain.CreateReservationRequest) (*domain.StockReservation, error)
+	GetReservation(ctx context.Context, id uuid.UUID) (*domain.StockReservation, error)
+	ConfirmReservation(ctx context.Context, id uuid.UUID) (*domain.StockReservation, error)
+	ReleaseReservation(ctx context.Context, id uuid.UUID) (*domain.StockReservation, error)
+	ExpireReservations(ctx context.Context) (int64, error)
+}
+
+type reservationService struct {
+	transactor      repository.Transactor
+	reservationRepo repository.ReservationRepository
+	inventoryRepo   repository.InventoryRepository
+}
+
+func NewReservationService(transactor repository.Transactor, reservationRepo repository.ReservationRepository, inventoryRepo repository.InventoryRepository) ReservationService {
+	return &reservationService{
+		transactor:      transactor,
+		reservationRepo: reservationRepo,
+		inventoryRepo:   inventoryRepo,
+	}
+}
+
+// Reserve holds stock for the request's holder. The product row is locked
+// while availability is checked, so two requests for the last unit cannot
+// both succeed.
+func (s *reservationService) Reserve(ctx context.Context, req *domain.CreateReservationRequest) (*domain.StockReservation, error) {
+	var reservation *domain.StockReservation
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		level, err := s.inventoryRepo.LockStock(ctx, req.ProductID)
+		if err != nil {
+			return err
+		}
+		if req.Quantity > level.Available() {
+			return domain.ErrInsufficientStock
+		}
+
+		reservation, err = s.reservationRepo.Create(ctx, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return reservation, nil
+}
+
+func (s *reservationService) GetReservation(ctx context.Context, id uuid.UUID) (*domain.StockReservation, error) {
+	return s.reservationRepo.GetByID(ctx, id)
+}
+
+// ConfirmReservation turns the held units into a sale movement.
+func (s *reservationService) ConfirmReservation(ctx context.Context, id uuid.UUID) (*domain.StockReservation, error) {
+	var reservation *domain.StockReservation
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		current, err := s.lockActive(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		// Confirm first so the units no longer count as reserved when the
+		// sale takes them out of stock.
+		reservation, err = s.reservationRepo.UpdateStatus(ctx, id, domain.ReservationConfirmed)
+		if err != nil {
+			return err
+		}
+
+		_, err = applyMovement(ctx, s.inventoryRepo, current.ProductID, domain.MovementSale, -current.Quantity, "reservation "+id.String()+" for "+current.Holder, domain.ActorFromContext(ctx))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return reservation, nil
+}
+
+func (s *reservationService) ReleaseReservation(ctx context.Context, id uuid.UUID) (*domain.StockReservation, error) {
+	var reservation *domain.StockReservation
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if _, err := s.lockActive(ctx, id); err != nil {
+			return err
+		}
+
+		var err error
+		reservation, err = s.reservationRepo.UpdateStatus(ctx, id, domain.ReservationReleased)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return reservation, nil
+}
+
+func (s *reservationService) ExpireReservations(ctx context.Context) (int64, error) {
+	return s.reservationRepo.ExpireStale(ctx)
+}
+
+// lockActive locks the reservation and rejects it unless it is still active
+// and unexpired.
+func (s *reservationService) lockActive(ctx context.Context, id uuid.UUID) (*domain.StockReservation, error) {
+	reservation, err := s.reservationRepo.LockByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if reservation.Status != domain.ReservationActive || !reservation.ExpiresAt.After(time.Now()) {
+		return nil, domain.ErrReservationNotActive
+	}
+	return reservation, nil
+}
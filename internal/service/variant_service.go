@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rezajo220/ecommerce/internal/domain"
+	"github.com/rezajo220/ecommerce/internal/repository"
+)
+
+type VariantService interface {
+	CreateVariant(ctx context.Context, productID uuid.UUID, req *domain.CreateVariantRequest) (*domain.ProductVariant, error)
+	ListVariants(ctx context.Context, productID uuid.UUID) ([]domain.ProductVariant, error)
+	UpdateVariant(ctx context.Context, productID, id uuid.UUID, req *domain.UpdateVariantRequest) (*domain.ProductVariant, error)
+	DeleteVariant(ctx context.Context, productID, id uuid.UUID) error
+	GetVariantBySKU(ctx context.Context, sku string) (*domain.ProductVariant, error)
+}
+
+type variantService struct {
+	variantRepo repository.VariantRepository
+	productRepo repository.ProductRepository
+}
+
+func NewVariantService(variantRepo repository.VariantRepository, productRepo repository.ProductRepository) VariantService {
+	return &variantService{
+		variantRepo: variantRepo,
+		productRepo: productRepo,
+	}
+}
+
+func (s *variantService) CreateVariant(ctx context.Context, productID uuid.UUID, req *domain.CreateVariantRequest) (*domain.ProductVariant, error) {
+	if _, err := s.productRepo.GetByID(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	return s.variantRepo.Create(ctx, productID, req)
+}
+
+func (s *variantService) ListVariants(ctx context.Context, productID uuid.UUID) ([]domain.ProductVariant, error) {
+	if _, err := s.productRepo.GetByID(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	return s.variantRepo.ListByProduct(ctx, productID)
+}
+
+func (s *variantService) UpdateVariant(ctx context.Context, productID, id uuid.UUID, req *domain.UpdateVariantRequest) (*domain.ProductVariant, error) {
+	variant, err := s.getProductVariant(ctx, productID, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.ChangesNothing(variant) {
+		return variant, nil
+	}
+
+	return s.variantRepo.Update(ctx, id, req)
+}
+
+func (s *variantService) DeleteVariant(ctx context.Context, productID, id uuid.UUID) error {
+	if _, err := s.getProductVariant(ctx, productID, id); err != nil {
+		return err
+	}
+
+	return s.variantRepo.Delete(ctx, id)
+}
+
+func (s *variantService) GetVariantBySKU(ctx context.Context, sku string) (*domain.ProductVariant, error) {
+	return s.variantRepo.GetBySKU(ctx, sku)
+}
+
+// getProductVariant loads a variant and treats one that belongs to another
+// product as not found.
+func (s *variantService) getProductVariant(ctx context.Context, productID, id uuid.UUID) (*domain.ProductVariant, error) {
+	variant, err := s.variantRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if variant.ProductID != productID {
+		return nil, domain.ErrVariantNotFound
+	}
+	return variant, nil
+}
+
+// attachVariants embeds each product's variants and its price range.
+func attachVariants(ctx context.Context, variantRepo repository.VariantRepository, products []domain.Product) error {
+	ids := make([]uuid.UUID, len(products))
+	for i := range products {
+		ids[i] = products[i].ID
+	}
+
+	variants, err := variantRepo.ListByProducts(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	for i := range products {
+		products[i].Variants = variants[products[i].ID]
+		products[i].PriceRange = domain.NewPriceRange(products[i].Price, products[i].Variants)
+	}
+	return nil
+}
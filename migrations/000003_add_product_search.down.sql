@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_products_search_vector;
+DROP TRIGGER IF EXISTS brands_search_vector_trigger ON brands;
+DROP TRIGGER IF EXISTS products_search_vector_trigger ON products;
+DROP FUNCTION IF EXISTS brands_search_vector_refresh();
+DROP FUNCTION IF EXISTS products_search_vector_update();
+DROP FUNCTION IF EXISTS products_search_vector(TEXT, TEXT);
+ALTER TABLE products DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,45 @@
+ALTER TABLE products ADD COLUMN IF NOT EXISTS search_vector tsvector;
+
+-- The vector covers the product name (weight A) and the brand name (weight B).
+-- The 'simple' configuration keeps tokens unstemmed so prefix matching works
+-- for any language in the catalog.
+CREATE OR REPLACE FUNCTION products_search_vector(product_name TEXT, brand_name TEXT) RETURNS tsvector AS $$
+    SELECT setweight(to_tsvector('simple', coalesce(product_name, '')), 'A') ||
+           setweight(to_tsvector('simple', coalesce(brand_name, '')), 'B');
+$$ LANGUAGE sql IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION products_search_vector_update() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector := products_search_vector(
+        NEW.product_name,
+        (SELECT brand_name FROM brands WHERE id = NEW.brand_id)
+    );
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER products_search_vector_trigger
+    BEFORE INSERT OR UPDATE OF product_name, brand_id ON products
+    FOR EACH ROW EXECUTE FUNCTION products_search_vector_update();
+
+CREATE OR REPLACE FUNCTION brands_search_vector_refresh() RETURNS trigger AS $$
+BEGIN
+    UPDATE products
+    SET search_vector = products_search_vector(product_name, NEW.brand_name)
+    WHERE brand_id = NEW.id;
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER brands_search_vector_trigger
+    AFTER UPDATE OF brand_name ON brands
+    FOR EACH ROW
+    WHEN (OLD.brand_name IS DISTINCT FROM NEW.brand_name)
+    EXECUTE FUNCTION brands_search_vector_refresh();
+
+UPDATE products p
+SET search_vector = products_search_vector(p.product_name, b.brand_name)
+FROM brands b
+WHERE b.id = p.brand_id;
+
+CREATE INDEX IF NOT EXISTS idx_products_search_vector ON products USING GIN (search_vector);
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS product_variants (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    sku TEXT NOT NULL,
+    options JSONB NOT NULL DEFAULT '{}',
+    price NUMERIC NOT NULL,
+    qty NUMERIC NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- SKUs are unique regardless of case so scanners and humans agree on them.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_product_variants_sku ON product_variants (lower(sku));
+CREATE INDEX IF NOT EXISTS idx_product_variants_product_id ON product_variants (product_id);
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS inventory_movements (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    movement_type TEXT NOT NULL CHECK (movement_type IN ('receipt', 'sale', 'adjustment', 'return')),
+    quantity NUMERIC NOT NULL CHECK (quantity <> 0),
+    qty_after NUMERIC NOT NULL CHECK (qty_after >= 0),
+    reason TEXT NOT NULL DEFAULT '',
+    actor TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_inventory_movements_product_id ON inventory_movements (product_id, created_at DESC);
+
+-- Opening balances, so that from now on products.qty always equals the sum
+-- of the product's movements.
+INSERT INTO inventory_movements (product_id, movement_type, quantity, qty_after, reason, actor)
+SELECT id, 'adjustment', qty, qty, 'opening balance', 'migration'
+FROM products
+WHERE qty <> 0;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS stock_reservations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    holder TEXT NOT NULL,
+    quantity NUMERIC NOT NULL CHECK (quantity > 0),
+    status TEXT NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'confirmed', 'released', 'expired')),
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_reservations_active_product ON stock_reservations (product_id) WHERE status = 'active';
+CREATE INDEX IF NOT EXISTS idx_stock_reservations_active_expires_at ON stock_reservations (expires_at) WHERE status = 'active';
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS order_items;
+DROP TABLE IF EXISTS orders;
+ALTER TABLE carts DROP COLUMN IF EXISTS checked_out_at;
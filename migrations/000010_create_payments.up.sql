@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS payments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    provider TEXT NOT NULL,
+    provider_ref TEXT NOT NULL,
+    amount NUMERIC NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('authorized', 'captured', 'failed', 'refunded')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (provider, provider_ref)
+);
+
+CREATE INDEX IF NOT EXISTS idx_payments_order_id ON payments (order_id);
+
+-- Webhook events already processed, keyed by the provider's event id.
+CREATE TABLE IF NOT EXISTS payment_events (
+    event_id TEXT PRIMARY KEY,
+    payment_id UUID REFERENCES payments(id) ON DELETE CASCADE,
+    event_type TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    received_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,59 @@
+CREATE TABLE IF NOT EXISTS roles (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name TEXT NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_id UUID NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    permission TEXT NOT NULL,
+    PRIMARY KEY (role_id, permission)
+);
+
+CREATE TABLE IF NOT EXISTS user_roles (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role_id UUID NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    PRIMARY KEY (user_id, role_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_roles_role_id ON user_roles (role_id);
+
+INSERT INTO roles (name, description) VALUES
+    ('admin', 'Full access, including managing roles'),
+    ('catalog_manager', 'Manages products, brands and categories'),
+    ('inventory_clerk', 'Adjusts stock and follows orders'),
+    ('viewer', 'Read-only access to back-office data')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT r.id, p.permission
+FROM roles r
+JOIN (VALUES
+    ('admin', 'products:write'),
+    ('admin', 'products:delete'),
+    ('admin', 'brands:write'),
+    ('admin', 'brands:delete'),
+    ('admin', 'categories:write'),
+    ('admin', 'categories:delete'),
+    ('admin', 'stock:read'),
+    ('admin', 'stock:adjust'),
+    ('admin', 'orders:read'),
+    ('admin', 'orders:write'),
+    ('admin', 'payments:refund'),
+    ('admin', 'roles:manage'),
+    ('catalog_manager', 'products:write'),
+    ('catalog_manager', 'products:delete'),
+    ('catalog_manager', 'brands:write'),
+    ('catalog_manager', 'brands:delete'),
+    ('catalog_manager', 'categories:write'),
+    ('catalog_manager', 'categories:delete'),
+    ('catalog_manager', 'stock:read'),
+    ('inventory_clerk', 'stock:read'),
+    ('inventory_clerk', 'stock:adjust'),
+    ('inventory_clerk', 'orders:read'),
+    ('viewer', 'stock:read'),
+    ('viewer', 'orders:read')
+) AS p (role_name, permission) ON p.role_name = r.name
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,3 @@
+DELETE FROM role_permissions WHERE permission = 'api_keys:manage';
+
+DROP TABLE IF EXISTS api_keys;
@@ -0,0 +1,21 @@
+-- API keys look like "<prefix>_<secret>". The prefix is stored in clear to
+-- find the key; only the SHA-256 hash of the secret is kept. Scopes are
+-- permission names; allowed_ips holds CIDR blocks, empty meaning any address.
+CREATE TABLE IF NOT EXISTS api_keys (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name TEXT NOT NULL,
+    prefix TEXT NOT NULL UNIQUE,
+    secret_hash TEXT NOT NULL,
+    scopes TEXT[] NOT NULL DEFAULT '{}',
+    allowed_ips TEXT[] NOT NULL DEFAULT '{}',
+    expires_at TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    created_by TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT id, 'api_keys:manage' FROM roles WHERE name = 'admin'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,19 @@
+-- audit_log records catalog changes. changes maps each changed field to its
+-- value before and after; request_id ties an entry to the request logs.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    actor TEXT NOT NULL,
+    action TEXT NOT NULL CHECK (action IN ('create', 'update', 'delete')),
+    entity_type TEXT NOT NULL,
+    entity_id UUID NOT NULL,
+    changes JSONB NOT NULL DEFAULT '{}',
+    request_id TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON audit_log (entity_type, entity_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log (created_at DESC);
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT id, 'audit:read' FROM roles WHERE name = 'admin'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,13 @@
+DELETE FROM audit_log WHERE action = 'restore';
+ALTER TABLE audit_log DROP CONSTRAINT IF EXISTS audit_log_action_check;
+ALTER TABLE audit_log ADD CONSTRAINT audit_log_action_check
+    CHECK (action IN ('create', 'update', 'delete'));
+
+DELETE FROM products WHERE deleted_at IS NOT NULL;
+DELETE FROM brands WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_brands_deleted_at;
+DROP INDEX IF EXISTS idx_products_deleted_at;
+
+ALTER TABLE brands DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE products DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,11 @@
+-- Products and brands are soft deleted: deleted_at is set instead of removing
+-- the row, and the purge command removes rows deleted long enough ago.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE brands ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_products_deleted_at ON products (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_brands_deleted_at ON brands (deleted_at) WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE audit_log DROP CONSTRAINT IF EXISTS audit_log_action_check;
+ALTER TABLE audit_log ADD CONSTRAINT audit_log_action_check
+    CHECK (action IN ('create', 'update', 'delete', 'restore'));
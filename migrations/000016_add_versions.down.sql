@@ -0,0 +1,2 @@
+ALTER TABLE brands DROP COLUMN IF EXISTS version;
+ALTER TABLE products DROP COLUMN IF EXISTS version;
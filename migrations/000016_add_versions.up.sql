@@ -0,0 +1,5 @@
+-- version is bumped by every write through the product and brand endpoints
+-- and exposed as the ETag; writes sent with If-Match only apply while the
+-- stored version still matches.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE brands ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,6 @@
+DELETE FROM role_permissions WHERE permission = 'exchange_rates:manage';
+
+DROP TABLE IF EXISTS product_currency_prices;
+DROP TABLE IF EXISTS exchange_rates;
+
+ALTER TABLE products DROP COLUMN IF EXISTS currency;
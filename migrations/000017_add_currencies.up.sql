@@ -0,0 +1,28 @@
+-- Products are priced in a base currency. Prices in other currencies come
+-- from a per-product override or are converted through exchange_rates.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'IDR';
+
+-- One unit of base_currency is worth rate units of quote_currency. Each
+-- direction is stored on its own so both can be set to the rates used in
+-- practice.
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    base_currency CHAR(3) NOT NULL,
+    quote_currency CHAR(3) NOT NULL,
+    rate NUMERIC NOT NULL CHECK (rate > 0),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (base_currency, quote_currency),
+    CHECK (base_currency <> quote_currency)
+);
+
+CREATE TABLE IF NOT EXISTS product_currency_prices (
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    currency CHAR(3) NOT NULL,
+    price NUMERIC NOT NULL CHECK (price >= 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (product_id, currency)
+);
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT id, 'exchange_rates:manage' FROM roles WHERE name = 'admin'
+ON CONFLICT DO NOTHING;
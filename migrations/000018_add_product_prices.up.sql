@@ -0,0 +1,26 @@
+-- product_prices is the timeline of each product's price. A row is in effect
+-- from effective_from until effective_to, or indefinitely while effective_to
+-- is NULL; rows of a product never overlap. Rows starting in the future are
+-- scheduled prices, copied onto products.price by a background job once they
+-- are due, which sets applied_at.
+CREATE TABLE IF NOT EXISTS product_prices (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    price NUMERIC NOT NULL CHECK (price >= 0),
+    currency CHAR(3) NOT NULL,
+    effective_from TIMESTAMPTZ NOT NULL,
+    effective_to TIMESTAMPTZ,
+    applied_at TIMESTAMPTZ,
+    actor TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (product_id, effective_from),
+    CHECK (effective_to IS NULL OR effective_to > effective_from)
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_prices_due ON product_prices (effective_from) WHERE applied_at IS NULL;
+
+-- Existing products start their history with the price they have now.
+INSERT INTO product_prices (product_id, price, currency, effective_from, applied_at, actor)
+SELECT id, price, currency, created_at, created_at, 'system'
+FROM products
+ON CONFLICT DO NOTHING;
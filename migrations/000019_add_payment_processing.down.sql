@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_payments_open_order_id;
+
+UPDATE payments SET status = 'failed', provider_ref = 'processing_' || id WHERE status = 'processing';
+
+ALTER TABLE payments DROP CONSTRAINT IF EXISTS payments_status_check;
+ALTER TABLE payments ADD CONSTRAINT payments_status_check
+    CHECK (status IN ('authorized', 'captured', 'failed', 'refunded'));
+
+UPDATE payments SET provider_ref = 'failed_' || id WHERE provider_ref IS NULL;
+ALTER TABLE payments ALTER COLUMN provider_ref SET NOT NULL;
@@ -0,0 +1,12 @@
+-- A payment is stored as processing before the gateway is called, so two
+-- concurrent attempts to pay the same order cannot both charge it. The
+-- provider reference is only known once the gateway has answered.
+ALTER TABLE payments ALTER COLUMN provider_ref DROP NOT NULL;
+
+ALTER TABLE payments DROP CONSTRAINT IF EXISTS payments_status_check;
+ALTER TABLE payments ADD CONSTRAINT payments_status_check
+    CHECK (status IN ('processing', 'authorized', 'captured', 'failed', 'refunded'));
+
+-- At most one payment per order is in flight or has taken the money.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payments_open_order_id ON payments (order_id)
+    WHERE status IN ('processing', 'authorized', 'captured');
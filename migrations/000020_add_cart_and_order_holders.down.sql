@@ -0,0 +1,6 @@
+DELETE FROM role_permissions WHERE permission = 'reservations:write';
+
+DROP INDEX IF EXISTS idx_orders_holder_id;
+
+ALTER TABLE orders DROP COLUMN IF EXISTS holder_id;
+ALTER TABLE carts DROP COLUMN IF EXISTS holder_id;
@@ -0,0 +1,11 @@
+-- Carts and orders belong to the caller that created them: a user, or an API
+-- key acting on its own. Rows from before have no holder and can no longer
+-- be changed through the API.
+ALTER TABLE carts ADD COLUMN IF NOT EXISTS holder_id UUID;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS holder_id UUID;
+
+CREATE INDEX IF NOT EXISTS idx_orders_holder_id ON orders (holder_id);
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT id, 'reservations:write' FROM roles WHERE name IN ('admin', 'inventory_clerk')
+ON CONFLICT DO NOTHING;
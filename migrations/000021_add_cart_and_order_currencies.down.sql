@@ -0,0 +1,3 @@
+ALTER TABLE orders DROP COLUMN IF EXISTS currency;
+ALTER TABLE order_items DROP COLUMN IF EXISTS currency;
+ALTER TABLE cart_items DROP COLUMN IF EXISTS currency;
@@ -0,0 +1,18 @@
+-- Cart lines, orders and order items record the currency their prices are
+-- in. A cart or order only ever holds one currency; existing rows take the
+-- currency of their product, or the default when the product is gone.
+ALTER TABLE cart_items ADD COLUMN IF NOT EXISTS currency CHAR(3);
+UPDATE cart_items ci SET currency = p.currency FROM products p WHERE p.id = ci.product_id AND ci.currency IS NULL;
+ALTER TABLE cart_items ALTER COLUMN currency SET NOT NULL;
+
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS currency CHAR(3);
+UPDATE order_items oi SET currency = p.currency FROM products p WHERE p.id = oi.product_id AND oi.currency IS NULL;
+UPDATE order_items SET currency = 'IDR' WHERE currency IS NULL;
+ALTER TABLE order_items ALTER COLUMN currency SET NOT NULL;
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS currency CHAR(3);
+UPDATE orders o SET currency = (
+    SELECT MIN(oi.currency) FROM order_items oi WHERE oi.order_id = o.id
+) WHERE o.currency IS NULL;
+UPDATE orders SET currency = 'IDR' WHERE currency IS NULL;
+ALTER TABLE orders ALTER COLUMN currency SET NOT NULL;
@@ -0,0 +1,4 @@
+DELETE FROM product_prices pp WHERE NOT EXISTS (SELECT 1 FROM products p WHERE p.id = pp.product_id);
+
+ALTER TABLE product_prices ADD CONSTRAINT product_prices_product_id_fkey
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE;
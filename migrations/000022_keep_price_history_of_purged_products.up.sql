@@ -0,0 +1,6 @@
+-- Price history outlives the products it belongs to, so invoices for a
+-- purged product can still be reconciled: product_id no longer references
+-- products and purging a product leaves its rows in place. Unapplied rows of
+-- a purged product are never due, since the scheduler only applies prices of
+-- existing products.
+ALTER TABLE product_prices DROP CONSTRAINT IF EXISTS product_prices_product_id_fkey;
@@ -0,0 +1,9 @@
+// Package migrations holds the versioned SQL schema migrations. Files are
+// named <version>_<name>.up.sql / <version>_<name>.down.sql and are embedded
+// into the binary so every environment applies exactly the same schema.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS